@@ -21,15 +21,21 @@ import (
 	"github.com/simple-container-com/api/pkg/cmd/cmd_cicd"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_deploy"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_destroy"
+	"github.com/simple-container-com/api/pkg/cmd/cmd_drift"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_image"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_init"
+	"github.com/simple-container-com/api/pkg/cmd/cmd_promote"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_provenance"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_provision"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_release"
+	"github.com/simple-container-com/api/pkg/cmd/cmd_rollback"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_sbom"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_secrets"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_stack"
+	"github.com/simple-container-com/api/pkg/cmd/cmd_state"
+	"github.com/simple-container-com/api/pkg/cmd/cmd_up"
 	"github.com/simple-container-com/api/pkg/cmd/cmd_upgrade"
+	"github.com/simple-container-com/api/pkg/cmd/cmd_validate"
 	"github.com/simple-container-com/api/pkg/cmd/root_cmd"
 )
 
@@ -85,10 +91,17 @@ func main() {
 		cmd_init.NewInitCmd(rootCmdInstance),
 		cmd_provision.NewProvisionCmd(rootCmdInstance),
 		cmd_deploy.NewDeployCmd(rootCmdInstance),
+		cmd_promote.NewPromoteCmd(rootCmdInstance),
+		cmd_rollback.NewRollbackCmd(rootCmdInstance),
+		cmd_drift.NewDriftCmd(rootCmdInstance),
 		cmd_cancel.NewCancelCmd(rootCmdInstance),
 		cmd_destroy.NewDestroyCmd(rootCmdInstance),
+		cmd_up.NewUpCmd(rootCmdInstance),
+		cmd_up.NewDownCmd(rootCmdInstance),
+		cmd_validate.NewValidateCmd(rootCmdInstance),
 		cmd_upgrade.NewUpgradeCmd(rootCmdInstance),
 		cmd_stack.NewStackCmd(rootCmdInstance),
+		cmd_state.NewStateCmd(rootCmdInstance),
 		cmd_cicd.NewCicdCmd(rootCmdInstance),
 		cmd_image.NewImageCmd(),
 		cmd_provenance.NewProvenanceCommand(),
@@ -33,6 +33,7 @@ import (
 	_ "github.com/simple-container-com/api/pkg/clouds/mongodb"
 	_ "github.com/simple-container-com/api/pkg/clouds/slack"
 	_ "github.com/simple-container-com/api/pkg/clouds/telegram"
+	_ "github.com/simple-container-com/api/pkg/clouds/vault"
 )
 
 // ResourceDefinition holds metadata about a resource struct
@@ -506,6 +507,8 @@ func (sg *SchemaGenerator) guessProviderFromResourceType(resourceType string) st
 		return "slack"
 	case has("telegram"):
 		return "telegram"
+	case has("vault"):
+		return "vault"
 	default:
 		return "unknown"
 	}
@@ -518,7 +521,7 @@ func (sg *SchemaGenerator) guessResourceType(resourceType string) string {
 		return "template"
 	case strings.Contains(resourceType, "auth") || strings.Contains(resourceType, "token") || strings.Contains(resourceType, "kubeconfig"):
 		return "auth"
-	case strings.Contains(resourceType, "secrets"):
+	case strings.Contains(resourceType, "secrets") || resourceType == "vault":
 		return "secrets"
 	default:
 		return "resource"
@@ -539,6 +542,8 @@ func (sg *SchemaGenerator) generateDescription(resourceType, structName string)
 		providerName = "MongoDB Atlas"
 	} else if provider == "cloudflare" {
 		providerName = "Cloudflare"
+	} else if provider == "vault" {
+		providerName = "HashiCorp Vault"
 	}
 
 	switch resourceKind {
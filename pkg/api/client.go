@@ -6,6 +6,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -278,12 +279,19 @@ type StackParams struct {
 	Version      string   `json:"version" yaml:"version"`
 	Timeouts     Timeouts `json:",inline" yaml:",inline"`
 	Parent       bool     `json:"parent" yaml:"parent"`
+	PromotedFrom string   `json:"promotedFrom,omitempty" yaml:"promotedFrom,omitempty"` // environment deployed images are promoted from (if any)
+	// PinnedImages maps image name to its immutable reference (name@sha256:...) to deploy instead of building the image
+	PinnedImages map[string]string `json:"pinnedImages,omitempty" yaml:"pinnedImages,omitempty"`
+	// OverridePolicies are names of policies which violations are ignored
+	OverridePolicies []string `json:"overridePolicies,omitempty" yaml:"overridePolicies,omitempty"`
 }
 
 type Timeouts struct {
 	ExecutionTimeout string `json:"executionTimeout" yaml:"executionTimeout"`
 	PreviewTimeout   string `json:"previewTimeout" yaml:"previewTimeout"`
 	DeployTimeout    string `json:"deployTimeout" yaml:"deployTimeout"`
+	// LockTimeout is how long to wait for the stack lock held by another operation (default: fail immediately)
+	LockTimeout string `json:"lockTimeout,omitempty" yaml:"lockTimeout,omitempty"`
 }
 
 type DeployParams struct {
@@ -291,6 +299,36 @@ type DeployParams struct {
 	Vars        VariableValues `json:"vars" yaml:"vars"`
 }
 
+// PromoteParams describes deployment of images and version deployed to FromEnvironment into Environment
+type PromoteParams struct {
+	DeployParams    `json:",inline" yaml:",inline"`
+	FromEnvironment string `json:"fromEnvironment" yaml:"fromEnvironment"`
+}
+
+// DeployAllParams describes deployment of every client stack configured for the environment
+type DeployAllParams struct {
+	DeployParams `json:",inline" yaml:",inline"`
+	Concurrency  int `json:"concurrency" yaml:"concurrency"` // max number of stacks deployed at the same time (default: 1)
+}
+
+type StackDeployStatus string
+
+const (
+	StackDeployStatusSucceeded StackDeployStatus = "succeeded"
+	StackDeployStatusFailed    StackDeployStatus = "failed"
+	StackDeployStatusSkipped   StackDeployStatus = "skipped"
+)
+
+// StackDeployResult is the outcome of deploying a single stack as a part of multi-stack deploy
+type StackDeployResult struct {
+	StackName string            `json:"stackName" yaml:"stackName"`
+	Status    StackDeployStatus `json:"status" yaml:"status"`
+	Duration  time.Duration     `json:"duration" yaml:"duration"`
+	Update    *UpdateResult     `json:"update,omitempty" yaml:"update,omitempty"`
+	Error     string            `json:"error,omitempty" yaml:"error,omitempty"`
+	BlockedBy []string          `json:"blockedBy,omitempty" yaml:"blockedBy,omitempty"` // upstream stacks that prevented deploy
+}
+
 type UpdateResult struct {
 	StackName  string         `json:"stackName" yaml:"stackName"`
 	Summary    string         `json:"summary" yaml:"summary"`
@@ -301,11 +339,14 @@ type PreviewResult struct {
 	StackName  string         `json:"stackName" yaml:"stackName"`
 	Summary    string         `json:"summary" yaml:"summary"`
 	Operations map[string]int `json:"operations" yaml:"operations"`
+	Steps      []PreviewStep  `json:"steps,omitempty" yaml:"steps,omitempty"`
 }
 
 type OutputsResult struct {
 	StackName string         `json:"stackName" yaml:"stackName"`
 	Outputs   map[string]any `json:"outputs" yaml:"outputs"`
+	// Secrets are names of outputs holding secret values
+	Secrets []string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
 }
 
 func (r *UpdateResult) String() string {
@@ -342,27 +383,29 @@ type DestroyParams struct {
 
 func (p *StackParams) ToProvisionParams() ProvisionParams {
 	return ProvisionParams{
-		StacksDir:   p.StacksDir,
-		Profile:     p.Profile,
-		Stacks:      []string{p.StackName},
-		SkipRefresh: p.SkipRefresh,
-		Timeouts:    p.Timeouts,
+		StacksDir:        p.StacksDir,
+		Profile:          p.Profile,
+		Stacks:           []string{p.StackName},
+		SkipRefresh:      p.SkipRefresh,
+		Timeouts:         p.Timeouts,
+		OverridePolicies: p.OverridePolicies,
 	}
 }
 
 func (p *StackParams) CopyForParentEnv(env string) *StackParams {
 	return &StackParams{
-		StacksDir:   p.StackDir,
-		StackDir:    p.StackDir,
-		Profile:     p.Profile,
-		StackName:   p.StackName,
-		Environment: p.Environment,
-		SkipRefresh: p.SkipRefresh,
-		SkipPreview: p.SkipPreview,
-		Version:     p.Version,
-		Timeouts:    p.Timeouts,
-		Parent:      p.Parent,
-		ParentEnv:   env,
+		StacksDir:    p.StackDir,
+		StackDir:     p.StackDir,
+		Profile:      p.Profile,
+		StackName:    p.StackName,
+		Environment:  p.Environment,
+		SkipRefresh:  p.SkipRefresh,
+		SkipPreview:  p.SkipPreview,
+		Version:      p.Version,
+		Timeouts:     p.Timeouts,
+		Parent:       p.Parent,
+		ParentEnv:    env,
+		PromotedFrom: p.PromotedFrom,
 	}
 }
 
@@ -29,6 +29,10 @@ type ConfigFile struct {
 	PublicKey          string `yaml:"publicKey,omitempty" json:"publicKey,omitempty"`
 	StacksDir          string `yaml:"stacksDir,omitempty" json:"stacksDir,omitempty"`
 	ParentRepository   string `yaml:"parentRepository,omitempty" json:"parentRepository,omitempty"`
+	// AllowCmdPlaceholders enables ${cmd:...} placeholders in stack configs
+	AllowCmdPlaceholders bool `yaml:"allowCmdPlaceholders,omitempty" json:"allowCmdPlaceholders,omitempty"`
+	// PrivateKeyAgent is SHA256 fingerprint of ssh-agent key the key pair is derived from instead of storing private key
+	PrivateKeyAgent string `yaml:"privateKeyAgent,omitempty" json:"privateKeyAgent,omitempty"`
 }
 
 type InitParams struct {
@@ -5,6 +5,7 @@ package api
 
 import (
 	"reflect"
+	"slices"
 
 	"gopkg.in/yaml.v3"
 
@@ -38,7 +39,11 @@ func (sd *ServerDescriptor) Copy() ServerDescriptor {
 		SchemaVersion: sd.SchemaVersion,
 		Provisioner:   sd.Provisioner.Copy(),
 		Secrets:       sd.Secrets.Copy(),
-		CiCd:          sd.CiCd.Copy(),
+		SecretSources: lo.MapValues(sd.SecretSources, func(value SecretSourceDescriptor, key string) SecretSourceDescriptor {
+			value.Config = value.Config.Copy()
+			return value
+		}),
+		CiCd: sd.CiCd.Copy(),
 		Templates: lo.MapValues(sd.Templates, func(value StackDescriptor, key string) StackDescriptor {
 			return value.Copy()
 		}),
@@ -46,6 +51,11 @@ func (sd *ServerDescriptor) Copy() ServerDescriptor {
 		Variables: lo.MapValues(sd.Variables, func(value VariableDescriptor, key string) VariableDescriptor {
 			return value.Copy()
 		}),
+		Policies: lo.Map(sd.Policies, func(value PolicyDescriptor, _ int) PolicyDescriptor {
+			value.Environments = slices.Clone(value.Environments)
+			value.ResourceTypes = slices.Clone(value.ResourceTypes)
+			return value
+		}),
 	}
 }
 
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+type DriftStatus string
+
+const (
+	DriftStatusInSync  DriftStatus = "in-sync"
+	DriftStatusDrifted DriftStatus = "drifted"
+	DriftStatusDeleted DriftStatus = "deleted" // resource was deleted out of band
+)
+
+// DriftedProperty is a property of the resource which actual value differs from the one in the stack state
+type DriftedProperty struct {
+	Path string `json:"path" yaml:"path"`
+	Kind string `json:"kind" yaml:"kind"` // add, delete, update, etc.
+}
+
+type DriftResource struct {
+	URN        string            `json:"urn" yaml:"urn"`
+	Type       string            `json:"type" yaml:"type"`
+	Status     DriftStatus       `json:"status" yaml:"status"`
+	Properties []DriftedProperty `json:"properties,omitempty" yaml:"properties,omitempty"`
+}
+
+// DriftReport is the result of comparing stack state with actual state of the cloud resources
+type DriftReport struct {
+	StackName   string          `json:"stackName" yaml:"stackName"`
+	Environment string          `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Resources   []DriftResource `json:"resources" yaml:"resources"`
+}
+
+// Count returns number of resources with the given status
+func (r *DriftReport) Count(status DriftStatus) int {
+	return lo.CountBy(r.Resources, func(res DriftResource) bool {
+		return res.Status == status
+	})
+}
+
+// HasDrift returns true when any resource is drifted or deleted out of band
+func (r *DriftReport) HasDrift() bool {
+	return r.Count(DriftStatusDrifted)+r.Count(DriftStatusDeleted) > 0
+}
+
+// Sort orders resources so that drifted and deleted ones go first
+func (r *DriftReport) Sort() {
+	order := map[DriftStatus]int{DriftStatusDeleted: 0, DriftStatusDrifted: 1, DriftStatusInSync: 2}
+	sort.SliceStable(r.Resources, func(i, j int) bool {
+		if order[r.Resources[i].Status] != order[r.Resources[j].Status] {
+			return order[r.Resources[i].Status] < order[r.Resources[j].Status]
+		}
+		return r.Resources[i].URN < r.Resources[j].URN
+	})
+}
+
+func (r *DriftReport) summary() string {
+	return fmt.Sprintf("%d drifted, %d deleted, %d in sync",
+		r.Count(DriftStatusDrifted), r.Count(DriftStatusDeleted), r.Count(DriftStatusInSync))
+}
+
+func (r *DriftReport) stackRef() string {
+	return lo.If(r.Environment != "", fmt.Sprintf("%s (%s)", r.StackName, r.Environment)).Else(r.StackName)
+}
+
+// Markdown renders report as a Markdown document (e.g. for PR comments or issues)
+func (r *DriftReport) Markdown() string {
+	res := strings.Builder{}
+	res.WriteString(fmt.Sprintf("## Drift report for `%s`\n\n", r.stackRef()))
+	res.WriteString(fmt.Sprintf("**%s**\n\n", r.summary()))
+	if !r.HasDrift() {
+		res.WriteString("No drift detected.\n")
+		return res.String()
+	}
+	res.WriteString("| Status | Type | Resource | Properties |\n")
+	res.WriteString("|--------|------|----------|------------|\n")
+	for _, resource := range r.Resources {
+		if resource.Status == DriftStatusInSync {
+			continue
+		}
+		props := lo.Map(resource.Properties, func(p DriftedProperty, _ int) string {
+			return fmt.Sprintf("`%s` (%s)", p.Path, p.Kind)
+		})
+		res.WriteString(fmt.Sprintf("| %s | `%s` | `%s` | %s |\n", resource.Status, resource.Type, resource.URN, strings.Join(props, "<br>")))
+	}
+	return res.String()
+}
+
+// ToAlert converts report to an alert which can be sent with any of AlertSender implementations
+func (r *DriftReport) ToAlert() Alert {
+	alert := Alert{
+		Name:      fmt.Sprintf("%s-drift", r.StackName),
+		Title:     fmt.Sprintf("Drift detected in %s", r.stackRef()),
+		Reason:    r.summary(),
+		StackName: r.StackName,
+		StackEnv:  r.Environment,
+		AlertType: AlertTriggered,
+		Description: strings.Join(lo.FilterMap(r.Resources, func(res DriftResource, _ int) (string, bool) {
+			return fmt.Sprintf("%s: %s", res.Status, res.URN), res.Status != DriftStatusInSync
+		}), "\n"),
+	}
+	if !r.HasDrift() {
+		alert.Title = fmt.Sprintf("No drift in %s", r.stackRef())
+		alert.AlertType = AlertResolved
+	}
+	return alert
+}
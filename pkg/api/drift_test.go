@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDriftReport(t *testing.T) {
+	RegisterTestingT(t)
+
+	report := &DriftReport{
+		StackName:   "billing",
+		Environment: "production",
+		Resources: []DriftResource{
+			{URN: "urn:c", Type: "aws:s3/bucket:Bucket", Status: DriftStatusInSync},
+			{URN: "urn:b", Type: "aws:ecs/service:Service", Status: DriftStatusDrifted, Properties: []DriftedProperty{
+				{Path: "desiredCount", Kind: "update"},
+				{Path: "tags.owner", Kind: "add"},
+			}},
+			{URN: "urn:a", Type: "aws:sqs/queue:Queue", Status: DriftStatusDeleted},
+		},
+	}
+	report.Sort()
+	Expect(report.HasDrift()).To(BeTrue())
+	Expect(report.Resources[0].URN).To(Equal("urn:a"))
+	Expect(report.Resources[1].URN).To(Equal("urn:b"))
+	Expect(report.Resources[2].URN).To(Equal("urn:c"))
+
+	md := report.Markdown()
+	Expect(md).To(ContainSubstring("## Drift report for `billing (production)`"))
+	Expect(md).To(ContainSubstring("1 drifted, 1 deleted, 1 in sync"))
+	Expect(md).To(ContainSubstring("| drifted | `aws:ecs/service:Service` | `urn:b` | `desiredCount` (update)<br>`tags.owner` (add) |"))
+	Expect(md).NotTo(ContainSubstring("urn:c"))
+
+	alert := report.ToAlert()
+	Expect(alert.AlertType).To(Equal(AlertTriggered))
+	Expect(alert.StackName).To(Equal("billing"))
+	Expect(alert.StackEnv).To(Equal("production"))
+	Expect(alert.Description).To(Equal("deleted: urn:a\ndrifted: urn:b"))
+
+	report.Resources = report.Resources[2:]
+	Expect(report.HasDrift()).To(BeFalse())
+	Expect(report.Markdown()).To(ContainSubstring("No drift detected."))
+	Expect(report.ToAlert().AlertType).To(Equal(AlertResolved))
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+type OperationEventType string
+
+const (
+	OperationEventStart   OperationEventType = "start"
+	OperationEventStep    OperationEventType = "step"
+	OperationEventSummary OperationEventType = "summary"
+)
+
+const (
+	OperationStatusSucceeded = "succeeded"
+	OperationStatusFailed    = "failed"
+)
+
+// OperationEvent is a machine-readable record of provisioner operation progress
+type OperationEvent struct {
+	Type      OperationEventType `json:"type" yaml:"type"`
+	Time      time.Time          `json:"time" yaml:"time"`
+	Operation string             `json:"operation" yaml:"operation"` // deploy, provision, destroy, cancel, refresh, preview
+	Stack     string             `json:"stack,omitempty" yaml:"stack,omitempty"`
+
+	// resource step fields
+	URN          string `json:"urn,omitempty" yaml:"urn,omitempty"`
+	ResourceType string `json:"resourceType,omitempty" yaml:"resourceType,omitempty"`
+	Op           string `json:"op,omitempty" yaml:"op,omitempty"`
+	DurationMs   int64  `json:"durationMs,omitempty" yaml:"durationMs,omitempty"`
+	Diagnostic   string `json:"diagnostic,omitempty" yaml:"diagnostic,omitempty"`
+
+	Status  string         `json:"status,omitempty" yaml:"status,omitempty"`
+	Error   string         `json:"error,omitempty" yaml:"error,omitempty"`
+	Update  *UpdateResult  `json:"update,omitempty" yaml:"update,omitempty"`
+	Preview *PreviewResult `json:"preview,omitempty" yaml:"preview,omitempty"`
+	Destroy *DestroyResult `json:"destroy,omitempty" yaml:"destroy,omitempty"`
+}
+
+type OperationEventSink interface {
+	Emit(OperationEvent)
+}
+
+type operationEventsKey struct{}
+
+var operationEvents operationEventsKey = struct{}{}
+
+// WithOperationEvents returns context which makes provisioners emit operation events into the sink
+func WithOperationEvents(ctx context.Context, sink OperationEventSink) context.Context {
+	return context.WithValue(ctx, operationEvents, sink)
+}
+
+// EmitOperationEvent sends event to the sink attached to context (if any)
+func EmitOperationEvent(ctx context.Context, evt OperationEvent) {
+	sink, ok := ctx.Value(operationEvents).(OperationEventSink)
+	if !ok || sink == nil {
+		return
+	}
+	if evt.Time.IsZero() {
+		evt.Time = time.Now()
+	}
+	sink.Emit(evt)
+}
+
+// HasOperationEvents returns true if context has operation events sink attached
+func HasOperationEvents(ctx context.Context) bool {
+	sink, ok := ctx.Value(operationEvents).(OperationEventSink)
+	return ok && sink != nil
+}
+
+type ndjsonEventSink struct {
+	_lock   sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewNDJSONEventSink returns sink writing each event as a separate JSON line
+func NewNDJSONEventSink(w io.Writer) OperationEventSink {
+	return &ndjsonEventSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *ndjsonEventSink) Emit(evt OperationEvent) {
+	s._lock.Lock()
+	defer s._lock.Unlock()
+	_ = s.encoder.Encode(evt)
+}
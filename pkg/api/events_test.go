@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestEmitOperationEvent(t *testing.T) {
+	RegisterTestingT(t)
+
+	// no sink attached
+	EmitOperationEvent(context.Background(), OperationEvent{Type: OperationEventStart})
+	Expect(HasOperationEvents(context.Background())).To(BeFalse())
+
+	buf := &bytes.Buffer{}
+	ctx := WithOperationEvents(context.Background(), NewNDJSONEventSink(buf))
+	Expect(HasOperationEvents(ctx)).To(BeTrue())
+
+	EmitOperationEvent(ctx, OperationEvent{Type: OperationEventStart, Operation: "deploy", Stack: "billing"})
+	EmitOperationEvent(ctx, OperationEvent{
+		Type: OperationEventStep, Operation: "deploy", URN: "urn:bucket", Op: "create",
+		Status: OperationStatusSucceeded, DurationMs: 1500,
+	})
+	EmitOperationEvent(ctx, OperationEvent{
+		Type: OperationEventSummary, Operation: "deploy", Stack: "billing", Status: OperationStatusSucceeded,
+		Update: &UpdateResult{StackName: "billing", Operations: map[string]int{"create": 1}},
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	Expect(lines).To(HaveLen(3))
+
+	var records []map[string]any
+	for _, line := range lines {
+		var record map[string]any
+		Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+		Expect(record["time"]).NotTo(BeEmpty())
+		records = append(records, record)
+	}
+	Expect(records[0]["type"]).To(Equal("start"))
+	Expect(records[0]).NotTo(HaveKey("urn"))
+	Expect(records[1]["urn"]).To(Equal("urn:bucket"))
+	Expect(records[1]["durationMs"]).To(BeEquivalentTo(1500))
+	Expect(records[2]["update"]).To(HaveKeyWithValue("operations", HaveKeyWithValue("create", BeEquivalentTo(1))))
+}
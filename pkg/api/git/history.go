@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package git
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pkg/errors"
+)
+
+// FileRevision is content of a file in the commit, Content is nil if the commit deletes the file
+type FileRevision struct {
+	Commit
+	Content []byte
+}
+
+func (r *repo) FileHistory(filePath string) ([]FileRevision, error) {
+	head, err := r.gitRepo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get HEAD reference")
+	}
+	commits, err := r.gitRepo.Log(&git.LogOptions{
+		From:     head.Hash(),
+		FileName: &filePath,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read history of %q", filePath)
+	}
+	defer commits.Close()
+
+	var res []FileRevision
+	if err := commits.ForEach(func(c *object.Commit) error {
+		revision := FileRevision{Commit: Commit{
+			Author:  c.Author.String(),
+			Hash:    c.Hash.String(),
+			Message: c.Message,
+			When:    c.Author.When,
+		}}
+		file, err := c.File(filePath)
+		if err != nil && !errors.Is(err, object.ErrFileNotFound) {
+			return errors.Wrapf(err, "failed to read %q in commit %s", filePath, c.Hash)
+		} else if err == nil {
+			content, err := file.Contents()
+			if err != nil {
+				return errors.Wrapf(err, "failed to read %q in commit %s", filePath, c.Hash)
+			}
+			revision.Content = []byte(content)
+		}
+		res = append(res, revision)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
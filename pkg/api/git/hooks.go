@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package git
+
+import (
+	"io"
+	"os"
+	"path"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/pkg/errors"
+)
+
+const hooksDir = "hooks"
+
+func (r *repo) Hook(name string) ([]byte, error) {
+	fs, err := r.dotGitFs()
+	if err != nil {
+		return nil, err
+	}
+	file, err := fs.Open(path.Join(hooksDir, name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to open git hook %q", name)
+	}
+	defer func() { _ = file.Close() }()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read git hook %q", name)
+	}
+	return content, nil
+}
+
+func (r *repo) InstallHook(name string, script []byte) error {
+	fs, err := r.dotGitFs()
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(hooksDir, 0o755); err != nil {
+		return errors.Wrapf(err, "failed to create git hooks dir")
+	}
+	hookPath := path.Join(hooksDir, name)
+	file, err := fs.OpenFile(hookPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open git hook %q", name)
+	}
+	defer func() { _ = file.Close() }()
+	if _, err := file.Write(script); err != nil {
+		return errors.Wrapf(err, "failed to write git hook %q", name)
+	}
+	// permissions of existing file are not changed by OpenFile
+	if change, ok := fs.(billy.Change); ok {
+		if err := change.Chmod(hookPath, 0o755); err != nil {
+			return errors.Wrapf(err, "failed to make git hook %q executable", name)
+		}
+	}
+	return nil
+}
+
+// dotGitFs returns filesystem of the git dir, which is not the one of the worktree for detected repositories
+func (r *repo) dotGitFs() (billy.Filesystem, error) {
+	if r.gitRepo == nil {
+		return nil, errors.New("git repository is not opened")
+	}
+	storage, ok := r.gitRepo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, errors.Errorf("git repository is not stored on filesystem")
+	}
+	return storage.Filesystem(), nil
+}
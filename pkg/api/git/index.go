@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package git
+
+import (
+	"io"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pkg/errors"
+)
+
+func (r *repo) IndexFiles(stagedOnly bool) (map[string][]byte, error) {
+	idx, err := r.gitRepo.Storer.Index()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read git index")
+	}
+
+	committed := make(map[string]plumbing.Hash)
+	if stagedOnly {
+		if head, err := r.gitRepo.Head(); err == nil {
+			commit, err := r.gitRepo.CommitObject(head.Hash())
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read HEAD commit")
+			}
+			tree, err := commit.Tree()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read tree of HEAD commit")
+			}
+			if err := tree.Files().ForEach(func(f *object.File) error {
+				committed[f.Name] = f.Hash
+				return nil
+			}); err != nil {
+				return nil, errors.Wrapf(err, "failed to list files of HEAD commit")
+			}
+		} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, errors.Wrapf(err, "failed to get HEAD reference")
+		}
+	}
+
+	res := make(map[string][]byte)
+	for _, entry := range idx.Entries {
+		if entry.Mode == filemode.Submodule || (stagedOnly && committed[entry.Name] == entry.Hash) {
+			continue
+		}
+		blob, err := r.gitRepo.BlobObject(entry.Hash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read staged content of %q", entry.Name)
+		}
+		reader, err := blob.Reader()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read staged content of %q", entry.Name)
+		}
+		content, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read staged content of %q", entry.Name)
+		}
+		res[entry.Name] = content
+	}
+	return res, nil
+}
@@ -195,6 +195,36 @@ func (_m *GitRepoMock) Exists(filePath string) bool {
 	return r0
 }
 
+// FileHistory provides a mock function with given fields: filePath
+func (_m *GitRepoMock) FileHistory(filePath string) ([]git.FileRevision, error) {
+	ret := _m.Called(filePath)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FileHistory")
+	}
+
+	var r0 []git.FileRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]git.FileRevision, error)); ok {
+		return rf(filePath)
+	}
+	if rf, ok := ret.Get(0).(func(string) []git.FileRevision); ok {
+		r0 = rf(filePath)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]git.FileRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(filePath)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Gitdir provides a mock function with no fields
 func (_m *GitRepoMock) Gitdir() string {
 	ret := _m.Called()
@@ -241,6 +271,66 @@ func (_m *GitRepoMock) Hash() (string, error) {
 	return r0, r1
 }
 
+// Hook provides a mock function with given fields: name
+func (_m *GitRepoMock) Hook(name string) ([]byte, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Hook")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]byte, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IndexFiles provides a mock function with given fields: stagedOnly
+func (_m *GitRepoMock) IndexFiles(stagedOnly bool) (map[string][]byte, error) {
+	ret := _m.Called(stagedOnly)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IndexFiles")
+	}
+
+	var r0 map[string][]byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(bool) (map[string][]byte, error)); ok {
+		return rf(stagedOnly)
+	}
+	if rf, ok := ret.Get(0).(func(bool) map[string][]byte); ok {
+		r0 = rf(stagedOnly)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(bool) error); ok {
+		r1 = rf(stagedOnly)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Init provides a mock function with given fields: wd, opts
 func (_m *GitRepoMock) Init(wd string, opts ...git.Option) error {
 	_va := make([]interface{}, len(opts))
@@ -291,6 +381,24 @@ func (_m *GitRepoMock) InitOrOpen(wd string, opts ...git.Option) error {
 	return r0
 }
 
+// InstallHook provides a mock function with given fields: name, script
+func (_m *GitRepoMock) InstallHook(name string, script []byte) error {
+	ret := _m.Called(name, script)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InstallHook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []byte) error); ok {
+		r0 = rf(name, script)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Log provides a mock function with no fields
 func (_m *GitRepoMock) Log() []git.Commit {
 	ret := _m.Called()
@@ -43,8 +43,16 @@ type Repo interface {
 	AddFileToIgnore(filePath string) error
 
 	AddFileToGit(filePath string) error
+	// IndexFiles returns contents of files in the git index, only of files staged for commit if stagedOnly is set
+	IndexFiles(stagedOnly bool) (map[string][]byte, error)
+	// Hook returns content of the git hook, nil if it is not installed
+	Hook(name string) ([]byte, error)
+	// InstallHook writes the executable git hook replacing the existing one
+	InstallHook(name string, script []byte) error
 	Commit(msg string, opts CommitOpts) error
 	Log() []Commit
+	// FileHistory returns revisions of the file in commits of HEAD changing it, newest first
+	FileHistory(filePath string) ([]FileRevision, error)
 	Workdir() string
 	Gitdir() string
 
@@ -56,6 +64,7 @@ type Commit struct {
 	Author  string
 	Hash    string
 	Message string
+	When    time.Time
 }
 
 type repo struct {
@@ -293,6 +302,7 @@ func (r *repo) Log() []Commit {
 				Author:  c.Author.String(),
 				Hash:    c.Hash.String(),
 				Message: c.Message,
+				When:    c.Author.When,
 			})
 		}
 	}
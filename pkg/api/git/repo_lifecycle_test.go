@@ -681,3 +681,89 @@ func nonEmptyLines(s string) []string {
 	}
 	return out
 }
+
+func TestIndexFiles(t *testing.T) {
+	RegisterTestingT(t)
+	wd := t.TempDir()
+	r := newRepoIn(wd)
+
+	writeWorktreeFile(r, "committed.txt", "v1")
+	Expect(r.AddFileToGit("committed.txt")).To(Succeed())
+
+	// nothing is committed yet, so every file of the index is staged
+	staged, err := r.IndexFiles(true)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(staged).To(Equal(map[string][]byte{"committed.txt": []byte("v1")}))
+	Expect(r.Commit("initial commit", CommitOpts{})).To(Succeed())
+
+	writeWorktreeFile(r, "new.txt", "new")
+	Expect(r.AddFileToGit("new.txt")).To(Succeed())
+	// not staged changes are not returned
+	writeWorktreeFile(r, "committed.txt", "v2")
+
+	staged, err = r.IndexFiles(true)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(staged).To(Equal(map[string][]byte{"new.txt": []byte("new")}))
+
+	all, err := r.IndexFiles(false)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(all).To(Equal(map[string][]byte{"committed.txt": []byte("v1"), "new.txt": []byte("new")}))
+}
+
+func TestHooks(t *testing.T) {
+	RegisterTestingT(t)
+	wd := t.TempDir()
+	r := newRepoIn(wd)
+
+	hook, err := r.Hook("pre-commit")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(hook).To(BeNil())
+
+	Expect(r.InstallHook("pre-commit", []byte("#!/bin/sh\nexit 0\n"))).To(Succeed())
+	Expect(r.InstallHook("pre-commit", []byte("#!/bin/sh\nexit 1\n"))).To(Succeed())
+
+	hook, err = r.Hook("pre-commit")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(string(hook)).To(Equal("#!/bin/sh\nexit 1\n"))
+
+	info, err := os.Stat(filepath.Join(wd, ".git", "hooks", "pre-commit"))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(info.Mode().Perm() & 0o100).NotTo(BeZero())
+}
+
+func TestFileHistory(t *testing.T) {
+	RegisterTestingT(t)
+	wd := t.TempDir()
+	r := newRepoIn(wd)
+
+	history, err := r.FileHistory("tracked.txt")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(history).To(BeEmpty())
+
+	writeWorktreeFile(r, "tracked.txt", "v1")
+	Expect(r.AddFileToGit("tracked.txt")).To(Succeed())
+	Expect(r.Commit("add tracked", CommitOpts{})).To(Succeed())
+
+	writeWorktreeFile(r, "other.txt", "other")
+	Expect(r.AddFileToGit("other.txt")).To(Succeed())
+	Expect(r.Commit("add other", CommitOpts{})).To(Succeed())
+
+	writeWorktreeFile(r, "tracked.txt", "v2")
+	Expect(r.AddFileToGit("tracked.txt")).To(Succeed())
+	Expect(r.Commit("update tracked", CommitOpts{})).To(Succeed())
+
+	Expect(os.Remove(filepath.Join(wd, "tracked.txt"))).To(Succeed())
+	Expect(r.Commit("remove tracked", CommitOpts{All: true})).To(Succeed())
+
+	history, err = r.FileHistory("tracked.txt")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(history).To(HaveLen(3))
+	Expect(history[0].Message).To(Equal("remove tracked"))
+	Expect(history[0].Content).To(BeNil())
+	Expect(history[1].Message).To(Equal("update tracked"))
+	Expect(string(history[1].Content)).To(Equal("v2"))
+	Expect(history[2].Message).To(Equal("add tracked"))
+	Expect(string(history[2].Content)).To(Equal("v1"))
+	Expect(history[2].Author).To(Equal("Test Author <author@test.local>"))
+	Expect(history[2].When.IsZero()).To(BeFalse())
+}
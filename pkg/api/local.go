@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"sync"
+)
+
+// Kinds of containers substituting cloud resources when stack runs locally (see RegisterLocalStandIns)
+const (
+	LocalStandInPostgres = "postgres"
+	LocalStandInMysql    = "mysql"
+	LocalStandInRedis    = "redis"
+	LocalStandInMongodb  = "mongodb"
+)
+
+// UpParams describes local run of the client stack
+type UpParams struct {
+	StackParams `json:",inline" yaml:",inline"`
+	// Detach leaves containers running instead of following their logs until interrupted
+	Detach bool `json:"detach" yaml:"detach"`
+	// Pull forces pulling images of services and stand-ins even if they are present locally
+	Pull bool `json:"pull" yaml:"pull"`
+}
+
+// LocalResource describes running local stand-in of the resource declared in server.yaml
+type LocalResource struct {
+	Descriptor ResourceDescriptor
+	// StackName is the name of client stack using the resource (cloud creates database and user named after it)
+	StackName string
+	Host      string
+	Port      string
+	User      string
+	Password  string
+	Database  string
+}
+
+// LocalResourceContext is what the resource provides to the client stack using it
+type LocalResourceContext struct {
+	Env map[string]string
+	// TplValues are values of ${resource:<name>.<key>} placeholders
+	TplValues map[string]string
+}
+
+// LocalStandIn describes how resource of the cloud is substituted when stack runs locally.
+// Context must provide the same env variables and template values as compute processor of the resource does in the cloud.
+type LocalStandIn struct {
+	Kind    string
+	Context func(res LocalResource) LocalResourceContext
+}
+
+type LocalStandInsRegister map[string]LocalStandIn
+
+var (
+	localStandInsLock    sync.RWMutex
+	localStandInsMapping = LocalStandInsRegister{}
+)
+
+func RegisterLocalStandIns(mapping LocalStandInsRegister) {
+	localStandInsLock.Lock()
+	defer localStandInsLock.Unlock()
+	for resType, standIn := range mapping {
+		localStandInsMapping[resType] = standIn
+	}
+}
+
+// LocalStandInFor returns stand-in of the resource type (false if resource type cannot run locally)
+func LocalStandInFor(resourceType string) (LocalStandIn, bool) {
+	localStandInsLock.RLock()
+	defer localStandInsLock.RUnlock()
+	standIn, found := localStandInsMapping[resourceType]
+	return standIn, found
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StackLock describes lock held on a stack by a running operation, so that concurrent operations
+// (e.g. two CI jobs deploying the same stack) fail or wait instead of corrupting state
+type StackLock struct {
+	ID        string    `json:"id" yaml:"id"`
+	Stack     string    `json:"stack" yaml:"stack"`
+	Operation string    `json:"operation" yaml:"operation"`
+	Holder    string    `json:"holder" yaml:"holder"`
+	RunURL    string    `json:"runUrl,omitempty" yaml:"runUrl,omitempty"`
+	StartedAt time.Time `json:"startedAt" yaml:"startedAt"`
+}
+
+// NewStackLock returns lock for the operation on the stack held by the current process
+func NewStackLock(stack, operation string) StackLock {
+	return StackLock{
+		ID:        uuid.NewString(),
+		Stack:     stack,
+		Operation: operation,
+		Holder:    lockHolder(),
+		RunURL:    CIRunURL(),
+		StartedAt: time.Now().UTC(),
+	}
+}
+
+func (l StackLock) String() string {
+	res := fmt.Sprintf("%s of %q by %s since %s (%s ago)", l.Operation, l.Stack, l.Holder,
+		l.StartedAt.Format(time.RFC3339), time.Since(l.StartedAt).Round(time.Second))
+	if l.RunURL != "" {
+		res += fmt.Sprintf(", run: %s", l.RunURL)
+	}
+	return res
+}
+
+// CIRunURL returns URL of the CI run the process is executed in (empty if unknown)
+func CIRunURL() string {
+	if url := os.Getenv("SC_CI_RUN_URL"); url != "" {
+		return url
+	}
+	if server, repo, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"); server != "" && repo != "" && runID != "" {
+		return fmt.Sprintf("%s/%s/actions/runs/%s", strings.TrimSuffix(server, "/"), repo, runID)
+	}
+	for _, env := range []string{"CI_JOB_URL", "BUILDKITE_BUILD_URL", "BUILD_URL"} {
+		if url := os.Getenv(env); url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+func lockHolder() string {
+	hostname, _ := os.Hostname()
+	username := os.Getenv("GITHUB_ACTOR")
+	if username == "" {
+		if u, err := user.Current(); err == nil {
+			username = u.Username
+		}
+	}
+	return fmt.Sprintf("%s@%s (pid %d)", username, hostname, os.Getpid())
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCIRunURL(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, env := range []string{"SC_CI_RUN_URL", "GITHUB_SERVER_URL", "GITHUB_REPOSITORY", "GITHUB_RUN_ID", "CI_JOB_URL", "BUILDKITE_BUILD_URL", "BUILD_URL"} {
+		t.Setenv(env, "")
+	}
+	Expect(CIRunURL()).To(BeEmpty())
+
+	t.Setenv("CI_JOB_URL", "https://gitlab.example.com/org/repo/-/jobs/1")
+	Expect(CIRunURL()).To(Equal("https://gitlab.example.com/org/repo/-/jobs/1"))
+
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com/")
+	t.Setenv("GITHUB_REPOSITORY", "org/repo")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	Expect(CIRunURL()).To(Equal("https://github.com/org/repo/actions/runs/42"))
+
+	lock := NewStackLock("org/project/billing--production", "deploy")
+	Expect(lock.ID).NotTo(BeEmpty())
+	Expect(lock.RunURL).To(Equal("https://github.com/org/repo/actions/runs/42"))
+	Expect(lock.String()).To(HavePrefix(`deploy of "org/project/billing--production" by `))
+	Expect(lock.String()).To(HaveSuffix(", run: https://github.com/org/repo/actions/runs/42"))
+}
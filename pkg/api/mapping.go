@@ -54,6 +54,7 @@ var (
 	cloudComposeConverterMapping     = CloudComposeConfigRegister{}
 	cloudSingleImageConverterMapping = CloudSingleImageConfigRegister{}
 	cloudStaticSiteConverterMapping  = CloudStaticSiteConfigRegister{}
+	deployInputMapping               = ConfigRegisterMap{}
 )
 
 func ConvertDescriptor[T any](from any, to *T) (*T, error) {
@@ -114,6 +115,20 @@ func RegisterCloudStaticSiteConverter(mapping CloudStaticSiteConfigRegister) {
 	cloudStaticSiteConverterMapping = lo.Assign(cloudStaticSiteConverterMapping, mapping)
 }
 
+// RegisterDeployInputReader registers readers of resolved client stack descriptors (per template type),
+// which allow replaying of previously captured deploy revisions
+func RegisterDeployInputReader(mapping ConfigRegisterMap) {
+	deployInputMapping = lo.Assign(deployInputMapping, mapping)
+}
+
+func ReadDeployInput(config *Config, templateType string) (Config, error) {
+	if fnc, found := deployInputMapping[templateType]; !found {
+		return *config, errors.Errorf("unsupported deploy input type %q", templateType)
+	} else {
+		return fnc(config)
+	}
+}
+
 func RegisterCloudHelper(mapping CloudHelpersRegisterMap) {
 	cloudHelpersConfigMapping = lo.Assign(cloudHelpersConfigMapping, mapping)
 }
@@ -146,7 +161,11 @@ type Provisioner interface {
 
 	SetPublicKey(pubKey string)
 
-	DeployStack(ctx context.Context, cfg *ConfigFile, stack Stack, params DeployParams) error
+	DeployStack(ctx context.Context, cfg *ConfigFile, stack Stack, params DeployParams) (*UpdateResult, error)
+
+	RollbackStack(ctx context.Context, cfg *ConfigFile, stack Stack, params DeployParams, revision DeployRevision) (*UpdateResult, error)
+
+	StackHistory(ctx context.Context, cfg *ConfigFile, stack Stack, params StackParams) ([]StackHistoryEntry, error)
 
 	DestroyChildStack(ctx context.Context, cfg *ConfigFile, stack Stack, params DestroyParams, preview bool) error
 
@@ -156,8 +175,18 @@ type Provisioner interface {
 
 	OutputsStack(ctx context.Context, cfg *ConfigFile, stack Stack, params StackParams) (*OutputsResult, error)
 
+	DriftStack(ctx context.Context, cfg *ConfigFile, stack Stack, params StackParams) (*DriftReport, error)
+
+	// ExportStack returns checkpoint of the stack with decrypted secrets, nil if the stack does not exist
+	ExportStack(ctx context.Context, cfg *ConfigFile, stack Stack, params StackParams) (*StackState, error)
+
+	// ImportStack replaces checkpoint of the stack (creating it if needed) encrypting secrets with its secrets provider
+	ImportStack(ctx context.Context, cfg *ConfigFile, stack Stack, params StackParams, state StackState) error
+
 	CancelStack(ctx context.Context, cfg *ConfigFile, stack Stack, params StackParams) error
 
+	UnlockStack(ctx context.Context, cfg *ConfigFile, stack Stack, params StackParams) (*StackLock, error)
+
 	DestroyParentStack(ctx context.Context, cfg *ConfigFile, parentStack Stack, params DestroyParams, preview bool) error
 
 	SetConfigReader(ProvisionerFieldConfigReaderFunc)
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OutputsFormatJson   = "json"
+	OutputsFormatYaml   = "yaml"
+	OutputsFormatDotenv = "dotenv"
+	OutputsFormatShell  = "shell"
+
+	// MaskedOutputValue replaces values of secret outputs unless they are revealed
+	MaskedOutputValue = "[secret]"
+)
+
+var (
+	envVarNameInvalidChars = regexp.MustCompile(`[^A-Z0-9_]+`)
+	dotenvSafeValue        = regexp.MustCompile(`^[A-Za-z0-9_./:@,+=%-]*$`)
+)
+
+// Masked returns copy of the result with values of secret outputs replaced
+func (r *OutputsResult) Masked() *OutputsResult {
+	return &OutputsResult{
+		StackName: r.StackName,
+		Secrets:   r.Secrets,
+		Outputs: lo.MapValues(r.Outputs, func(value any, key string) any {
+			return lo.Ternary[any](lo.Contains(r.Secrets, key), MaskedOutputValue, value)
+		}),
+	}
+}
+
+// Lookup returns value of the output by path: output name optionally followed by dot-separated
+// keys (or indexes) of the nested value, e.g. `db-config.host` or `endpoints.0`
+func (r *OutputsResult) Lookup(path string) (any, error) {
+	key, rest := path, ""
+	if _, found := r.Outputs[path]; !found {
+		key, rest, _ = strings.Cut(path, ".")
+	}
+	value, found := r.Outputs[key]
+	if !found {
+		return nil, errors.Errorf("output %q is not found in stack %q", key, r.StackName)
+	}
+	if rest == "" {
+		return value, nil
+	}
+	if value == MaskedOutputValue && lo.Contains(r.Secrets, key) {
+		return nil, errors.Errorf("output %q is secret and must be revealed to look up %q", key, path)
+	}
+	current := value
+	// complex values are stored as JSON strings
+	if s, ok := value.(string); ok {
+		if err := json.Unmarshal([]byte(s), &current); err != nil {
+			return nil, errors.Errorf("output %q is not an object, cannot look up %q", key, path)
+		}
+	}
+	for _, part := range strings.Split(rest, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			if current, found = v[part]; !found {
+				return nil, errors.Errorf("key %q is not found in output %q", part, key)
+			}
+		case []any:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, errors.Errorf("index %q is out of range of output %q", part, key)
+			}
+			current = v[idx]
+		default:
+			return nil, errors.Errorf("cannot look up %q in scalar value of output %q", part, key)
+		}
+	}
+	return current, nil
+}
+
+// FormatOutputs renders outputs in the format. When outputs of several stacks are aggregated,
+// dotenv and shell variable names are prefixed with names of the stacks.
+func FormatOutputs(results []*OutputsResult, format string, aggregate bool) (string, error) {
+	var doc any = results
+	if !aggregate && len(results) == 1 {
+		doc = results[0]
+	}
+	switch format {
+	case "", OutputsFormatJson:
+		res, err := json.Marshal(doc)
+		return string(res) + "\n", err
+	case OutputsFormatYaml:
+		res, err := yaml.Marshal(doc)
+		return string(res), err
+	case OutputsFormatDotenv, OutputsFormatShell:
+		res := strings.Builder{}
+		for _, result := range results {
+			prefix := lo.Ternary(aggregate, outputsStackPrefix(result.StackName), "")
+			keys := lo.Keys(result.Outputs)
+			sort.Strings(keys)
+			for _, key := range keys {
+				line, err := FormatOutputValue(EnvVarName(prefix, key), result.Outputs[key], format)
+				if err != nil {
+					return "", err
+				}
+				res.WriteString(line)
+			}
+		}
+		return res.String(), nil
+	default:
+		return "", unsupportedOutputsFormat(format)
+	}
+}
+
+// FormatOutputValue renders single value: as-is for scalars in json and yaml formats (so that it can be used in scripts directly),
+// or as variable assignment in dotenv and shell formats
+func FormatOutputValue(name string, value any, format string) (string, error) {
+	str, isString := value.(string)
+	if !isString && value != nil {
+		switch format {
+		case OutputsFormatYaml:
+			res, err := yaml.Marshal(value)
+			return string(res), err
+		default:
+			res, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			str = string(res)
+		}
+	}
+	switch format {
+	case OutputsFormatDotenv:
+		return fmt.Sprintf("%s=%s\n", name, dotenvQuote(str)), nil
+	case OutputsFormatShell:
+		return fmt.Sprintf("export %s=%s\n", name, shellQuote(str)), nil
+	case "", OutputsFormatJson, OutputsFormatYaml:
+		return str + "\n", nil
+	default:
+		return "", unsupportedOutputsFormat(format)
+	}
+}
+
+func unsupportedOutputsFormat(format string) error {
+	return errors.Errorf("unsupported outputs format %q, expected one of [%s]", format,
+		strings.Join([]string{OutputsFormatJson, OutputsFormatYaml, OutputsFormatDotenv, OutputsFormatShell}, ", "))
+}
+
+// EnvVarName converts output name (optionally prefixed) into the name of environment variable, e.g. db-url -> DB_URL
+func EnvVarName(prefix, key string) string {
+	name := strings.Trim(envVarNameInvalidChars.ReplaceAllString(strings.ToUpper(key), "_"), "_")
+	if prefix != "" {
+		name = EnvVarName("", prefix) + "_" + name
+	}
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// outputsStackPrefix returns name of the stack without organization, project and environment
+func outputsStackPrefix(stackName string) string {
+	name := stackName[strings.LastIndex(stackName, "/")+1:]
+	name, _, _ = strings.Cut(name, "--")
+	return name
+}
+
+// dotenvQuote quotes value so that dotenv loaders read it as is: single quotes are taken literally,
+// values which cannot be single quoted are double quoted with $ escaped since loaders expand $VAR in double quotes
+func dotenvQuote(value string) string {
+	if dotenvSafeValue.MatchString(value) {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n") {
+		return "'" + value + "'"
+	}
+	return strings.ReplaceAll(strconv.Quote(value), "$", `\$`)
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOutputsResult_Lookup(t *testing.T) {
+	RegisterTestingT(t)
+
+	res := &OutputsResult{
+		StackName: "org/project/billing--production",
+		Outputs: map[string]any{
+			"db-url":    "postgres://db:5432",
+			"db-config": `{"host":"db","ports":[5432,5433]}`,
+			"password":  "s3cr3t",
+		},
+		Secrets: []string{"password"},
+	}
+
+	Expect(res.Lookup("db-url")).To(Equal("postgres://db:5432"))
+	Expect(res.Lookup("db-config.host")).To(Equal("db"))
+	Expect(res.Lookup("db-config.ports.1")).To(Equal(float64(5433)))
+	_, err := res.Lookup("db-config.ports.5")
+	Expect(err).To(MatchError(ContainSubstring("out of range")))
+	_, err = res.Lookup("unknown")
+	Expect(err).To(MatchError(ContainSubstring(`output "unknown" is not found`)))
+
+	masked := res.Masked()
+	Expect(masked.Lookup("password")).To(Equal(MaskedOutputValue))
+	Expect(res.Lookup("password")).To(Equal("s3cr3t"))
+}
+
+func TestFormatOutputs(t *testing.T) {
+	RegisterTestingT(t)
+
+	billing := &OutputsResult{
+		StackName: "org/project/billing--production",
+		Outputs: map[string]any{
+			"db-url": "postgres://db:5432",
+			"motd":   "it's fine",
+		},
+	}
+	web := &OutputsResult{
+		StackName: "org/project/web--production",
+		Outputs:   map[string]any{"url": "https://example.com/?a=b c"},
+	}
+
+	out, err := FormatOutputs([]*OutputsResult{billing}, OutputsFormatJson, false)
+	Expect(err).To(BeNil())
+	Expect(out).To(Equal(`{"stackName":"org/project/billing--production","outputs":{"db-url":"postgres://db:5432","motd":"it's fine"}}` + "\n"))
+
+	out, err = FormatOutputs([]*OutputsResult{billing}, OutputsFormatDotenv, false)
+	Expect(err).To(BeNil())
+	Expect(out).To(Equal("DB_URL=postgres://db:5432\nMOTD=\"it's fine\"\n"))
+
+	out, err = FormatOutputs([]*OutputsResult{billing, web}, OutputsFormatShell, true)
+	Expect(err).To(BeNil())
+	Expect(out).To(Equal("export BILLING_DB_URL='postgres://db:5432'\n" +
+		"export BILLING_MOTD='it'\\''s fine'\n" +
+		"export WEB_URL='https://example.com/?a=b c'\n"))
+
+	out, err = FormatOutputs([]*OutputsResult{billing, web}, OutputsFormatYaml, true)
+	Expect(err).To(BeNil())
+	Expect(out).To(HavePrefix("- stackName: org/project/billing--production\n"))
+
+	_, err = FormatOutputs([]*OutputsResult{billing}, "xml", false)
+	Expect(err).To(MatchError(ContainSubstring(`unsupported outputs format "xml"`)))
+
+	out, err = FormatOutputValue("PORTS", []any{float64(5432)}, OutputsFormatDotenv)
+	Expect(err).To(BeNil())
+	Expect(out).To(Equal("PORTS='[5432]'\n"))
+
+	out, err = FormatOutputValue("PASSWORD", "it's $HOME", OutputsFormatDotenv)
+	Expect(err).To(BeNil())
+	Expect(out).To(Equal(`PASSWORD="it's \$HOME"` + "\n"))
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// PolicyDescriptor describes a guardrail evaluated against the preview plan before stack is updated
+type PolicyDescriptor struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Environments policy applies to (default: all)
+	Environments []string `json:"environments,omitempty" yaml:"environments,omitempty"`
+	// ResourceTypes policy applies to (default: all), either resource types of server.yaml (e.g. aws-rds-postgres)
+	// or types of cloud resources (e.g. aws:rds/instance:Instance)
+	ResourceTypes []string `json:"resourceTypes,omitempty" yaml:"resourceTypes,omitempty"`
+	DenyReplace   bool     `json:"denyReplace,omitempty" yaml:"denyReplace,omitempty"`
+	DenyDelete    bool     `json:"denyDelete,omitempty" yaml:"denyDelete,omitempty"`
+	// MaxDeletes limits number of matching resources deleted by a single update (replacements are not counted)
+	MaxDeletes *int `json:"maxDeletes,omitempty" yaml:"maxDeletes,omitempty"`
+}
+
+type PolicyViolation struct {
+	Policy  string `json:"policy" yaml:"policy"`
+	URN     string `json:"urn,omitempty" yaml:"urn,omitempty"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (v PolicyViolation) String() string {
+	if v.URN == "" {
+		return fmt.Sprintf("[%s] %s", v.Policy, v.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", v.Policy, v.URN, v.Message)
+}
+
+// PolicyResourceTypesRegister maps resource type of server.yaml to types of cloud resources it creates
+type PolicyResourceTypesRegister map[string][]string
+
+var (
+	policyResourceTypesLock    sync.RWMutex
+	policyResourceTypesMapping = PolicyResourceTypesRegister{}
+)
+
+func RegisterPolicyResourceTypes(mapping PolicyResourceTypesRegister) {
+	policyResourceTypesLock.Lock()
+	defer policyResourceTypesLock.Unlock()
+	for resType, cloudTypes := range mapping {
+		policyResourceTypesMapping[resType] = lo.Uniq(append(policyResourceTypesMapping[resType], cloudTypes...))
+	}
+}
+
+// cloudResourceTypes returns types of cloud resources matching the policy resource type
+func cloudResourceTypes(resourceType string) []string {
+	if strings.Contains(resourceType, ":") {
+		return []string{resourceType}
+	}
+	policyResourceTypesLock.RLock()
+	defer policyResourceTypesLock.RUnlock()
+	return policyResourceTypesMapping[resourceType]
+}
+
+func (p *PolicyDescriptor) appliesToEnv(env string) bool {
+	// resources of unknown environment are always checked
+	return len(p.Environments) == 0 || env == "" || lo.Contains(p.Environments, env)
+}
+
+func (p *PolicyDescriptor) appliesToType(cloudType string) bool {
+	if len(p.ResourceTypes) == 0 {
+		return true
+	}
+	return lo.SomeBy(p.ResourceTypes, func(t string) bool {
+		return lo.Contains(cloudResourceTypes(t), cloudType)
+	})
+}
+
+func (p *PolicyDescriptor) validate() error {
+	if p.Name == "" {
+		return errors.Errorf("policy name is required")
+	}
+	for _, t := range p.ResourceTypes {
+		if len(cloudResourceTypes(t)) == 0 {
+			return errors.Errorf("policy %q: resource type %q is not supported by policies", p.Name, t)
+		}
+	}
+	if p.MaxDeletes != nil && *p.MaxDeletes < 0 {
+		return errors.Errorf("policy %q: maxDeletes must not be negative", p.Name)
+	}
+	return nil
+}
+
+// HasPoliciesForEnv returns true if any of the policies applies to the environment
+func HasPoliciesForEnv(policies []PolicyDescriptor, env string) bool {
+	return lo.SomeBy(policies, func(p PolicyDescriptor) bool {
+		return p.appliesToEnv(env)
+	})
+}
+
+// EvaluatePolicies checks planned steps against policies, steps without known environment are attributed to env.
+// Violations of overridden policies are ignored.
+func EvaluatePolicies(policies []PolicyDescriptor, env string, steps []PreviewStep, overrides []string) ([]PolicyViolation, error) {
+	for _, override := range overrides {
+		if !lo.ContainsBy(policies, func(p PolicyDescriptor) bool { return p.Name == override }) {
+			return nil, errors.Errorf("cannot override policy %q: it is not configured", override)
+		}
+	}
+	var res []PolicyViolation
+	for _, policy := range policies {
+		if err := policy.validate(); err != nil {
+			return nil, err
+		}
+		if lo.Contains(overrides, policy.Name) {
+			continue
+		}
+		deletes := 0
+		for _, step := range steps {
+			stepEnv := lo.Ternary(step.Environment != "", step.Environment, env)
+			if !policy.appliesToEnv(stepEnv) || !policy.appliesToType(step.Type) {
+				continue
+			}
+			switch step.Op {
+			case PreviewOpReplace:
+				if policy.DenyReplace {
+					res = append(res, PolicyViolation{Policy: policy.Name, URN: step.URN, Message: fmt.Sprintf("replacement of %s is denied", step.Type)})
+				}
+			case PreviewOpDelete:
+				deletes++
+				if policy.DenyDelete {
+					res = append(res, PolicyViolation{Policy: policy.Name, URN: step.URN, Message: fmt.Sprintf("deletion of %s is denied", step.Type)})
+				}
+			}
+		}
+		if policy.MaxDeletes != nil && deletes > *policy.MaxDeletes {
+			res = append(res, PolicyViolation{Policy: policy.Name, Message: fmt.Sprintf("%d resources would be deleted, at most %d allowed", deletes, *policy.MaxDeletes)})
+		}
+	}
+	return res, nil
+}
+
+// PolicyViolationsError builds error describing violations and how to override them
+func PolicyViolationsError(stackName string, violations []PolicyViolation) error {
+	policies := lo.Uniq(lo.Map(violations, func(v PolicyViolation, _ int) string {
+		return v.Policy
+	}))
+	return errors.Errorf("update of stack %q is aborted due to policy violations:\n  %s\nuse %s to proceed anyway",
+		stackName,
+		strings.Join(lo.Map(violations, func(v PolicyViolation, _ int) string { return v.String() }), "\n  "),
+		strings.Join(lo.Map(policies, func(p string, _ int) string { return "--override-policy " + p }), " "))
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+)
+
+func TestEvaluatePolicies(t *testing.T) {
+	RegisterTestingT(t)
+
+	RegisterPolicyResourceTypes(PolicyResourceTypesRegister{
+		"test-postgres": {"aws:rds/instance:Instance"},
+	})
+	policies := []PolicyDescriptor{
+		{Name: "protect-db", Environments: []string{"production"}, ResourceTypes: []string{"test-postgres"}, DenyReplace: true, DenyDelete: true},
+		{Name: "limit-deletes", MaxDeletes: lo.ToPtr(1)},
+	}
+	steps := []PreviewStep{
+		{URN: "urn:db", Type: "aws:rds/instance:Instance", Op: PreviewOpReplace},
+		{URN: "urn:staging-db", Type: "aws:rds/instance:Instance", Op: PreviewOpDelete, Environment: "staging"},
+		{URN: "urn:bucket", Type: "aws:s3/bucket:Bucket", Op: PreviewOpDelete},
+		{URN: "urn:svc", Type: "aws:ecs/service:Service", Op: PreviewOpUpdate},
+	}
+
+	Expect(HasPoliciesForEnv(policies[:1], "staging")).To(BeFalse())
+	Expect(HasPoliciesForEnv(policies, "staging")).To(BeTrue())
+
+	violations, err := EvaluatePolicies(policies, "production", steps, nil)
+	Expect(err).To(BeNil())
+	Expect(violations).To(ConsistOf(
+		PolicyViolation{Policy: "protect-db", URN: "urn:db", Message: "replacement of aws:rds/instance:Instance is denied"},
+		PolicyViolation{Policy: "limit-deletes", Message: "2 resources would be deleted, at most 1 allowed"},
+	))
+
+	violations, err = EvaluatePolicies(policies, "staging", steps, []string{"limit-deletes"})
+	Expect(err).To(BeNil())
+	Expect(violations).To(BeEmpty())
+
+	_, err = EvaluatePolicies(policies, "production", steps, []string{"unknown"})
+	Expect(err).To(MatchError(ContainSubstring(`cannot override policy "unknown"`)))
+
+	_, err = EvaluatePolicies([]PolicyDescriptor{{Name: "bad", ResourceTypes: []string{"unknown-type"}}}, "production", steps, nil)
+	Expect(err).To(MatchError(ContainSubstring(`resource type "unknown-type" is not supported`)))
+
+	err = PolicyViolationsError("infra", []PolicyViolation{{Policy: "protect-db", URN: "urn:db", Message: "denied"}})
+	Expect(err.Error()).To(ContainSubstring("[protect-db] urn:db: denied"))
+	Expect(err.Error()).To(ContainSubstring("--override-policy protect-db"))
+}
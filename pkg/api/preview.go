@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const (
+	PreviewOpCreate  = "create"
+	PreviewOpUpdate  = "update"
+	PreviewOpReplace = "replace"
+	PreviewOpDelete  = "delete"
+)
+
+// PreviewStep is a change planned for a single resource
+type PreviewStep struct {
+	URN  string `json:"urn" yaml:"urn"`
+	Type string `json:"type" yaml:"type"`
+	Op   string `json:"op" yaml:"op"`
+	// Environment of the resource (if known from its tags)
+	Environment string   `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Properties  []string `json:"properties,omitempty" yaml:"properties,omitempty"` // paths of changing properties
+	// ReplaceKeys are properties which cannot be updated in place and force replacement of the resource
+	ReplaceKeys   []string `json:"replaceKeys,omitempty" yaml:"replaceKeys,omitempty"`
+	ForcedReplace bool     `json:"forcedReplace,omitempty" yaml:"forcedReplace,omitempty"`
+}
+
+// IsDestructive returns true if step deletes or replaces the resource
+func (s PreviewStep) IsDestructive() bool {
+	return s.Op == PreviewOpReplace || s.Op == PreviewOpDelete
+}
+
+// DestructiveSteps returns steps which delete or replace resources
+func (r *PreviewResult) DestructiveSteps() []PreviewStep {
+	return lo.Filter(r.Steps, func(s PreviewStep, _ int) bool {
+		return s.IsDestructive()
+	})
+}
+
+// Markdown renders planned changes as a Markdown document (e.g. for PR comments)
+func (r *PreviewResult) Markdown() string {
+	res := strings.Builder{}
+	res.WriteString(fmt.Sprintf("### Preview of `%s`\n\n", r.StackName))
+	ops := lo.Keys(r.Operations)
+	sort.Strings(ops)
+	res.WriteString(strings.Join(lo.Map(ops, func(op string, _ int) string {
+		return fmt.Sprintf("**%s**: %d", op, r.Operations[op])
+	}), ", "))
+	res.WriteString("\n\n")
+	if len(r.Steps) == 0 {
+		res.WriteString("No changes planned.\n")
+		return res.String()
+	}
+	if destructive := r.DestructiveSteps(); len(destructive) > 0 {
+		res.WriteString(fmt.Sprintf("> [!WARNING]\n> %d resource(s) will be replaced or deleted\n\n", len(destructive)))
+	}
+	res.WriteString("| Op | Type | Resource | Changed properties |\n")
+	res.WriteString("|----|------|----------|--------------------|\n")
+	for _, step := range r.Steps {
+		op := step.Op
+		if step.IsDestructive() {
+			op = fmt.Sprintf("**%s** :warning:", op)
+		}
+		props := lo.Map(step.Properties, func(p string, _ int) string {
+			if lo.Contains(step.ReplaceKeys, p) {
+				return fmt.Sprintf("`%s` (forces replace)", p)
+			}
+			return fmt.Sprintf("`%s`", p)
+		})
+		res.WriteString(fmt.Sprintf("| %s | `%s` | `%s` | %s |\n", op, step.Type, step.URN, strings.Join(props, "<br>")))
+	}
+	return res.String()
+}
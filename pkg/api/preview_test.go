@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPreviewResult_Markdown(t *testing.T) {
+	RegisterTestingT(t)
+
+	res := &PreviewResult{
+		StackName:  "infra--production",
+		Operations: map[string]int{"update": 1, "replace": 1, "same": 10},
+		Steps: []PreviewStep{
+			{URN: "urn:db", Type: "aws:rds/instance:Instance", Op: PreviewOpReplace, Properties: []string{"engineVersion", "storageEncrypted"}, ReplaceKeys: []string{"storageEncrypted"}, ForcedReplace: true},
+			{URN: "urn:svc", Type: "aws:ecs/service:Service", Op: PreviewOpUpdate, Properties: []string{"desiredCount"}},
+		},
+	}
+	Expect(res.DestructiveSteps()).To(HaveLen(1))
+
+	md := res.Markdown()
+	Expect(md).To(ContainSubstring("### Preview of `infra--production`"))
+	Expect(md).To(ContainSubstring("**replace**: 1, **same**: 10, **update**: 1"))
+	Expect(md).To(ContainSubstring("1 resource(s) will be replaced or deleted"))
+	Expect(md).To(ContainSubstring("| **replace** :warning: | `aws:rds/instance:Instance` | `urn:db` | `engineVersion`<br>`storageEncrypted` (forces replace) |"))
+	Expect(md).To(ContainSubstring("| update | `aws:ecs/service:Service` | `urn:svc` | `desiredCount` |"))
+
+	res.Steps = nil
+	Expect(res.Markdown()).To(ContainSubstring("No changes planned."))
+}
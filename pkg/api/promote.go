@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	// ImageDigestOutputPrefix prefixes stack outputs holding immutable references of deployed images
+	ImageDigestOutputPrefix = "sc-image-digest--"
+	// DeployVersionOutput is the stack output holding the version stack was deployed with
+	DeployVersionOutput = "sc-deploy-version"
+	// PromotedFromOutput is the stack output holding the environment deployed images were promoted from
+	PromotedFromOutput = "sc-promoted-from"
+)
+
+// ImageDigestOutputName returns name of the stack output holding immutable reference of the image
+func ImageDigestOutputName(imageName string) string {
+	return ImageDigestOutputPrefix + imageName
+}
+
+// PromotedImagesFromOutputs returns image name -> immutable image reference (name@sha256:...)
+// for every image exported to the stack outputs
+func PromotedImagesFromOutputs(outputs map[string]any) (map[string]string, error) {
+	res := make(map[string]string)
+	for key, value := range outputs {
+		imageName, ok := strings.CutPrefix(key, ImageDigestOutputPrefix)
+		if !ok {
+			continue
+		}
+		ref, _ := value.(string)
+		if !strings.Contains(ref, "@sha256:") {
+			return nil, errors.Errorf("output %q does not contain image digest: %q", key, ref)
+		}
+		res[imageName] = ref
+	}
+	return res, nil
+}
+
+// CheckPromotable verifies images built for the source client config can be deployed with the target client config,
+// i.e. nothing that affects images differs between the two
+func CheckPromotable(from, to StackClientDescriptor) error {
+	if from.Type != to.Type {
+		return errors.Errorf("stack type differs: %q != %q", from.Type, to.Type)
+	}
+	switch fromCfg := from.Config.Config.(type) {
+	case *StackConfigCompose:
+		toCfg, ok := to.Config.Config.(*StackConfigCompose)
+		if !ok {
+			return errors.Errorf("unexpected config type %T", to.Config.Config)
+		}
+		if fromCfg.DockerComposeFile != toCfg.DockerComposeFile {
+			return errors.Errorf("dockerComposeFile differs: %q != %q", fromCfg.DockerComposeFile, toCfg.DockerComposeFile)
+		}
+		if notBuilt := lo.Without(toCfg.Runs, fromCfg.Runs...); len(notBuilt) > 0 {
+			sort.Strings(notBuilt)
+			return errors.Errorf("services are not deployed in source environment: [%s]", strings.Join(notBuilt, ", "))
+		}
+	case *StackConfigSingleImage:
+		toCfg, ok := to.Config.Config.(*StackConfigSingleImage)
+		if !ok {
+			return errors.Errorf("unexpected config type %T", to.Config.Config)
+		}
+		if !reflect.DeepEqual(fromCfg.Image, toCfg.Image) {
+			return errors.Errorf("image configuration differs")
+		}
+	default:
+		return errors.Errorf("stacks of type %q do not support promotion", from.Type)
+	}
+	return nil
+}
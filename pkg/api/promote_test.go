@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPromotedImagesFromOutputs(t *testing.T) {
+	RegisterTestingT(t)
+
+	digest := "123.dkr.ecr.aws/billing/api@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	images, err := PromotedImagesFromOutputs(map[string]any{
+		ImageDigestOutputName("billing/api"): digest,
+		DeployVersionOutput:                  "1.2.3",
+		"billing-staging-outcome":            "success",
+	})
+	Expect(err).To(BeNil())
+	Expect(images).To(Equal(map[string]string{"billing/api": digest}))
+
+	_, err = PromotedImagesFromOutputs(map[string]any{
+		ImageDigestOutputName("billing/api"): "",
+	})
+	Expect(err).To(HaveOccurred())
+}
+
+func TestCheckPromotable(t *testing.T) {
+	RegisterTestingT(t)
+
+	compose := func(file string, runs ...string) StackClientDescriptor {
+		return StackClientDescriptor{
+			Type: ClientTypeCloudCompose,
+			Config: Config{Config: &StackConfigCompose{
+				DockerComposeFile: file,
+				Runs:              runs,
+				Env:               map[string]string{"ENV": file},
+			}},
+		}
+	}
+
+	t.Run("same compose file and services", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(CheckPromotable(compose("docker-compose.yaml", "api", "worker"), compose("docker-compose.yaml", "api"))).To(Succeed())
+	})
+
+	t.Run("different compose file", func(t *testing.T) {
+		RegisterTestingT(t)
+		err := CheckPromotable(compose("docker-compose.yaml", "api"), compose("docker-compose.prod.yaml", "api"))
+		Expect(err).To(MatchError(ContainSubstring("dockerComposeFile differs")))
+	})
+
+	t.Run("services not deployed in source", func(t *testing.T) {
+		RegisterTestingT(t)
+		err := CheckPromotable(compose("docker-compose.yaml", "api"), compose("docker-compose.yaml", "api", "worker"))
+		Expect(err).To(MatchError(ContainSubstring("[worker]")))
+	})
+
+	t.Run("single image", func(t *testing.T) {
+		RegisterTestingT(t)
+		single := func(dockerfile string) StackClientDescriptor {
+			return StackClientDescriptor{
+				Type:   ClientTypeSingleImage,
+				Config: Config{Config: &StackConfigSingleImage{Image: &ContainerImage{Dockerfile: dockerfile}}},
+			}
+		}
+		Expect(CheckPromotable(single("Dockerfile"), single("Dockerfile"))).To(Succeed())
+		Expect(CheckPromotable(single("Dockerfile"), single("prod.Dockerfile"))).To(MatchError(ContainSubstring("image configuration differs")))
+	})
+
+	t.Run("static is not supported", func(t *testing.T) {
+		RegisterTestingT(t)
+		static := StackClientDescriptor{Type: ClientTypeStatic, Config: Config{Config: &StackConfigStatic{}}}
+		Expect(CheckPromotable(static, static)).To(HaveOccurred())
+	})
+}
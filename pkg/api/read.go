@@ -143,6 +143,12 @@ func ReadServerConfigs(descriptor *ServerDescriptor) (*ServerDescriptor, error)
 		res = *withSecrets
 	}
 
+	if withSecretSources, err := DetectSecretSourcesType(&res); err != nil {
+		return nil, err
+	} else {
+		res = *withSecretSources
+	}
+
 	if withTemplates, err := DetectTemplatesType(&res); err != nil {
 		return nil, err
 	} else {
@@ -305,6 +311,21 @@ func DetectSecretsType(descriptor *ServerDescriptor) (*ServerDescriptor, error)
 	return descriptor, nil
 }
 
+func DetectSecretSourcesType(descriptor *ServerDescriptor) (*ServerDescriptor, error) {
+	for name, source := range descriptor.SecretSources {
+		if fn, found := providerConfigMapping[source.Type]; !found {
+			return nil, errors.Errorf("unknown type %q of secret source %q", source.Type, name)
+		} else {
+			var err error
+			if source.Config, err = fn(&source.Config); err != nil {
+				return descriptor, err
+			}
+		}
+		descriptor.SecretSources[name] = source
+	}
+	return descriptor, nil
+}
+
 func DetectProvisionerType(descriptor *ServerDescriptor) (*ServerDescriptor, error) {
 	if descriptor.Provisioner.IsInherited() {
 		return descriptor, nil
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DeploySnapshotOutput is the stack output holding DeployRevision of the latest update of the stack
+	DeploySnapshotOutput = "sc-deploy-snapshot"
+	// DeployRevisionsOutput is the stack output holding DeployRevision of previous updates of the stack
+	DeployRevisionsOutput = "sc-deploy-revisions"
+	// MaxDeployRevisions is the number of previous revisions kept in stack outputs
+	MaxDeployRevisions = 10
+)
+
+// RollbackParams describes rollback of a stack to one of its previous revisions
+type RollbackParams struct {
+	DeployParams `json:",inline" yaml:",inline"`
+	// ToUpdate is the version of the stack update (as in stack history) to roll back to,
+	// previous revision is used when not specified
+	ToUpdate int `json:"toUpdate" yaml:"toUpdate"`
+}
+
+// DeployRevision is a snapshot of everything needed to replay a deploy of the stack
+type DeployRevision struct {
+	UpdateVersion int               `json:"updateVersion" yaml:"updateVersion"` // version of the stack update in stack history
+	Version       string            `json:"version" yaml:"version"`             // deploy version
+	ClientType    string            `json:"clientType" yaml:"clientType"`
+	Images        map[string]string `json:"images,omitempty" yaml:"images,omitempty"` // image name -> immutable image reference
+	Descriptor    string            `json:"descriptor" yaml:"descriptor"`             // resolved stack descriptor in yaml
+}
+
+// StackHistoryEntry describes a single update of the stack
+type StackHistoryEntry struct {
+	UpdateVersion int             `json:"updateVersion" yaml:"updateVersion"`
+	Kind          string          `json:"kind" yaml:"kind"`
+	Result        string          `json:"result" yaml:"result"`
+	StartTime     string          `json:"startTime" yaml:"startTime"`
+	Revision      *DeployRevision `json:"revision,omitempty" yaml:"revision,omitempty"` // nil when update cannot be replayed
+}
+
+// NewDeployRevision captures resolved stack descriptor to be replayed later
+func NewDeployRevision(updateVersion int, params StackParams, clientType string, desc StackDescriptor) (*DeployRevision, error) {
+	descYaml, err := yaml.Marshal(desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal stack descriptor")
+	}
+	return &DeployRevision{
+		UpdateVersion: updateVersion,
+		Version:       params.Version,
+		ClientType:    clientType,
+		Descriptor:    string(descYaml),
+	}, nil
+}
+
+// StackDescriptor returns stack descriptor captured with the revision
+func (r *DeployRevision) StackDescriptor() (*StackDescriptor, error) {
+	var desc StackDescriptor
+	if err := yaml.Unmarshal([]byte(r.Descriptor), &desc); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal stack descriptor of revision %d", r.UpdateVersion)
+	}
+	converted, err := ReadDeployInput(&desc.Config, desc.Type)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read stack descriptor of revision %d", r.UpdateVersion)
+	}
+	desc.Config = converted
+	return &desc, nil
+}
+
+// DeployRevisionsFromOutputs returns revisions stored in stack outputs sorted from the newest to the oldest
+func DeployRevisionsFromOutputs(outputs map[string]any) ([]DeployRevision, error) {
+	var res []DeployRevision
+	if prev, _ := outputs[DeployRevisionsOutput].(string); prev != "" {
+		if err := json.Unmarshal([]byte(prev), &res); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal %q output", DeployRevisionsOutput)
+		}
+	}
+	if latest, _ := outputs[DeploySnapshotOutput].(string); latest != "" {
+		var revision DeployRevision
+		if err := json.Unmarshal([]byte(latest), &revision); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal %q output", DeploySnapshotOutput)
+		}
+		images, err := PromotedImagesFromOutputs(outputs)
+		if err != nil {
+			return nil, err
+		}
+		revision.Images = images
+		res = append(res, revision)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].UpdateVersion > res[j].UpdateVersion
+	})
+	return res, nil
+}
+
+// SelectRollbackRevision returns revision to roll back to: the one of update toUpdate if specified,
+// otherwise the revision preceding the latest one
+func SelectRollbackRevision(history []StackHistoryEntry, toUpdate int) (*DeployRevision, error) {
+	var revisions []DeployRevision
+	for _, entry := range history {
+		if entry.Revision == nil {
+			continue
+		}
+		if toUpdate > 0 && entry.UpdateVersion == toUpdate {
+			return entry.Revision, nil
+		}
+		revisions = append(revisions, *entry.Revision)
+	}
+	if toUpdate > 0 {
+		return nil, errors.Errorf("update %d is not found in stack history or cannot be replayed", toUpdate)
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].UpdateVersion > revisions[j].UpdateVersion
+	})
+	if len(revisions) < 2 {
+		return nil, errors.Errorf("no previous revision to roll back to")
+	}
+	return &revisions[1], nil
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type testDeployInput struct {
+	Image string            `json:"image" yaml:"image"`
+	Env   map[string]string `json:"env" yaml:"env"`
+}
+
+func TestDeployRevision_StackDescriptor(t *testing.T) {
+	RegisterTestingT(t)
+
+	RegisterDeployInputReader(ConfigRegisterMap{
+		"test-deploy-input": func(config *Config) (Config, error) {
+			return ConvertConfig(config, &testDeployInput{})
+		},
+	})
+
+	revision, err := NewDeployRevision(3, StackParams{Version: "1.0.1"}, ClientTypeCloudCompose, StackDescriptor{
+		Type:        "test-deploy-input",
+		ParentStack: "infra",
+		Config:      Config{Config: &testDeployInput{Image: "app", Env: map[string]string{"A": "${resource:db.host}"}}},
+	})
+	Expect(err).To(BeNil())
+	Expect(revision.UpdateVersion).To(Equal(3))
+	Expect(revision.Version).To(Equal("1.0.1"))
+
+	desc, err := revision.StackDescriptor()
+	Expect(err).To(BeNil())
+	Expect(desc.Type).To(Equal("test-deploy-input"))
+	Expect(desc.ParentStack).To(Equal("infra"))
+	Expect(desc.Config.Config).To(Equal(&testDeployInput{Image: "app", Env: map[string]string{"A": "${resource:db.host}"}}))
+
+	revision.Descriptor = "type: unknown-input\nconfig: {}\n"
+	_, err = revision.StackDescriptor()
+	Expect(err).To(MatchError(ContainSubstring("unsupported deploy input type")))
+}
+
+func TestDeployRevisionsFromOutputs(t *testing.T) {
+	RegisterTestingT(t)
+
+	digest := "registry/app@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	previous, _ := json.Marshal([]DeployRevision{{UpdateVersion: 2, Version: "1"}, {UpdateVersion: 5, Version: "2"}})
+	latest, _ := json.Marshal(DeployRevision{UpdateVersion: 8, Version: "3"})
+
+	revisions, err := DeployRevisionsFromOutputs(map[string]any{
+		DeployRevisionsOutput:         string(previous),
+		DeploySnapshotOutput:          string(latest),
+		ImageDigestOutputName("app"):  digest,
+		"billing-production-outcome":  "success",
+		DeployVersionOutput:           "3",
+		"some-unrelated-stack-output": "",
+	})
+	Expect(err).To(BeNil())
+	Expect(revisions).To(HaveLen(3))
+	Expect(revisions[0].UpdateVersion).To(Equal(8))
+	Expect(revisions[0].Images).To(Equal(map[string]string{"app": digest}))
+	Expect(revisions[1].UpdateVersion).To(Equal(5))
+	Expect(revisions[2].UpdateVersion).To(Equal(2))
+
+	revisions, err = DeployRevisionsFromOutputs(map[string]any{})
+	Expect(err).To(BeNil())
+	Expect(revisions).To(BeEmpty())
+}
+
+func TestSelectRollbackRevision(t *testing.T) {
+	RegisterTestingT(t)
+
+	history := []StackHistoryEntry{
+		{UpdateVersion: 9, Kind: "update", Result: "succeeded", Revision: &DeployRevision{UpdateVersion: 9, Version: "3"}},
+		{UpdateVersion: 8, Kind: "refresh", Result: "succeeded"},
+		{UpdateVersion: 7, Kind: "update", Result: "failed"},
+		{UpdateVersion: 6, Kind: "update", Result: "succeeded", Revision: &DeployRevision{UpdateVersion: 6, Version: "2"}},
+		{UpdateVersion: 3, Kind: "update", Result: "succeeded", Revision: &DeployRevision{UpdateVersion: 3, Version: "1"}},
+	}
+
+	revision, err := SelectRollbackRevision(history, 0)
+	Expect(err).To(BeNil())
+	Expect(revision.Version).To(Equal("2"))
+
+	revision, err = SelectRollbackRevision(history, 3)
+	Expect(err).To(BeNil())
+	Expect(revision.Version).To(Equal("1"))
+
+	_, err = SelectRollbackRevision(history, 7)
+	Expect(err).To(HaveOccurred())
+
+	_, err = SelectRollbackRevision(history[:1], 0)
+	Expect(err).To(MatchError(ContainSubstring("no previous revision")))
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// SecretSourceDescriptor declares external store ${secret:<source>://<path>#<key>} placeholders are read from, e.g.
+//
+//	secretSources:
+//	  vault:
+//	    type: vault
+//	    config:
+//	      address: https://vault.example.com
+//	      token: ${env:VAULT_TOKEN}
+type SecretSourceDescriptor struct {
+	Type   string `json:"type" yaml:"type"`
+	Config `json:",inline" yaml:",inline"`
+	// Strict fails deploy when a secret cannot be read from the source instead of leaving the placeholder unresolved
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+}
+
+// SecretSource reads secrets stored outside of the repository
+type SecretSource interface {
+	// ReadSecret returns the secret stored at path, secrets consisting of several fields are returned as JSON objects
+	ReadSecret(ctx context.Context, path string) (string, error)
+}
+
+type (
+	SecretSourceInitFunc     func(config Config) (SecretSource, error)
+	SecretSourcesRegisterMap map[string]SecretSourceInitFunc
+)
+
+var (
+	secretSourcesLock    sync.RWMutex
+	secretSourcesMapping = SecretSourcesRegisterMap{}
+)
+
+func RegisterSecretSources(mapping SecretSourcesRegisterMap) {
+	secretSourcesLock.Lock()
+	defer secretSourcesLock.Unlock()
+	for sourceType, initFunc := range mapping {
+		secretSourcesMapping[sourceType] = initFunc
+	}
+}
+
+// NewSecretSource returns reader of the secrets store of the type
+func NewSecretSource(sourceType string, config Config) (SecretSource, error) {
+	secretSourcesLock.RLock()
+	initFunc, found := secretSourcesMapping[sourceType]
+	secretSourcesLock.RUnlock()
+	if !found {
+		return nil, errors.Errorf("secrets of type %q cannot be read as secret source", sourceType)
+	}
+	return initFunc(config)
+}
+
+// SecretRef is a reference to external secret, e.g. vault://kv/data/payments#stripe_key
+type SecretRef struct {
+	Source string
+	Path   string
+	Key    string
+}
+
+// ParseSecretRef parses reference to external secret, returns false if ref is a name of the repository secret
+func ParseSecretRef(ref string) (SecretRef, bool) {
+	source, rest, found := strings.Cut(ref, "://")
+	if !found || source == "" {
+		return SecretRef{}, false
+	}
+	path, key, _ := strings.Cut(rest, "#")
+	return SecretRef{Source: source, Path: path, Key: key}, true
+}
+
+func (r SecretRef) String() string {
+	if r.Key == "" {
+		return r.Source + "://" + r.Path
+	}
+	return r.Source + "://" + r.Path + "#" + r.Key
+}
+
+// Value returns the field of secret selected by the key of reference, or the whole secret if the key is empty
+func (r SecretRef) Value(secret string) (string, error) {
+	if r.Key == "" {
+		return secret, nil
+	}
+	ref := SecretRef{Source: r.Source, Path: r.Path}.String()
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", errors.Errorf("secret %q is not a JSON object, field %q cannot be selected", ref, r.Key)
+	}
+	value, found := fields[r.Key]
+	if !found {
+		return "", errors.Errorf("field %q not found in secret %q", r.Key, ref)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	res, err := json.Marshal(value)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal field %q of secret %q", r.Key, ref)
+	}
+	return string(res), nil
+}
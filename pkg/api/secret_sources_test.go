@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	RegisterTestingT(t)
+
+	ref, external := ParseSecretRef("vault://kv/data/payments#stripe_key")
+	Expect(external).To(BeTrue())
+	Expect(ref).To(Equal(SecretRef{Source: "vault", Path: "kv/data/payments", Key: "stripe_key"}))
+	Expect(ref.String()).To(Equal("vault://kv/data/payments#stripe_key"))
+
+	ref, external = ParseSecretRef("aws-secrets-manager://prod/db")
+	Expect(external).To(BeTrue())
+	Expect(ref).To(Equal(SecretRef{Source: "aws-secrets-manager", Path: "prod/db"}))
+	Expect(ref.String()).To(Equal("aws-secrets-manager://prod/db"))
+
+	for _, name := range []string{"DB_PASSWORD", "://path", "values.key"} {
+		_, external = ParseSecretRef(name)
+		Expect(external).To(BeFalse(), name)
+	}
+}
+
+func TestSecretRef_Value(t *testing.T) {
+	RegisterTestingT(t)
+
+	secret := `{"user":"app","port":5432,"tls":{"enabled":true}}`
+
+	value, err := SecretRef{Source: "vault", Path: "db"}.Value(secret)
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal(secret))
+
+	value, err = SecretRef{Source: "vault", Path: "db", Key: "user"}.Value(secret)
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal("app"))
+
+	value, err = SecretRef{Source: "vault", Path: "db", Key: "port"}.Value(secret)
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal("5432"))
+
+	value, err = SecretRef{Source: "vault", Path: "db", Key: "tls"}.Value(secret)
+	Expect(err).To(BeNil())
+	Expect(value).To(MatchJSON(`{"enabled":true}`))
+
+	_, err = SecretRef{Source: "vault", Path: "db", Key: "password"}.Value(secret)
+	Expect(err).To(MatchError(`field "password" not found in secret "vault://db"`))
+
+	_, err = SecretRef{Source: "vault", Path: "token", Key: "value"}.Value("plain")
+	Expect(err).To(MatchError(`secret "vault://token" is not a JSON object, field "value" cannot be selected`))
+}
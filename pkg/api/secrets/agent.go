@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/simple-container-com/api/pkg/api/secrets/ciphers"
+)
+
+// agentKeyChallenge is signed by the key of the agent, the signature is the seed of the derived key pair,
+// so it must never change
+const agentKeyChallenge = "simple-container.com/secrets/agent-key/v1"
+
+// AgentKey is a key held in ssh-agent
+type AgentKey struct {
+	Fingerprint string
+	Type        string
+	Comment     string
+}
+
+// ListAgentKeys returns keys of ssh-agent listening on SSH_AUTH_SOCK
+func ListAgentKeys() ([]AgentKey, error) {
+	client, closeFunc, err := dialAgent()
+	if err != nil {
+		return nil, err
+	}
+	defer closeFunc()
+	keys, err := client.List()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list keys of ssh-agent")
+	}
+	res := make([]AgentKey, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, AgentKey{Fingerprint: ssh.FingerprintSHA256(key), Type: key.Type(), Comment: key.Comment})
+	}
+	return res, nil
+}
+
+// AgentKeySigner returns signer of the ssh-agent key with SHA256 fingerprint (as printed by `ssh-add -l`),
+// the connection to the agent is kept open for the signer
+func AgentKeySigner(fingerprint string) (ssh.Signer, error) {
+	client, closeFunc, err := dialAgent()
+	if err != nil {
+		return nil, err
+	}
+	signers, err := client.Signers()
+	if err != nil {
+		closeFunc()
+		return nil, errors.Wrapf(err, "failed to list keys of ssh-agent")
+	}
+	fingerprint = "SHA256:" + strings.TrimPrefix(fingerprint, "SHA256:")
+	for _, signer := range signers {
+		if ssh.FingerprintSHA256(signer.PublicKey()) == fingerprint {
+			return signer, nil
+		}
+	}
+	closeFunc()
+	return nil, errors.Errorf("key %q not found in ssh-agent, add it with `ssh-add`", fingerprint)
+}
+
+// DeriveSignerKeyPair derives ssh-ed25519 key pair of secrets from the signature of a fixed challenge,
+// the private key is returned as PEM and the public key in authorized_keys format.
+// Only ssh-ed25519 and ssh-rsa keys are supported, as signatures of other key types are not deterministic
+func DeriveSignerKeyPair(signer ssh.Signer) (string, string, error) {
+	pub := signer.PublicKey()
+	var sig *ssh.Signature
+	var err error
+	switch pub.Type() {
+	case ssh.KeyAlgoED25519:
+		sig, err = signer.Sign(rand.Reader, []byte(agentKeyChallenge))
+	case ssh.KeyAlgoRSA:
+		algSigner, ok := signer.(ssh.AlgorithmSigner)
+		if !ok {
+			return "", "", errors.Errorf("signer of %q key does not support %q signatures", pub.Type(), ssh.KeyAlgoRSASHA256)
+		}
+		sig, err = algSigner.SignWithAlgorithm(rand.Reader, []byte(agentKeyChallenge), ssh.KeyAlgoRSASHA256)
+	default:
+		return "", "", errors.Errorf("signatures of %q keys are not deterministic, use ssh-ed25519 or ssh-rsa key", pub.Type())
+	}
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to sign challenge with %s key %s", pub.Type(), ssh.FingerprintSHA256(pub))
+	}
+	if err := pub.Verify([]byte(agentKeyChallenge), sig); err != nil {
+		return "", "", errors.Wrapf(err, "signature of %s key %s is invalid", pub.Type(), ssh.FingerprintSHA256(pub))
+	}
+
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sig.Blob, pub.Marshal(), []byte(agentKeyChallenge)), seed); err != nil {
+		return "", "", errors.Wrapf(err, "failed to derive key")
+	}
+	privKey := ed25519.NewKeyFromSeed(seed)
+	privKeyPem, err := ciphers.MarshalEd25519PrivateKey(privKey)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to marshal derived private key")
+	}
+	pubKey, err := ciphers.MarshalEd25519PublicKey(privKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to marshal derived public key")
+	}
+	return privKeyPem, TrimPubKey(string(pubKey)), nil
+}
+
+func dialAgent() (agent.ExtendedAgent, func(), error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, nil, errors.New("ssh-agent is not running: SSH_AUTH_SOCK is not set")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to connect to ssh-agent at %q", socket)
+	}
+	return agent.NewClient(conn), func() { _ = conn.Close() }, nil
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+// startTestAgent serves keyring with the keys on a unix socket set as SSH_AUTH_SOCK
+func startTestAgent(t *testing.T, keys ...any) {
+	t.Helper()
+	keyring := agent.NewKeyring()
+	for _, key := range keys {
+		Expect(keyring.Add(agent.AddedKey{PrivateKey: key, Comment: "test"})).To(Succeed())
+	}
+	socket := path.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	Expect(err).To(BeNil())
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+}
+
+func fingerprintOf(t *testing.T, key any) string {
+	t.Helper()
+	signer, err := ssh.NewSignerFromKey(key)
+	Expect(err).To(BeNil())
+	return ssh.FingerprintSHA256(signer.PublicKey())
+}
+
+func TestDeriveSignerKeyPair(t *testing.T) {
+	RegisterTestingT(t)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).To(BeNil())
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).To(BeNil())
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(BeNil())
+	startTestAgent(t, edKey, rsaKey, ecKey)
+
+	keys, err := ListAgentKeys()
+	Expect(err).To(BeNil())
+	Expect(keys).To(HaveLen(3))
+
+	for _, key := range []any{edKey, rsaKey} {
+		fingerprint := fingerprintOf(t, key)
+		t.Run(fingerprint, func(t *testing.T) {
+			RegisterTestingT(t)
+			signer, err := AgentKeySigner(fingerprint)
+			Expect(err).To(BeNil())
+			privKey, pubKey, err := DeriveSignerKeyPair(signer)
+			Expect(err).To(BeNil())
+			Expect(pubKey).To(HavePrefix("ssh-ed25519 "))
+
+			// same key pair is derived from the same agent key, the prefix of fingerprint is optional
+			signer, err = AgentKeySigner(fingerprint[len("SHA256:"):])
+			Expect(err).To(BeNil())
+			privKeyAgain, pubKeyAgain, err := DeriveSignerKeyPair(signer)
+			Expect(err).To(BeNil())
+			Expect(privKeyAgain).To(Equal(privKey))
+			Expect(pubKeyAgain).To(Equal(pubKey))
+		})
+	}
+
+	t.Run("rejects keys with non-deterministic signatures", func(t *testing.T) {
+		RegisterTestingT(t)
+		signer, err := AgentKeySigner(fingerprintOf(t, ecKey))
+		Expect(err).To(BeNil())
+		_, _, err = DeriveSignerKeyPair(signer)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("not deterministic"))
+	})
+
+	t.Run("fails for unknown key", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := AgentKeySigner("SHA256:unknown")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("not found in ssh-agent"))
+	})
+}
+
+func TestWithSSHAgentKey(t *testing.T) {
+	RegisterTestingT(t)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).To(BeNil())
+	startTestAgent(t, edKey)
+	fingerprint := fingerprintOf(t, edKey)
+
+	c, wd, cleanup := newTestCryptor(t)
+	defer cleanup()
+	secretsFile := "stacks/common/secrets.yaml"
+	original, err := os.ReadFile(path.Join(wd, secretsFile))
+	Expect(err).To(BeNil())
+	Expect(c.AddFile(secretsFile)).To(Succeed())
+
+	signer, err := AgentKeySigner(fingerprint)
+	Expect(err).To(BeNil())
+	_, agentPubKey, err := DeriveSignerKeyPair(signer)
+	Expect(err).To(BeNil())
+	Expect(c.AddPublicKey(agentPubKey)).To(Succeed())
+	Expect(os.Remove(path.Join(wd, secretsFile))).To(Succeed())
+
+	t.Run("decrypts secrets with key derived from agent key", func(t *testing.T) {
+		RegisterTestingT(t)
+		agentCryptor, err := NewCryptor(wd, withGitDir("gitdir"), WithSSHAgentKey(fingerprint))
+		Expect(err).To(BeNil())
+		Expect(agentCryptor.PublicKey()).To(Equal(agentPubKey))
+		Expect(agentCryptor.ReadSecretFiles()).To(Succeed())
+		Expect(agentCryptor.DecryptAll(false)).To(Succeed())
+
+		content, err := os.ReadFile(path.Join(wd, secretsFile))
+		Expect(err).To(BeNil())
+		Expect(content).To(Equal(original))
+	})
+
+	t.Run("reads fingerprint from profile config", func(t *testing.T) {
+		RegisterTestingT(t)
+		cfgFile := path.Join(wd, api.ScConfigDirectory, "cfg.agent-key.yaml")
+		Expect(os.WriteFile(cfgFile, []byte("privateKeyAgent: "+fingerprint+"\n"), 0o600)).To(Succeed())
+		agentCryptor, err := NewCryptor(wd, withGitDir("gitdir"), WithKeysFromScConfig("agent-key"))
+		Expect(err).To(BeNil())
+		Expect(agentCryptor.PublicKey()).To(Equal(agentPubKey))
+
+		_, err = agentCryptor.Rotate(RotateParams{KeyType: KeyTypeEd25519})
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("derived from ssh-agent key"))
+	})
+
+	t.Run("fails when configured public key does not match derived key", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := NewCryptor(wd, withGitDir("gitdir"), WithKeysFromScConfig("local-key-files"), WithSSHAgentKey(fingerprint))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("does not match"))
+	})
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+const (
+	// CipherSc chunks secrets with RSA-OAEP for RSA keys and seals them with X25519 for ed25519 keys, readable by sc only
+	CipherSc = "sc"
+	// CipherAge writes armored age files (https://age-encryption.org/v1) for ssh-rsa and ssh-ed25519 recipients,
+	// so secrets can also be decrypted with `age -d -i ~/.ssh/id_ed25519`
+	CipherAge = "age"
+)
+
+// AgeCipherSchemaVersion is the schema version of secrets.yaml encrypted with the age cipher
+const AgeCipherSchemaVersion = 2
+
+// CipherName returns the cipher secrets are encrypted with
+func (r Registry) CipherName() string {
+	if r.Cipher == "" {
+		return CipherSc
+	}
+	return r.Cipher
+}
+
+// SetCipher selects the cipher and re-encrypts all files the current key can decrypt with it,
+// files it cannot decrypt keep their ciphertexts, which stay readable as decryption detects the cipher
+func (c *cryptor) SetCipher(name string) error {
+	defer c.withWriteLock()()
+
+	if name != CipherSc && name != CipherAge {
+		return errors.Errorf("unsupported cipher %q, must be %q or %q", name, CipherSc, CipherAge)
+	}
+	if err := c.initData(); err != nil {
+		return err
+	}
+	contents, err := c.decryptRegisteredFiles()
+	if err != nil {
+		return err
+	}
+	c.secrets.Registry.Cipher = ""
+	if name != CipherSc {
+		c.secrets.Registry.Cipher = name
+	}
+
+	acceptedChanges := make(map[string]bool)
+	for _, relFilePath := range c.secrets.Registry.Files {
+		content, decrypted := contents[relFilePath]
+		if !decrypted {
+			c.consoleWriter.Println(color.YellowFmt("secret file %q cannot be decrypted with the current key and keeps its cipher, "+
+				"run `sc secrets cipher` with a key which can decrypt it", relFilePath))
+			continue
+		}
+		// drop ciphertexts of all keys so that unchanged content is re-encrypted too
+		for key, keySecrets := range c.secrets.Secrets {
+			keySecrets.RemoveFile(EncryptedSecretFile{Path: relFilePath})
+			c.secrets.Secrets[key] = keySecrets
+		}
+		if err := c.encryptChangedData(relFilePath, content, true, true, acceptedChanges); err != nil {
+			return errors.Wrapf(err, "failed to re-encrypt secret file %q with cipher %q", relFilePath, name)
+		}
+	}
+	return c.MarshalSecretsFile()
+}
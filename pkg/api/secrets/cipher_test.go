@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/secrets/ciphers"
+)
+
+func TestSetCipher(t *testing.T) {
+	RegisterTestingT(t)
+	c, wd, cleanup := newTestCryptor(t)
+	defer cleanup()
+
+	edPriv, edPub, err := ciphers.GenerateEd25519KeyPair()
+	Expect(err).To(BeNil())
+	edPubSSH, err := ciphers.MarshalEd25519PublicKey(edPub)
+	Expect(err).To(BeNil())
+	edKey := strings.TrimSpace(string(edPubSSH))
+
+	original, err := os.ReadFile(path.Join(wd, "stacks/common/secrets.yaml"))
+	Expect(err).To(BeNil())
+	Expect(c.AddFile("stacks/common/secrets.yaml")).To(Succeed())
+	Expect(c.AddPublicKey(edKey)).To(Succeed())
+
+	storePath := path.Join(wd, api.ScConfigDirectory, EncryptedSecretFilesDataFileName)
+
+	t.Run("rejects unknown cipher", func(t *testing.T) {
+		RegisterTestingT(t)
+		err := c.SetCipher("sops")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("unsupported cipher"))
+		Expect(c.GetSecretFiles().Registry.CipherName()).To(Equal(CipherSc))
+	})
+
+	t.Run("re-encrypts secrets as age files", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(c.SetCipher(CipherAge)).To(Succeed())
+
+		files := c.GetSecretFiles()
+		Expect(files.Registry.CipherName()).To(Equal(CipherAge))
+		for _, key := range []string{TrimPubKey(c.PublicKey()), edKey} {
+			keySecrets := files.Secrets[key]
+			Expect(ciphers.IsAgeEncrypted(keySecrets.GetEncryptedContent("stacks/common/secrets.yaml"))).To(BeTrue())
+		}
+
+		store, err := os.ReadFile(storePath)
+		Expect(err).To(BeNil())
+		Expect(string(store)).To(ContainSubstring("schemaVersion: 2"))
+		Expect(string(store)).To(ContainSubstring("cipher: age"))
+
+		content, err := c.GetAndDecryptFileContent("stacks/common/secrets.yaml")
+		Expect(err).To(BeNil())
+		Expect(content).To(Equal(original))
+
+		// other recipients can decrypt their copies with plain age tooling
+		edSecrets := files.Secrets[edKey]
+		content, err = ciphers.DecryptAge(edPriv, edSecrets.GetEncryptedContent("stacks/common/secrets.yaml")[0])
+		Expect(err).To(BeNil())
+		Expect(content).To(Equal(original))
+	})
+
+	t.Run("switching back makes store readable by older builds", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(c.SetCipher(CipherSc)).To(Succeed())
+
+		files := c.GetSecretFiles()
+		Expect(files.Registry.Cipher).To(BeEmpty())
+		currentSecrets := files.Secrets[TrimPubKey(c.PublicKey())]
+		Expect(ciphers.IsAgeEncrypted(currentSecrets.GetEncryptedContent("stacks/common/secrets.yaml"))).To(BeFalse())
+
+		store, err := os.ReadFile(storePath)
+		Expect(err).To(BeNil())
+		Expect(string(store)).NotTo(ContainSubstring("schemaVersion"))
+		Expect(string(store)).NotTo(ContainSubstring("AGE ENCRYPTED FILE"))
+
+		content, err := c.GetAndDecryptFileContent("stacks/common/secrets.yaml")
+		Expect(err).To(BeNil())
+		Expect(content).To(Equal(original))
+	})
+}
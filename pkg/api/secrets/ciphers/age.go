@@ -0,0 +1,452 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package ciphers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/ssh"
+)
+
+// The age v1 format (https://age-encryption.org/v1) with the ssh-ed25519 and ssh-rsa recipient types
+// of age's agessh package, so the same SSH keys sc uses can decrypt secrets with the `age` CLI:
+//
+//	age -d -i ~/.ssh/id_ed25519 < secret.age
+//
+// Ciphertexts are ASCII armored to be stored in secrets.yaml.
+const (
+	ageIntro      = "age-encryption.org/v1"
+	ageArmorBegin = "-----BEGIN AGE ENCRYPTED FILE-----"
+	ageArmorEnd   = "-----END AGE ENCRYPTED FILE-----"
+
+	ageEd25519Label = "age-encryption.org/v1/ssh-ed25519"
+	ageRSALabel     = "age-encryption.org/v1/ssh-rsa"
+
+	ageFileKeySize    = 16
+	ageNonceSize      = 16
+	ageChunkSize      = 64 * 1024
+	ageColumnsPerLine = 64
+)
+
+var ageB64 = base64.RawStdEncoding.Strict()
+
+type ageStanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+// IsAgeEncrypted reports whether the encrypted data of a secret file is an armored age file
+func IsAgeEncrypted(chunks []string) bool {
+	return len(chunks) == 1 && strings.HasPrefix(strings.TrimSpace(chunks[0]), ageArmorBegin)
+}
+
+// EncryptAge encrypts plaintext for an ssh-ed25519 or ssh-rsa public key into an armored age file
+func EncryptAge(key crypto.PublicKey, plaintext []byte) (string, error) {
+	sshKey, err := ssh.NewPublicKey(key)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to convert public key to ssh public key")
+	}
+	fileKey := make([]byte, ageFileKeySize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return "", errors.Wrap(err, "failed to generate file key")
+	}
+
+	var stanza *ageStanza
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		stanza, err = ageWrapEd25519(sshKey, k, fileKey)
+	case *rsa.PublicKey:
+		stanza, err = ageWrapRSA(sshKey, k, fileKey)
+	default:
+		return "", errors.Errorf("unsupported key type for age encryption: %T", key)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var header bytes.Buffer
+	header.WriteString(ageIntro + "\n")
+	writeAgeStanza(&header, stanza)
+	header.WriteString("---")
+	mac, err := ageHeaderMAC(fileKey, header.Bytes())
+	if err != nil {
+		return "", err
+	}
+	header.WriteString(" " + ageB64.EncodeToString(mac) + "\n")
+
+	nonce := make([]byte, ageNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "failed to generate payload nonce")
+	}
+	payload, err := ageSealPayload(fileKey, nonce, plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	file := append(header.Bytes(), nonce...)
+	file = append(file, payload...)
+	return armorAge(file), nil
+}
+
+// DecryptAge decrypts an armored age file with an RSA or ed25519 private key
+func DecryptAge(key crypto.PrivateKey, armored string) ([]byte, error) {
+	file, err := dearmorAge(armored)
+	if err != nil {
+		return nil, err
+	}
+	stanzas, header, rest, err := parseAgeHeader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileKey []byte
+	for _, stanza := range stanzas {
+		if fileKey, err = ageUnwrap(key, stanza); err != nil {
+			return nil, err
+		} else if fileKey != nil {
+			break
+		}
+	}
+	if fileKey == nil {
+		return nil, errors.New("age file is not encrypted for the private key")
+	}
+
+	macLine, payload, _ := bytes.Cut(rest, []byte("\n"))
+	mac, err := ageB64.DecodeString(string(macLine))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode age header mac")
+	}
+	expectedMAC, err := ageHeaderMAC(fileKey, header)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, expectedMAC) {
+		return nil, errors.New("age header mac mismatch")
+	}
+	if len(payload) < ageNonceSize {
+		return nil, errors.New("age payload too short")
+	}
+	return ageOpenPayload(fileKey, payload[:ageNonceSize], payload[ageNonceSize:])
+}
+
+func ageWrapEd25519(sshKey ssh.PublicKey, pub ed25519.PublicKey, fileKey []byte) (*ageStanza, error) {
+	theirPublicKey, err := ed25519PublicKeyToX25519(pub)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(ephemeral); err != nil {
+		return nil, errors.Wrap(err, "failed to generate ephemeral key")
+	}
+	ourPublicKey, err := curve25519.X25519(ephemeral, curve25519.Basepoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute ephemeral public key")
+	}
+	sharedSecret, err := curve25519.X25519(ephemeral, theirPublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "ephemeral ECDH failed")
+	}
+	wrappingKey, err := ageEd25519WrappingKey(sshKey, sharedSecret, ourPublicKey, theirPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ageAEADEncrypt(wrappingKey, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ageStanza{
+		Type: "ssh-ed25519",
+		Args: []string{ageSSHTag(sshKey), ageB64.EncodeToString(ourPublicKey)},
+		Body: body,
+	}, nil
+}
+
+func ageWrapRSA(sshKey ssh.PublicKey, pub *rsa.PublicKey, fileKey []byte) (*ageStanza, error) {
+	body, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, fileKey, []byte(ageRSALabel))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap file key")
+	}
+	return &ageStanza{Type: "ssh-rsa", Args: []string{ageSSHTag(sshKey)}, Body: body}, nil
+}
+
+// ageUnwrap returns nil file key if the stanza is not addressed to the private key
+func ageUnwrap(key crypto.PrivateKey, stanza ageStanza) ([]byte, error) {
+	if k, ok := key.(*ed25519.PrivateKey); ok {
+		key = *k
+	}
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return nil, errors.Errorf("invalid ed25519 private key size: %d", len(k))
+		}
+		sshKey, err := ssh.NewPublicKey(k.Public())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert public key to ssh public key")
+		}
+		if stanza.Type != "ssh-ed25519" || len(stanza.Args) != 2 || stanza.Args[0] != ageSSHTag(sshKey) {
+			return nil, nil
+		}
+		ephemeralShare, err := ageB64.DecodeString(stanza.Args[1])
+		if err != nil || len(ephemeralShare) != curve25519.PointSize {
+			return nil, errors.New("invalid ssh-ed25519 stanza")
+		}
+		h := sha512.Sum512(k.Seed())
+		secretKey := h[:curve25519.ScalarSize]
+		ourPublicKey, err := ed25519PublicKeyToX25519(k.Public().(ed25519.PublicKey))
+		if err != nil {
+			return nil, err
+		}
+		sharedSecret, err := curve25519.X25519(secretKey, ephemeralShare)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ssh-ed25519 stanza")
+		}
+		wrappingKey, err := ageEd25519WrappingKey(sshKey, sharedSecret, ephemeralShare, ourPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		return ageAEADDecrypt(wrappingKey, stanza.Body)
+	case *rsa.PrivateKey:
+		sshKey, err := ssh.NewPublicKey(&k.PublicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert public key to ssh public key")
+		}
+		if stanza.Type != "ssh-rsa" || len(stanza.Args) != 1 || stanza.Args[0] != ageSSHTag(sshKey) {
+			return nil, nil
+		}
+		fileKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, k, stanza.Body, []byte(ageRSALabel))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to unwrap file key")
+		}
+		return fileKey, nil
+	default:
+		return nil, errors.Errorf("unsupported private key type for age decryption: %T", key)
+	}
+}
+
+// ageEd25519WrappingKey tweaks the shared secret with the ssh key as agessh does,
+// so it cannot be reused with a native age X25519 recipient of the same key
+func ageEd25519WrappingKey(sshKey ssh.PublicKey, sharedSecret, ephemeralShare, recipientPublicKey []byte) ([]byte, error) {
+	tweak := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, nil, sshKey.Marshal(), []byte(ageEd25519Label)), tweak); err != nil {
+		return nil, errors.Wrap(err, "failed to derive tweak")
+	}
+	sharedSecret, err := curve25519.X25519(tweak, sharedSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to tweak shared secret")
+	}
+	salt := append(append([]byte{}, ephemeralShare...), recipientPublicKey...)
+	wrappingKey := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, salt, []byte(ageEd25519Label)), wrappingKey); err != nil {
+		return nil, errors.Wrap(err, "failed to derive wrapping key")
+	}
+	return wrappingKey, nil
+}
+
+func ageSSHTag(sshKey ssh.PublicKey) string {
+	h := sha256.Sum256(sshKey.Marshal())
+	return ageB64.EncodeToString(h[:4])
+}
+
+func ageAEADEncrypt(key, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create AEAD")
+	}
+	return aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), plaintext, nil), nil
+}
+
+func ageAEADDecrypt(key, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) != ageFileKeySize+chacha20poly1305.Overhead {
+		return nil, errors.New("invalid wrapped file key size")
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create AEAD")
+	}
+	fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap file key")
+	}
+	return fileKey, nil
+}
+
+func ageHeaderMAC(fileKey, header []byte) ([]byte, error) {
+	hmacKey := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, fileKey, nil, []byte("header")), hmacKey); err != nil {
+		return nil, errors.Wrap(err, "failed to derive header mac key")
+	}
+	h := hmac.New(sha256.New, hmacKey)
+	h.Write(header)
+	return h.Sum(nil), nil
+}
+
+func ageStreamKey(fileKey, nonce []byte) ([]byte, error) {
+	streamKey := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, fileKey, nonce, []byte("payload")), streamKey); err != nil {
+		return nil, errors.Wrap(err, "failed to derive payload key")
+	}
+	return streamKey, nil
+}
+
+// ageChunkNonce is the STREAM nonce: 11 bytes big endian chunk counter and the last chunk flag
+func ageChunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func ageSealPayload(fileKey, nonce, plaintext []byte) ([]byte, error) {
+	streamKey, err := ageStreamKey(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(streamKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create AEAD")
+	}
+	var res []byte
+	for counter := uint64(0); ; counter++ {
+		chunk := plaintext[:min(len(plaintext), ageChunkSize)]
+		plaintext = plaintext[len(chunk):]
+		last := len(plaintext) == 0
+		res = aead.Seal(res, ageChunkNonce(counter, last), chunk, nil)
+		if last {
+			return res, nil
+		}
+	}
+}
+
+func ageOpenPayload(fileKey, nonce, payload []byte) ([]byte, error) {
+	streamKey, err := ageStreamKey(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(streamKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create AEAD")
+	}
+	var res []byte
+	for counter := uint64(0); ; counter++ {
+		chunk := payload[:min(len(payload), ageChunkSize+chacha20poly1305.Overhead)]
+		payload = payload[len(chunk):]
+		last := len(payload) == 0
+		if res, err = aead.Open(res, ageChunkNonce(counter, last), chunk, nil); err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt age payload")
+		}
+		if last {
+			return res, nil
+		}
+	}
+}
+
+func writeAgeStanza(w *bytes.Buffer, stanza *ageStanza) {
+	w.WriteString("-> " + strings.Join(append([]string{stanza.Type}, stanza.Args...), " ") + "\n")
+	body := ageB64.EncodeToString(stanza.Body)
+	for len(body) >= ageColumnsPerLine {
+		w.WriteString(body[:ageColumnsPerLine] + "\n")
+		body = body[ageColumnsPerLine:]
+	}
+	// the last line of the body is always shorter than a full line, even if empty
+	w.WriteString(body + "\n")
+}
+
+// parseAgeHeader returns recipient stanzas, the header covered by the mac (up to "---")
+// and the rest of the file starting with the encoded mac
+func parseAgeHeader(file []byte) ([]ageStanza, []byte, []byte, error) {
+	offset := 0
+	readLine := func() (string, error) {
+		end := bytes.IndexByte(file[offset:], '\n')
+		if end < 0 {
+			return "", errors.New("unexpected end of age header")
+		}
+		line := string(file[offset : offset+end])
+		offset += end + 1
+		return line, nil
+	}
+	if intro, err := readLine(); err != nil {
+		return nil, nil, nil, err
+	} else if intro != ageIntro {
+		return nil, nil, nil, errors.Errorf("unsupported age format %q", intro)
+	}
+
+	var stanzas []ageStanza
+	for {
+		line, err := readLine()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if strings.HasPrefix(line, "--- ") {
+			headerLen := offset - len(line) - 1 + len("---")
+			return stanzas, file[:headerLen], file[headerLen+1:], nil
+		}
+		if !strings.HasPrefix(line, "-> ") {
+			return nil, nil, nil, errors.Errorf("malformed age header line %q", line)
+		}
+		args := strings.Fields(strings.TrimPrefix(line, "-> "))
+		if len(args) == 0 {
+			return nil, nil, nil, errors.New("age stanza without type")
+		}
+		stanza := ageStanza{Type: args[0], Args: args[1:]}
+		for {
+			bodyLine, err := readLine()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			decoded, err := ageB64.DecodeString(bodyLine)
+			if err != nil || len(bodyLine) > ageColumnsPerLine {
+				return nil, nil, nil, errors.New("malformed age stanza body")
+			}
+			stanza.Body = append(stanza.Body, decoded...)
+			if len(bodyLine) < ageColumnsPerLine {
+				break
+			}
+		}
+		stanzas = append(stanzas, stanza)
+	}
+}
+
+func armorAge(file []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(file)
+	var sb strings.Builder
+	sb.WriteString(ageArmorBegin + "\n")
+	for len(encoded) > 0 {
+		line := encoded[:min(len(encoded), ageColumnsPerLine)]
+		encoded = encoded[len(line):]
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString(ageArmorEnd + "\n")
+	return sb.String()
+}
+
+func dearmorAge(armored string) ([]byte, error) {
+	armored = strings.TrimSpace(armored)
+	if !strings.HasPrefix(armored, ageArmorBegin) || !strings.HasSuffix(armored, ageArmorEnd) {
+		return nil, errors.New("not an armored age file")
+	}
+	encoded := strings.Join(strings.Fields(armored[len(ageArmorBegin):len(armored)-len(ageArmorEnd)]), "")
+	file, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode armored age file")
+	}
+	return file, nil
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package ciphers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestAge_RoundTrip(t *testing.T) {
+	RegisterTestingT(t)
+
+	edPriv, edPub, err := GenerateEd25519KeyPair()
+	Expect(err).ToNot(HaveOccurred())
+	rsaPriv, rsaPub, err := GenerateKeyPair(2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	for name, plaintext := range map[string][]byte{
+		"empty":         {},
+		"short":         []byte("DB_PASSWORD=hunter2\nAPI_KEY=abc"),
+		"single chunk":  bytes.Repeat([]byte{'a'}, ageChunkSize),
+		"several chunk": bytes.Repeat([]byte("0123456789"), ageChunkSize/5),
+	} {
+		t.Run("ed25519 "+name, func(t *testing.T) {
+			RegisterTestingT(t)
+			armored, err := EncryptAge(edPub, plaintext)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(IsAgeEncrypted([]string{armored})).To(BeTrue())
+
+			decrypted, err := DecryptAge(edPriv, armored)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decrypted).To(Equal(plaintext))
+
+			decrypted, err = DecryptAge(&edPriv, armored)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decrypted).To(Equal(plaintext))
+		})
+		t.Run("rsa "+name, func(t *testing.T) {
+			RegisterTestingT(t)
+			armored, err := EncryptAge(rsaPub, plaintext)
+			Expect(err).ToNot(HaveOccurred())
+
+			decrypted, err := DecryptAge(rsaPriv, armored)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decrypted).To(Equal(plaintext))
+		})
+	}
+}
+
+func TestAge_Format(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, edPub, err := GenerateEd25519KeyPair()
+	Expect(err).ToNot(HaveOccurred())
+	armored, err := EncryptAge(edPub, []byte("secret"))
+	Expect(err).ToNot(HaveOccurred())
+
+	lines := strings.Split(strings.TrimSpace(armored), "\n")
+	Expect(lines[0]).To(Equal("-----BEGIN AGE ENCRYPTED FILE-----"))
+	Expect(lines[len(lines)-1]).To(Equal("-----END AGE ENCRYPTED FILE-----"))
+	for _, line := range lines[1 : len(lines)-1] {
+		Expect(len(line)).To(BeNumerically("<=", 64))
+	}
+
+	file, err := dearmorAge(armored)
+	Expect(err).ToNot(HaveOccurred())
+	header := strings.Split(string(file), "\n")
+	Expect(header[0]).To(Equal("age-encryption.org/v1"))
+	Expect(header[1]).To(HavePrefix("-> ssh-ed25519 "))
+	Expect(strings.Fields(header[1])).To(HaveLen(4))
+	// 32 bytes of wrapped file key fit into a single body line
+	Expect(header[2]).To(HaveLen(43))
+	Expect(header[3]).To(HavePrefix("--- "))
+}
+
+func TestAge_Errors(t *testing.T) {
+	RegisterTestingT(t)
+
+	edPriv, edPub, err := GenerateEd25519KeyPair()
+	Expect(err).ToNot(HaveOccurred())
+	otherPriv, _, err := GenerateEd25519KeyPair()
+	Expect(err).ToNot(HaveOccurred())
+	rsaPriv, _, err := GenerateKeyPair(2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	armored, err := EncryptAge(edPub, []byte("secret"))
+	Expect(err).ToNot(HaveOccurred())
+
+	t.Run("other key", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := DecryptAge(otherPriv, armored)
+		Expect(err).To(MatchError(ContainSubstring("not encrypted for the private key")))
+		_, err = DecryptAge(rsaPriv, armored)
+		Expect(err).To(MatchError(ContainSubstring("not encrypted for the private key")))
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		RegisterTestingT(t)
+		file, err := dearmorAge(armored)
+		Expect(err).ToNot(HaveOccurred())
+		file[len(file)-1] ^= 1
+		_, err = DecryptAge(edPriv, armorAge(file))
+		Expect(err).To(MatchError(ContainSubstring("failed to decrypt age payload")))
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		RegisterTestingT(t)
+		file, err := dearmorAge(armored)
+		Expect(err).ToNot(HaveOccurred())
+		tampered := bytes.Replace(file, []byte("-> ssh-ed25519 "), []byte("-> ssh-ed25519  "), 1)
+		_, err = DecryptAge(edPriv, armorAge(tampered))
+		Expect(err).To(MatchError(ContainSubstring("header mac mismatch")))
+	})
+
+	t.Run("not armored", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(IsAgeEncrypted([]string{base64.StdEncoding.EncodeToString([]byte("scx25519"))})).To(BeFalse())
+		_, err := DecryptAge(edPriv, "age-encryption.org/v1")
+		Expect(err).To(MatchError(ContainSubstring("not an armored age file")))
+	})
+}
@@ -22,7 +22,12 @@ const EncryptedSecretFilesDataFileName = "secrets.yaml"
 // guard in unmarshalSecretsFile — instead of silently dropping the new fields on
 // the next write. This reader must therefore ship and roll out fleet-wide BEFORE
 // any higher-versioned store is ever written.
-const CurrentSecretsSchemaVersion = 0
+//
+// Version 1 adds recipient groups (`registry.groups`); it is written only when groups
+// are declared, so stores without groups stay readable by version 0 binaries.
+// Version 2 adds the age cipher (`registry.cipher: age`); it is written only when
+// the age cipher is selected.
+const CurrentSecretsSchemaVersion = AgeCipherSchemaVersion
 
 // ErrSecretsStoreVersionUnsupported is returned when the on-disk store declares a
 // schema version newer than CurrentSecretsSchemaVersion. It MUST stay fatal on every
@@ -53,6 +58,8 @@ type Cryptor interface {
 	MarshalSecretsFile() error
 	GetSecretFiles() EncryptedSecretFiles
 	GetAndDecryptFileContent(relPath string) ([]byte, error)
+	// EncryptContent encrypts content of the secret file without writing it to disk
+	EncryptContent(relPath string, content []byte) error
 	PublicKey() string
 	PrivateKey() string
 	Workdir() string
@@ -62,6 +69,22 @@ type Cryptor interface {
 	RemovePublicKey(pubKey string) error
 	// GetKnownPublicKeys return all public keys
 	GetKnownPublicKeys() []string
+	// Rotate replaces the key pair of the current profile and re-encrypts secrets without revoked public keys
+	Rotate(params RotateParams) (*RotationReport, error)
+	// SetCipher selects the cipher of the secrets store and re-encrypts secrets with it
+	SetCipher(name string) error
+	// Guard checks files about to be committed for plaintext secrets and revealed files for changes which are not hidden yet
+	Guard(files map[string][]byte) (*GuardReport, error)
+	// History returns changes of recipients, secret files and hashes of their values made by commits of secrets.yaml
+	History(relFilePath string) ([]HistoryEntry, error)
+	// SetRecipientGroup declares group of public keys allowed to decrypt files matching its globs
+	// (group without keys and files is removed) and re-encrypts secrets accordingly
+	SetRecipientGroup(name string, group RecipientGroup) error
+	// Seal encrypts data of any size for public keys allowed to decrypt all of the secret files
+	// (for all public keys if no files are given)
+	Seal(data []byte, relFilePaths ...string) (*SealedData, error)
+	// Open decrypts data sealed with Seal using the current private key
+	Open(sealed SealedData) ([]byte, error)
 
 	Options() []Option
 	GitRepo() git.Repo
@@ -80,10 +103,12 @@ type cryptor struct {
 	currentPrivateKey    string
 	currentPublicKey     string
 	privateKeyPassphrase string
-	secrets              EncryptedSecretFiles
-	consoleWriter        util.ConsoleWriter
-	consoleReader        util.ConsoleReader
-	confirmationReader   util.ConsoleReader
+	// keyFromSigner is set when the key pair is derived from a signer and must not be stored
+	keyFromSigner      bool
+	secrets            EncryptedSecretFiles
+	consoleWriter      util.ConsoleWriter
+	consoleReader      util.ConsoleReader
+	confirmationReader util.ConsoleReader
 }
 
 func (c *cryptor) Workdir() string {
@@ -148,6 +173,14 @@ type SshKey struct {
 
 type Registry struct {
 	Files []string `json:"files" yaml:"files"`
+	// Cipher secrets are encrypted with: empty for the sc cipher or `age`
+	Cipher string `json:"cipher,omitempty" yaml:"cipher,omitempty"`
+	// Groups restrict recipients of files matching their globs, other files are encrypted for all public keys
+	Groups map[string]RecipientGroup `json:"groups,omitempty" yaml:"groups,omitempty"`
+	// KeyEpoch is incremented by every rotation of the keys (see `sc secrets rotate`)
+	KeyEpoch int `json:"keyEpoch,omitempty" yaml:"keyEpoch,omitempty"`
+	// RevokedKeys are public keys removed since the last rotation, with files they could decrypt
+	RevokedKeys []RevokedKey `json:"revokedKeys,omitempty" yaml:"revokedKeys,omitempty"`
 }
 
 type EncryptedSecretFile struct {
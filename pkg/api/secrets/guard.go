@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+// guardMinValueLength skips short values (e.g. ports or booleans) which would match unrelated content
+const guardMinValueLength = 6
+
+type GuardFindingKind string
+
+const (
+	// GuardSecretFile is a plaintext secret file registered in secrets.yaml which is about to be committed
+	GuardSecretFile GuardFindingKind = "secret-file"
+	// GuardSecretValue is a file containing a value of a decrypted secret
+	GuardSecretValue GuardFindingKind = "secret-value"
+	// GuardOutdatedStore is a revealed secret file which differs from its content encrypted into secrets.yaml,
+	// either it has local changes which are not encrypted yet or it is a stale copy revealed before secrets.yaml was updated
+	GuardOutdatedStore GuardFindingKind = "outdated-store"
+)
+
+type GuardFinding struct {
+	Kind   GuardFindingKind `json:"kind" yaml:"kind"`
+	Path   string           `json:"path" yaml:"path"`
+	Detail string           `json:"detail" yaml:"detail"`
+}
+
+// GuardReport lists secrets which would leak with the commit, empty report means the commit is safe
+type GuardReport struct {
+	Findings []GuardFinding `json:"findings" yaml:"findings"`
+}
+
+func (r *GuardReport) Passed() bool {
+	return len(r.Findings) == 0
+}
+
+// Guard checks files about to be committed (paths relative to the repository root mapped to their contents)
+// for plaintext secret files and values of decrypted secrets, and revealed secret files which differ from
+// their content encrypted into secrets.yaml. Values are compared by their hashes,
+// so neither the report nor its detail contain them
+func (c *cryptor) Guard(files map[string][]byte) (*GuardReport, error) {
+	defer c.withReadLock()()
+
+	if err := c.initData(); err != nil {
+		return nil, err
+	}
+	contents, err := c.decryptRegisteredFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GuardReport{}
+	known := make(map[[sha256.Size]byte][]string)
+	for _, relFilePath := range c.secrets.Registry.Files {
+		if _, staged := files[relFilePath]; staged {
+			report.Findings = append(report.Findings, GuardFinding{
+				Kind: GuardSecretFile, Path: relFilePath,
+				Detail: "plaintext secret file is staged, unstage it and make sure it is in .gitignore",
+			})
+		}
+		content, decrypted := contents[relFilePath]
+		if !decrypted {
+			continue
+		}
+		for source, value := range secretValues(relFilePath, content) {
+			hash := sha256.Sum256([]byte(value))
+			known[hash] = append(known[hash], source)
+		}
+		if c.gitRepo.Exists(relFilePath) {
+			if revealed, err := c.readSecretFile(relFilePath); err != nil {
+				return nil, err
+			} else if !bytes.Equal(revealed, content) {
+				report.Findings = append(report.Findings, GuardFinding{
+					Kind: GuardOutdatedStore, Path: relFilePath,
+					Detail: fmt.Sprintf("differs from %s, run `sc secrets hide` to encrypt local changes or `sc secrets reveal` to replace a stale copy",
+						EncryptedSecretFilesDataFileName),
+				})
+			}
+		}
+	}
+
+	paths := lo.Keys(files)
+	sort.Strings(paths)
+	storePath := filepath.ToSlash(filepath.Join(api.ScConfigDirectory, EncryptedSecretFilesDataFileName))
+	for _, relFilePath := range paths {
+		if relFilePath == storePath || lo.Contains(c.secrets.Registry.Files, relFilePath) {
+			continue
+		}
+		var sources []string
+		for _, candidate := range guardCandidates(files[relFilePath]) {
+			sources = append(sources, known[sha256.Sum256([]byte(candidate))]...)
+		}
+		if len(sources) > 0 {
+			sources = lo.Uniq(sources)
+			sort.Strings(sources)
+			report.Findings = append(report.Findings, GuardFinding{
+				Kind: GuardSecretValue, Path: relFilePath,
+				Detail: "contains " + strings.Join(sources, ", "),
+			})
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Path < report.Findings[j].Path
+	})
+	return report, nil
+}
+
+// secretValues returns values of the secret file (see secretKeyValues) and its whole content keyed by their description
+func secretValues(relFilePath string, content []byte) map[string]string {
+	res := make(map[string]string)
+	for key, value := range secretKeyValues(content) {
+		res[fmt.Sprintf("value of %q from %s", key, relFilePath)] = value
+	}
+	res["content of "+relFilePath] = strings.TrimSpace(string(content))
+	return lo.PickBy(res, func(_ string, value string) bool {
+		return len(value) >= guardMinValueLength
+	})
+}
+
+// guardCandidates splits content into strings which may hold a secret value: trimmed lines,
+// values of KEY=VALUE and `key: value` lines and whitespace separated words
+func guardCandidates(content []byte) []string {
+	res := []string{strings.TrimSpace(string(content))}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		res = append(res, line)
+		for _, sep := range []string{"=", ": "} {
+			if _, value, found := strings.Cut(line, sep); found {
+				res = append(res, unquote(strings.TrimSpace(value)))
+			}
+		}
+		for _, word := range strings.Fields(line) {
+			res = append(res, unquote(strings.Trim(word, ",;:")))
+		}
+	}
+	return lo.Filter(res, func(candidate string, _ int) bool {
+		return len(candidate) >= guardMinValueLength
+	})
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.ContainsRune(`"'`+"`", rune(value[0])) && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestGuard(t *testing.T) {
+	RegisterTestingT(t)
+	c, wd, cleanup := newTestCryptor(t)
+	defer cleanup()
+
+	secretsFile := "stacks/common/secrets.yaml"
+	original, err := os.ReadFile(path.Join(wd, secretsFile))
+	Expect(err).To(BeNil())
+	content, err := SetSecretValue(original, "STRIPE_KEY", "sk_live_abcdef")
+	Expect(err).To(BeNil())
+	Expect(os.WriteFile(path.Join(wd, secretsFile), content, 0o644)).To(Succeed())
+	Expect(c.AddFile(secretsFile)).To(Succeed())
+
+	t.Run("passes for files without secrets", func(t *testing.T) {
+		RegisterTestingT(t)
+		report, err := c.Guard(map[string][]byte{
+			"README.md":           []byte("STRIPE_KEY is read from secrets"),
+			".sc/secrets.yaml":    []byte("registry: {}"),
+			"stacks/app/env.yaml": []byte("port: 8080\n"),
+		})
+		Expect(err).To(BeNil())
+		Expect(report.Passed()).To(BeTrue())
+	})
+
+	t.Run("flags staged plaintext secret files", func(t *testing.T) {
+		RegisterTestingT(t)
+		report, err := c.Guard(map[string][]byte{secretsFile: content})
+		Expect(err).To(BeNil())
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Kind).To(Equal(GuardSecretFile))
+		Expect(report.Findings[0].Path).To(Equal(secretsFile))
+	})
+
+	t.Run("flags values of decrypted secrets without printing them", func(t *testing.T) {
+		RegisterTestingT(t)
+		report, err := c.Guard(map[string][]byte{
+			"deploy/app.env":  []byte("# payments\nSTRIPE_KEY=sk_live_abcdef\n"),
+			"deploy/app.yaml": []byte("env:\n  key: \"sk_live_abcdef\"\n"),
+			"deploy/run.sh":   []byte("curl -u sk_live_abcdef: https://api.stripe.com"),
+		})
+		Expect(err).To(BeNil())
+		Expect(report.Findings).To(HaveLen(3))
+		for _, finding := range report.Findings {
+			Expect(finding.Kind).To(Equal(GuardSecretValue))
+			Expect(finding.Detail).To(Equal(`contains value of "STRIPE_KEY" from stacks/common/secrets.yaml`))
+			Expect(finding.Detail).NotTo(ContainSubstring("sk_live_abcdef"))
+		}
+	})
+
+	t.Run("flags revealed files changed since they were encrypted", func(t *testing.T) {
+		RegisterTestingT(t)
+		changed, err := SetSecretValue(content, "STRIPE_KEY", "sk_live_123456")
+		Expect(err).To(BeNil())
+		Expect(os.WriteFile(path.Join(wd, secretsFile), changed, 0o644)).To(Succeed())
+
+		report, err := c.Guard(map[string][]byte{})
+		Expect(err).To(BeNil())
+		Expect(report.Findings).To(ConsistOf(GuardFinding{
+			Kind: GuardOutdatedStore, Path: secretsFile,
+			Detail: "differs from secrets.yaml, run `sc secrets hide` to encrypt local changes or `sc secrets reveal` to replace a stale copy",
+		}))
+
+		Expect(c.EncryptChanged(false, true)).To(Succeed())
+		Expect(c.MarshalSecretsFile()).To(Succeed())
+		report, err = c.Guard(map[string][]byte{})
+		Expect(err).To(BeNil())
+		Expect(report.Passed()).To(BeTrue())
+	})
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+type HistoryChangeKind string
+
+const (
+	HistoryRecipientAdded   HistoryChangeKind = "recipient-added"
+	HistoryRecipientRemoved HistoryChangeKind = "recipient-removed"
+	HistoryFileAdded        HistoryChangeKind = "file-added"
+	HistoryFileRemoved      HistoryChangeKind = "file-removed"
+	HistoryValueAdded       HistoryChangeKind = "value-added"
+	HistoryValueChanged     HistoryChangeKind = "value-changed"
+	HistoryValueRemoved     HistoryChangeKind = "value-removed"
+	// HistoryFileUndecryptable means the current key cannot decrypt the file since the commit,
+	// changes of its values are reported with the next commit the key can decrypt it in
+	HistoryFileUndecryptable HistoryChangeKind = "undecryptable"
+)
+
+type HistoryChange struct {
+	Kind HistoryChangeKind `json:"kind" yaml:"kind"`
+	// File is the secret file the change belongs to, empty for recipients of the whole store
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	// Key is the key of the value or the public key of the recipient
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+	// Fingerprint identifies the new value of an added or changed value, equal values have equal fingerprints
+	// within the repository. It is a truncated HMAC keyed with the first commit of secrets.yaml rather than
+	// a plain hash, so short secrets cannot be brute-forced from it with precomputed tables
+	Fingerprint string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+}
+
+// historyFingerprintLength is the number of hex characters of value fingerprints reported in history
+const historyFingerprintLength = 12
+
+// HistoryEntry lists changes of secrets made by the commit of secrets.yaml
+type HistoryEntry struct {
+	Commit  string          `json:"commit" yaml:"commit"`
+	Author  string          `json:"author" yaml:"author"`
+	When    time.Time       `json:"when" yaml:"when"`
+	Message string          `json:"message" yaml:"message"`
+	Changes []HistoryChange `json:"changes" yaml:"changes"`
+}
+
+// historyState is the state of secrets at a revision of secrets.yaml
+type historyState struct {
+	recipients []string
+	files      []string
+	// values are fingerprints of values by their keys of files the current key could decrypt at the revision
+	values map[string]map[string]string
+}
+
+// History walks commits of secrets.yaml decrypting every revision in memory with the current key and returns
+// changes of recipients, registered files and keys of values, newest first. If relFilePath is not empty,
+// only changes of the file are returned and recipients are public keys the file is encrypted for
+func (c *cryptor) History(relFilePath string) ([]HistoryEntry, error) {
+	defer c.withReadLock()()
+
+	if err := c.initData(); err != nil {
+		return nil, err
+	}
+	storePath := path.Join(api.ScConfigDirectory, EncryptedSecretFilesDataFileName)
+	revisions, err := c.gitRepo.FileHistory(storePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	// the first commit of secrets.yaml keys fingerprints of values, so they are comparable within the repository only
+	fingerprintKey := []byte(revisions[len(revisions)-1].Hash)
+
+	var res []HistoryEntry
+	prev := historyState{values: make(map[string]map[string]string)}
+	undecryptable := make(map[string]bool)
+	decrypted := make(map[[sha256.Size]byte]map[string]string)
+	for i := len(revisions) - 1; i >= 0; i-- {
+		revision := revisions[i]
+		store := EncryptedSecretFiles{}
+		if revision.Content != nil {
+			parsed, err := api.UnmarshalDescriptor[EncryptedSecretFiles](revision.Content)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal %q of commit %s", storePath, revision.Hash)
+			} else if parsed.SchemaVersion > CurrentSecretsSchemaVersion {
+				return nil, fmt.Errorf("%q of commit %s is schema version %d, but this sc build supports up to schema version %d; upgrade sc: %w",
+					storePath, revision.Hash, parsed.SchemaVersion, CurrentSecretsSchemaVersion, ErrSecretsStoreVersionUnsupported)
+			}
+			store = *parsed
+			store.Secrets = lo.MapKeys(store.Secrets, func(_ EncryptedSecrets, key string) string {
+				return TrimPubKey(key)
+			})
+		}
+		files := lo.Filter(store.Registry.Files, func(file string, _ int) bool {
+			return relFilePath == "" || file == relFilePath
+		})
+		cur := historyState{recipients: historyRecipients(store, relFilePath), files: files, values: make(map[string]map[string]string)}
+
+		var changes []HistoryChange
+		added, removed := lo.Difference(cur.recipients, prev.recipients)
+		for _, key := range added {
+			changes = append(changes, HistoryChange{Kind: HistoryRecipientAdded, File: relFilePath, Key: key})
+		}
+		for _, key := range removed {
+			changes = append(changes, HistoryChange{Kind: HistoryRecipientRemoved, File: relFilePath, Key: key})
+		}
+		currentSecrets := store.Secrets[TrimPubKey(c.currentPublicKey)]
+		for _, file := range files {
+			prevValues, known := prev.values[file]
+			if !lo.Contains(prev.files, file) {
+				changes = append(changes, HistoryChange{Kind: HistoryFileAdded, File: file})
+				prevValues, known = map[string]string{}, true
+			}
+			encrypted := currentSecrets.GetEncryptedContent(file)
+			if len(encrypted) == 0 {
+				if !undecryptable[file] {
+					changes = append(changes, HistoryChange{Kind: HistoryFileUndecryptable, File: file})
+					undecryptable[file] = true
+				}
+				if known {
+					cur.values[file] = prevValues
+				}
+				continue
+			}
+			cacheKey := sha256.Sum256([]byte(strings.Join(encrypted, "\n")))
+			values, cached := decrypted[cacheKey]
+			if !cached {
+				content, err := c.decryptSecretData(encrypted)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to decrypt secret file %q of commit %s", file, revision.Hash)
+				}
+				values = lo.MapValues(secretKeyValues(content), func(value string, _ string) string {
+					return historyValueFingerprint(fingerprintKey, value)
+				})
+				decrypted[cacheKey] = values
+			}
+			delete(undecryptable, file)
+			cur.values[file] = values
+			changes = append(changes, diffHistoryValues(file, prevValues, values)...)
+		}
+		for _, file := range prev.files {
+			if !lo.Contains(files, file) {
+				changes = append(changes, HistoryChange{Kind: HistoryFileRemoved, File: file})
+				delete(undecryptable, file)
+			}
+		}
+		prev = cur
+		if len(changes) == 0 {
+			continue
+		}
+		res = append(res, HistoryEntry{
+			Commit:  revision.Hash,
+			Author:  revision.Author,
+			When:    revision.When,
+			Message: strings.TrimSpace(revision.Message),
+			Changes: changes,
+		})
+	}
+	slices.Reverse(res)
+	return res, nil
+}
+
+// historyRecipients returns sorted public keys of the store, or public keys the file is encrypted for if it is not empty
+func historyRecipients(store EncryptedSecretFiles, relFilePath string) []string {
+	res := lo.Filter(lo.Keys(store.Secrets), func(key string, _ int) bool {
+		if relFilePath == "" {
+			return true
+		}
+		secrets := store.Secrets[key]
+		return len(secrets.GetEncryptedContent(relFilePath)) > 0
+	})
+	sort.Strings(res)
+	return res
+}
+
+func diffHistoryValues(file string, prev, cur map[string]string) []HistoryChange {
+	var res []HistoryChange
+	keys := lo.Uniq(append(lo.Keys(prev), lo.Keys(cur)...))
+	sort.Strings(keys)
+	for _, key := range keys {
+		prevFingerprint, wasSet := prev[key]
+		curFingerprint, isSet := cur[key]
+		switch {
+		case !wasSet:
+			res = append(res, HistoryChange{Kind: HistoryValueAdded, File: file, Key: key, Fingerprint: curFingerprint})
+		case !isSet:
+			res = append(res, HistoryChange{Kind: HistoryValueRemoved, File: file, Key: key})
+		case prevFingerprint != curFingerprint:
+			res = append(res, HistoryChange{Kind: HistoryValueChanged, File: file, Key: key, Fingerprint: curFingerprint})
+		}
+	}
+	return res
+}
+
+func historyValueFingerprint(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:historyFingerprintLength]
+}
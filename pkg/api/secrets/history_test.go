@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/git"
+	"github.com/simple-container-com/api/pkg/api/secrets/ciphers"
+)
+
+// historyRepo returns fixed revisions of secrets.yaml instead of reading them from git
+type historyRepo struct {
+	git.Repo
+	revisions []git.FileRevision
+}
+
+func (r *historyRepo) FileHistory(filePath string) ([]git.FileRevision, error) {
+	return r.revisions, nil
+}
+
+func TestHistory(t *testing.T) {
+	RegisterTestingT(t)
+	c, wd, cleanup := newTestCryptor(t)
+	defer cleanup()
+
+	commonFile := "stacks/common/secrets.yaml"
+	storePath := path.Join(wd, api.ScConfigDirectory, EncryptedSecretFilesDataFileName)
+	var revisions []git.FileRevision
+	commit := func(hash, message string) {
+		store, err := os.ReadFile(storePath)
+		Expect(err).To(BeNil())
+		revisions = append([]git.FileRevision{{
+			Commit:  git.Commit{Hash: hash, Author: "Alice <alice@example.com>", Message: message + "\n", When: time.Unix(int64(len(revisions)), 0)},
+			Content: store,
+		}}, revisions...)
+	}
+
+	Expect(c.AddFile(commonFile)).To(Succeed())
+	commit("c1", "add common secrets")
+
+	original, err := os.ReadFile(path.Join(wd, commonFile))
+	Expect(err).To(BeNil())
+	content, err := SetSecretValue(original, "GITHUB_TOKEN", "ghp_rotated")
+	Expect(err).To(BeNil())
+	content, err = SetSecretValue(content, "STRIPE_KEY", "sk_live_abcdef")
+	Expect(err).To(BeNil())
+	content, _, err = UnsetSecretValue(content, "CLOUDFLARE_API_TOKEN")
+	Expect(err).To(BeNil())
+	Expect(os.WriteFile(path.Join(wd, commonFile), content, 0o644)).To(Succeed())
+	Expect(c.EncryptChanged(false, true)).To(Succeed())
+	Expect(c.MarshalSecretsFile()).To(Succeed())
+	commit("c2", "update tokens")
+
+	_, teammatePubKey, err := ciphers.GenerateEd25519KeyPair()
+	Expect(err).To(BeNil())
+	teammatePubKeySSH, err := ciphers.MarshalEd25519PublicKey(teammatePubKey)
+	Expect(err).To(BeNil())
+	teammateKey := TrimPubKey(string(teammatePubKeySSH))
+	Expect(c.AddPublicKey(teammateKey)).To(Succeed())
+	commit("c3", "allow teammate")
+
+	Expect(c.AddFile("stacks/refapp/secrets.yaml")).To(Succeed())
+	commit("c4", "add refapp secrets")
+
+	Expect(c.RemovePublicKey(teammateKey)).To(Succeed())
+	commit("c5", "disallow teammate")
+
+	c.(*cryptor).gitRepo = &historyRepo{Repo: c.GitRepo(), revisions: revisions}
+	currentKey := TrimPubKey(c.PublicKey())
+
+	t.Run("lists changes of all files newest first", func(t *testing.T) {
+		RegisterTestingT(t)
+		history, err := c.History("")
+		Expect(err).To(BeNil())
+		Expect(history).To(HaveLen(5))
+		Expect(history[0].Commit).To(Equal("c5"))
+		Expect(history[0].Author).To(Equal("Alice <alice@example.com>"))
+		Expect(history[0].Message).To(Equal("disallow teammate"))
+		Expect(history[0].Changes).To(Equal([]HistoryChange{{Kind: HistoryRecipientRemoved, Key: teammateKey}}))
+		Expect(history[1].Changes[0]).To(Equal(HistoryChange{Kind: HistoryFileAdded, File: "stacks/refapp/secrets.yaml"}))
+		Expect(history[2].Changes).To(Equal([]HistoryChange{{Kind: HistoryRecipientAdded, Key: teammateKey}}))
+		Expect(history[3].Changes).To(Equal([]HistoryChange{
+			{Kind: HistoryValueRemoved, File: commonFile, Key: "CLOUDFLARE_API_TOKEN"},
+			{Kind: HistoryValueChanged, File: commonFile, Key: "GITHUB_TOKEN", Fingerprint: historyValueFingerprint([]byte("c1"), "ghp_rotated")},
+			{Kind: HistoryValueAdded, File: commonFile, Key: "STRIPE_KEY", Fingerprint: historyValueFingerprint([]byte("c1"), "sk_live_abcdef")},
+		}))
+		Expect(history[4].Changes).To(ContainElements(
+			HistoryChange{Kind: HistoryRecipientAdded, Key: currentKey},
+			HistoryChange{Kind: HistoryFileAdded, File: commonFile},
+		))
+		// values are reported as fingerprints keyed with the first commit, not as values or their plain hashes
+		reported, err := json.Marshal(history)
+		Expect(err).To(BeNil())
+		for _, value := range []string{"ghp_rotated", "sk_live_abcdef"} {
+			fingerprint := historyValueFingerprint([]byte("c1"), value)
+			Expect(fingerprint).To(HaveLen(historyFingerprintLength))
+			Expect(string(reported)).To(ContainSubstring(fingerprint))
+			Expect(string(reported)).NotTo(ContainSubstring(value))
+			plainHash := sha256.Sum256([]byte(value))
+			Expect(string(reported)).NotTo(ContainSubstring(hex.EncodeToString(plainHash[:])[:historyFingerprintLength]))
+		}
+	})
+
+	t.Run("lists changes of the file only", func(t *testing.T) {
+		RegisterTestingT(t)
+		history, err := c.History(commonFile)
+		Expect(err).To(BeNil())
+		Expect(history).To(HaveLen(4))
+		Expect(history[0].Changes).To(Equal([]HistoryChange{{Kind: HistoryRecipientRemoved, File: commonFile, Key: teammateKey}}))
+		Expect(history[1].Commit).To(Equal("c3"))
+		Expect(history[1].Changes).To(Equal([]HistoryChange{{Kind: HistoryRecipientAdded, File: commonFile, Key: teammateKey}}))
+		Expect(history[2].Commit).To(Equal("c2"))
+		Expect(history[3].Commit).To(Equal("c1"))
+	})
+}
@@ -47,6 +47,10 @@ func (c *cryptor) GetAndDecryptFileContent(relPath string) ([]byte, error) {
 	// Normalize the current public key to ensure it matches how keys are stored in secrets map
 	normalizedCurrentKey := TrimPubKey(c.currentPublicKey)
 
+	if !c.secrets.Registry.Allows(relPath, normalizedCurrentKey) {
+		return nil, errors.Errorf("secret file %q is restricted to recipient groups %s, current public key is not a member of them",
+			relPath, strings.Join(c.secrets.Registry.GroupsOf(relPath), ", "))
+	}
 	if f, found := c.secrets.Secrets[normalizedCurrentKey]; !found {
 		return nil, errors.Errorf("secret file %q not found", relPath)
 	} else if encrypted, found := lo.Find(f.Files, func(item EncryptedSecretFile) bool {
@@ -93,6 +97,15 @@ func (c *cryptor) RemovePublicKey(pubKey string) error {
 		return errors.Errorf("public key %q not found in secrets", normalizedKey)
 	}
 
+	// files the key could decrypt stay readable from git history until their values are rotated
+	if files := c.secrets.Secrets[normalizedKey].Files; len(files) > 0 {
+		c.secrets.Registry.RevokedKeys = append(c.secrets.Registry.RevokedKeys, RevokedKey{
+			PublicKey: normalizedKey,
+			Files: lo.Map(files, func(f EncryptedSecretFile, _ int) string {
+				return f.Path
+			}),
+		})
+	}
 	delete(c.secrets.Secrets, normalizedKey)
 	err := c.EncryptChanged(true, false)
 	if err != nil {
@@ -104,6 +117,7 @@ func (c *cryptor) RemovePublicKey(pubKey string) error {
 	}
 
 	c.consoleWriter.Println(color.GreenFmt("removed public key"), color.MagentaFmt("%s", normalizedKey))
+	c.consoleWriter.Println(color.YellowFmt("secrets the key could decrypt are listed by"), "sc secrets rotate --dry-run")
 	return nil
 }
 
@@ -189,6 +203,17 @@ func (c *cryptor) unmarshalSecretsFile() error {
 func (c *cryptor) MarshalSecretsFile() error {
 	secretsFilePath := path.Join(api.ScConfigDirectory, EncryptedSecretFilesDataFileName)
 
+	// stores are written with the lowest schema version supporting their features,
+	// so they are kept readable by builds which do not support the rest
+	switch {
+	case c.secrets.Registry.Cipher == CipherAge:
+		c.secrets.SchemaVersion = AgeCipherSchemaVersion
+	case len(c.secrets.Registry.Groups) > 0:
+		c.secrets.SchemaVersion = RecipientGroupsSchemaVersion
+	default:
+		c.secrets.SchemaVersion = 0
+	}
+
 	bytes, err := api.MarshalDescriptor(&c.secrets)
 	if err != nil {
 		return errors.Wrapf(err, "failed to marshal secrets")
@@ -231,6 +256,13 @@ func (c *cryptor) DecryptAll(forceChanged bool) error {
 		revealedCount++
 		c.consoleWriter.Println(color.GreenFmt("revealed"), color.MagentaFmt("%s", sFile.Path))
 	}
+	currentSecrets := c.secrets.Secrets[normalizedCurrentKey]
+	for _, relFilePath := range c.secrets.Registry.Files {
+		if len(currentSecrets.GetEncryptedContent(relFilePath)) == 0 {
+			// e.g. file is scoped to recipient groups current key is not a member of
+			c.consoleWriter.Println(color.YellowFmt("skipped"), color.MagentaFmt("%s", relFilePath), "(not encrypted for current public key)")
+		}
+	}
 
 	if revealedCount > 0 {
 		c.consoleWriter.Println(color.GreenFmt("revealed %d secret file(s)", revealedCount))
@@ -242,73 +274,91 @@ func (c *cryptor) DecryptAll(forceChanged bool) error {
 }
 
 func (c *cryptor) EncryptChanged(force bool, forceChanged bool) error {
-	// Normalize the current public key to ensure it matches how keys are stored in secrets map
-	normalizedCurrentKey := TrimPubKey(c.currentPublicKey)
-
 	c.secrets.Secrets = lo.MapKeys(c.secrets.Secrets, func(_ EncryptedSecrets, key string) string {
 		return TrimPubKey(key)
 	})
 	for publicKey := range c.secrets.Secrets {
 		filteredSecrets := c.secrets.Secrets[publicKey]
 		filteredSecrets.Files = lo.Filter(filteredSecrets.Files, func(file EncryptedSecretFile, _ int) bool {
-			return lo.Contains(c.secrets.Registry.Files, file.Path)
+			return lo.Contains(c.secrets.Registry.Files, file.Path) && c.secrets.Registry.Allows(file.Path, publicKey)
 		})
 		c.secrets.Secrets[publicKey] = filteredSecrets
 	}
 
 	acceptedChanges := make(map[string]bool)
 	for _, relFilePath := range c.secrets.Registry.Files {
+		if c.skipUndecryptable(relFilePath) {
+			continue
+		}
 		secretData, err := c.readSecretFile(relFilePath)
 		if err != nil {
 			return errors.Wrapf(err, "failed to read secret file %q", relFilePath)
 		}
-		secrets := c.secrets.Secrets[normalizedCurrentKey]
-
-		currentContent, _ := c.decryptSecretData(secrets.GetEncryptedContent(relFilePath))
-		if currentContent != nil && string(secretData) == string(currentContent) && !force {
-			// skip re-encrypting for unchanged secret
-			continue
+		if err := c.encryptChangedData(relFilePath, secretData, force, forceChanged, acceptedChanges); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		// for all other public keys
-		for publicKey := range c.secrets.Secrets {
-			pKeySecrets := c.secrets.Secrets[publicKey]
+// EncryptContent encrypts content of the secret file with all known public keys without writing the content to disk,
+// the file is registered if it is not yet
+func (c *cryptor) EncryptContent(relFilePath string, content []byte) error {
+	defer c.withWriteLock()()
 
-			sFile, err := c.encryptSecretsFileWith(publicKey, relFilePath)
-			if err != nil {
-				return err
-			}
+	if err := c.initData(); err != nil {
+		return err
+	}
+	if !lo.Contains(c.secrets.Registry.Files, relFilePath) {
+		c.secrets.Registry.Files = append(c.secrets.Registry.Files, relFilePath)
+		if err := c.gitRepo.AddFileToIgnore(relFilePath); err != nil {
+			return err
+		}
+	}
+	// diff is not shown to avoid printing secrets
+	if err := c.encryptChangedData(relFilePath, content, false, true, make(map[string]bool)); err != nil {
+		return errors.Wrapf(err, "failed to encrypt secret file %q", relFilePath)
+	}
+	return c.MarshalSecretsFile()
+}
 
-			if accepted := acceptedChanges[sFile.Path]; !accepted {
-				if err := c.ensureDiffAcceptable(sFile.Path, currentContent, secretData, forceChanged); err != nil {
-					return errors.Wrapf(err, "diff is not acceptable")
-				}
-				acceptedChanges[sFile.Path] = true
-			}
+func (c *cryptor) encryptChangedData(relFilePath string, secretData []byte, force bool, forceChanged bool, acceptedChanges map[string]bool) error {
+	// Normalize the current public key to ensure it matches how keys are stored in secrets map
+	normalizedCurrentKey := TrimPubKey(c.currentPublicKey)
+	if _, found := c.secrets.Secrets[normalizedCurrentKey]; !found {
+		c.secrets.Secrets[normalizedCurrentKey] = EncryptedSecrets{}
+	}
+	secrets := c.secrets.Secrets[normalizedCurrentKey]
+	recipients := c.recipients(relFilePath)
 
-			if string(secretData) != string(currentContent) {
-				pKeySecrets.RemoveFile(sFile)
-			}
+	currentContent, _ := c.decryptSecretData(secrets.GetEncryptedContent(relFilePath))
+	if currentContent != nil && string(secretData) == string(currentContent) && !force && c.encryptedForAll(relFilePath, recipients) {
+		// skip re-encrypting for unchanged secret
+		return nil
+	}
 
-			pKeySecrets.AddFileIfNotExist(sFile)
-			c.secrets.Secrets[publicKey] = pKeySecrets
-		}
+	// for all public keys allowed to decrypt the file
+	for _, publicKey := range recipients {
+		pKeySecrets := c.secrets.Secrets[publicKey]
 
-		sFile, err := c.encryptSecretsFileWith(normalizedCurrentKey, relFilePath)
+		sFile, err := c.encryptSecretDataWith(publicKey, relFilePath, secretData)
 		if err != nil {
 			return err
 		}
+
 		if accepted := acceptedChanges[sFile.Path]; !accepted {
 			if err := c.ensureDiffAcceptable(sFile.Path, currentContent, secretData, forceChanged); err != nil {
 				return errors.Wrapf(err, "diff is not acceptable")
 			}
+			acceptedChanges[sFile.Path] = true
 		}
-		acceptedChanges[sFile.Path] = true
+
 		if string(secretData) != string(currentContent) {
-			secrets.RemoveFile(sFile)
+			pKeySecrets.RemoveFile(sFile)
 		}
-		secrets.AddFileIfNotExist(sFile)
-		c.secrets.Secrets[normalizedCurrentKey] = secrets
+
+		pKeySecrets.AddFileIfNotExist(sFile)
+		c.secrets.Secrets[publicKey] = pKeySecrets
 	}
 	return nil
 }
@@ -384,9 +434,9 @@ func (c *cryptor) ensureDiffAcceptable(fileName string, currentContent, newConte
 	return errors.Errorf("Change is not accepted")
 }
 
-func (c *cryptor) encryptSecretsFileWith(publicKey string, relFilePath string) (EncryptedSecretFile, error) {
+func (c *cryptor) encryptSecretDataWith(publicKey string, relFilePath string, secretData []byte) (EncryptedSecretFile, error) {
 	file := EncryptedSecretFile{}
-	encryptedData, err := c.encryptSecretFile(publicKey, relFilePath)
+	encryptedData, err := c.encryptSecretData(publicKey, relFilePath, secretData)
 	if err != nil {
 		return file, err
 	}
@@ -401,14 +451,23 @@ func (c *cryptor) encryptSecretFile(keyData string, relFilePath string) ([]strin
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to read secret file %q", relFilePath)
 	}
+	return c.encryptSecretData(keyData, relFilePath, secretData)
+}
 
+func (c *cryptor) encryptSecretData(keyData string, relFilePath string, secretData []byte) ([]string, error) {
 	parsed, err := ciphers.ParsePublicKey(keyData)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to parse public key: %q", keyData)
 	}
 
 	var encryptedData []string
-	encryptedData, err = ciphers.EncryptLargeString(parsed, string(secretData))
+	if c.secrets.Registry.Cipher == CipherAge {
+		var armored string
+		armored, err = ciphers.EncryptAge(parsed, secretData)
+		encryptedData = []string{armored}
+	} else {
+		encryptedData, err = ciphers.EncryptLargeString(parsed, string(secretData))
+	}
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to encrypt secret file: %q with publicKey %q", relFilePath, keyData[0:15])
 	}
@@ -462,6 +521,14 @@ func (c *cryptor) decryptSecretData(encryptedData []string) ([]byte, error) {
 	}
 
 	var decrypted []byte
+	// age files are self-describing and readable whichever cipher is configured
+	if ciphers.IsAgeEncrypted(encryptedData) {
+		decrypted, err = ciphers.DecryptAge(rawKey, encryptedData[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt age encrypted secret")
+		}
+		return decrypted, nil
+	}
 	// Handle different key types
 	if rsaKey, ok := rawKey.(*rsa.PrivateKey); ok {
 		decrypted, err = ciphers.DecryptLargeString(rsaKey, encryptedData)
@@ -484,6 +551,25 @@ func (c *cryptor) decryptSecretData(encryptedData []string) ([]byte, error) {
 	return decrypted, nil
 }
 
+// decryptRegisteredFiles decrypts in memory all registered files encrypted for the current public key
+func (c *cryptor) decryptRegisteredFiles() (map[string][]byte, error) {
+	currentKey := TrimPubKey(c.currentPublicKey)
+	currentSecrets := c.secrets.Secrets[currentKey]
+	contents := make(map[string][]byte)
+	for _, relFilePath := range c.secrets.Registry.Files {
+		encrypted := currentSecrets.GetEncryptedContent(relFilePath)
+		if len(encrypted) == 0 {
+			continue
+		}
+		content, err := c.decryptSecretData(encrypted)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt secret file %q with configured public key %q", relFilePath, currentKey)
+		}
+		contents[relFilePath] = content
+	}
+	return contents, nil
+}
+
 func (c *cryptor) decryptSecretDataToFile(encryptedData []string, relFilePath string, forceChanged bool) ([]byte, error) {
 	decrypted, err := c.decryptSecretData(encryptedData)
 	if err != nil {
@@ -578,20 +664,25 @@ func NewCryptor(workDir string, opts ...Option) (Cryptor, error) {
 
 func (c *cryptor) GenerateKeyPairWithProfile(projectName string, profile string) error {
 	c.profile = profile
-	privKey, pubKey, err := ciphers.GenerateKeyPair(2048)
+	privKey, pubKey, err := generateRSAKeyPair()
 	if err != nil {
-		return errors.Wrapf(err, "failed to generate key pair")
+		return err
 	}
+	c.currentPrivateKey, c.currentPublicKey = privKey, pubKey
+	return c.writeProfileConfig(projectName)
+}
 
-	c.currentPrivateKey = string(ciphers.PrivateKeyToBytes(privKey))
-
-	mPubKey, err := ciphers.MarshalPublicKey(pubKey)
+func (c *cryptor) GenerateEd25519KeyPairWithProfile(projectName string, profile string) error {
+	c.profile = profile
+	privKey, pubKey, err := generateEd25519KeyPair()
 	if err != nil {
-		return errors.Wrapf(err, "failed to serialize public key")
+		return err
 	}
+	c.currentPrivateKey, c.currentPublicKey = privKey, pubKey
+	return c.writeProfileConfig(projectName)
+}
 
-	c.currentPublicKey = TrimPubKey(string(mPubKey))
-
+func (c *cryptor) writeProfileConfig(projectName string) error {
 	config := &api.ConfigFile{
 		ProjectName: projectName,
 		PrivateKey:  c.currentPrivateKey,
@@ -603,35 +694,32 @@ func (c *cryptor) GenerateKeyPairWithProfile(projectName string, profile string)
 	return nil
 }
 
-func (c *cryptor) GenerateEd25519KeyPairWithProfile(projectName string, profile string) error {
-	c.profile = profile
-	privKey, pubKey, err := ciphers.GenerateEd25519KeyPair()
+func generateRSAKeyPair() (string, string, error) {
+	privKey, pubKey, err := ciphers.GenerateKeyPair(2048)
 	if err != nil {
-		return errors.Wrapf(err, "failed to generate ed25519 key pair")
+		return "", "", errors.Wrapf(err, "failed to generate key pair")
 	}
-
-	privKeyPem, err := ciphers.MarshalEd25519PrivateKey(privKey)
+	mPubKey, err := ciphers.MarshalPublicKey(pubKey)
 	if err != nil {
-		return errors.Wrapf(err, "failed to marshal ed25519 private key")
+		return "", "", errors.Wrapf(err, "failed to serialize public key")
 	}
-	c.currentPrivateKey = privKeyPem
+	return string(ciphers.PrivateKeyToBytes(privKey)), TrimPubKey(string(mPubKey)), nil
+}
 
-	mPubKey, err := ciphers.MarshalEd25519PublicKey(pubKey)
+func generateEd25519KeyPair() (string, string, error) {
+	privKey, pubKey, err := ciphers.GenerateEd25519KeyPair()
 	if err != nil {
-		return errors.Wrapf(err, "failed to serialize ed25519 public key")
+		return "", "", errors.Wrapf(err, "failed to generate ed25519 key pair")
 	}
-
-	c.currentPublicKey = TrimPubKey(string(mPubKey))
-
-	config := &api.ConfigFile{
-		ProjectName: projectName,
-		PrivateKey:  c.currentPrivateKey,
-		PublicKey:   c.currentPublicKey,
+	privKeyPem, err := ciphers.MarshalEd25519PrivateKey(privKey)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to marshal ed25519 private key")
 	}
-	if err := config.WriteConfigFile(c.workDir, c.profile); err != nil {
-		return errors.Wrapf(err, "failed to write config file")
+	mPubKey, err := ciphers.MarshalEd25519PublicKey(pubKey)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to serialize ed25519 public key")
 	}
-	return nil
+	return privKeyPem, TrimPubKey(string(mPubKey)), nil
 }
 
 func (c *cryptor) applyOpts(opts []Option) error {
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 
 	"github.com/simple-container-com/api/pkg/api"
 	"github.com/simple-container-com/api/pkg/api/git"
@@ -165,6 +166,12 @@ func WithKeysFromScConfig(profile string) Option {
 			if cfg.PrivateKeyPassword != "" {
 				c.privateKeyPassphrase = cfg.PrivateKeyPassword
 			}
+			if cfg.PrivateKeyAgent != "" {
+				if cfg.PrivateKeyPath != "" || cfg.PrivateKey != "" {
+					return errors.New("both private key agent and private key are configured")
+				}
+				return WithSSHAgentKey(cfg.PrivateKeyAgent).f(c)
+			}
 			return nil
 		},
 	}
@@ -227,3 +234,37 @@ func WithPrivateKeyPath(filePath string) Option {
 		},
 	}
 }
+
+// WithSSHAgentKey derives the key pair from the ssh-agent key with SHA256 fingerprint, see WithPrivateKeySigner
+func WithSSHAgentKey(fingerprint string) Option {
+	return Option{
+		f: func(c *cryptor) error {
+			signer, err := AgentKeySigner(fingerprint)
+			if err != nil {
+				return err
+			}
+			return WithPrivateKeySigner(signer).f(c)
+		},
+	}
+}
+
+// WithPrivateKeySigner derives ssh-ed25519 key pair from the signature of the signer, which may be backed by
+// ssh-agent or a hardware token (see ssh.NewSignerFromSigner), so the private key is never stored.
+// The derived public key must be allowed to decrypt secrets instead of the public key of the signer
+func WithPrivateKeySigner(signer ssh.Signer) Option {
+	return Option{
+		f: func(c *cryptor) error {
+			privKey, pubKey, err := DeriveSignerKeyPair(signer)
+			if err != nil {
+				return err
+			}
+			if c.currentPublicKey != "" && TrimPubKey(c.currentPublicKey) != pubKey {
+				return errors.Errorf("configured public key does not match key %q derived from %s key %s",
+					pubKey, signer.PublicKey().Type(), ssh.FingerprintSHA256(signer.PublicKey()))
+			}
+			c.currentPrivateKey, c.currentPublicKey, c.privateKeyPassphrase = privKey, pubKey, ""
+			c.keyFromSigner = true
+			return nil
+		},
+	}
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+// RecipientGroupsSchemaVersion is the schema version of secrets.yaml declaring recipient groups
+const RecipientGroupsSchemaVersion = 1
+
+// RecipientGroup is a set of public keys allowed to decrypt files matching the globs, e.g.
+//
+//	groups:
+//	  prod:
+//	    publicKeys: [ "ssh-ed25519 AAAA...ci", "ssh-rsa AAAA...admin" ]
+//	    files: [ ".sc/stacks/*-prod/secrets.yaml" ]
+type RecipientGroup struct {
+	PublicKeys []string `json:"publicKeys" yaml:"publicKeys"`
+	Files      []string `json:"files" yaml:"files"` // globs as in path.Match, e.g. `.sc/stacks/*/secrets.yaml`
+}
+
+// Matches returns true if the file matches any of the group's globs
+func (g RecipientGroup) Matches(relPath string) bool {
+	return lo.SomeBy(g.Files, func(glob string) bool {
+		matched, err := path.Match(glob, relPath)
+		return err == nil && matched
+	})
+}
+
+// HasPublicKey returns true if the key is in the group (aliases of keys are ignored)
+func (g RecipientGroup) HasPublicKey(pubKey string) bool {
+	pubKey = TrimPubKey(pubKey)
+	return lo.ContainsBy(g.PublicKeys, func(key string) bool {
+		return TrimPubKey(key) == pubKey
+	})
+}
+
+// GroupsOf returns sorted names of groups scoping the file, empty if the file is encrypted for all public keys
+func (r Registry) GroupsOf(relPath string) []string {
+	res := lo.Filter(lo.Keys(r.Groups), func(name string, _ int) bool {
+		return r.Groups[name].Matches(relPath)
+	})
+	sort.Strings(res)
+	return res
+}
+
+// GroupsOfKey returns sorted names of groups the public key belongs to
+func (r Registry) GroupsOfKey(pubKey string) []string {
+	res := lo.Filter(lo.Keys(r.Groups), func(name string, _ int) bool {
+		return r.Groups[name].HasPublicKey(pubKey)
+	})
+	sort.Strings(res)
+	return res
+}
+
+// Allows returns true if the file may be encrypted for the public key: the file is not scoped by any group
+// or the key belongs to one of the groups scoping it
+func (r Registry) Allows(relPath, pubKey string) bool {
+	groups := r.GroupsOf(relPath)
+	return len(groups) == 0 || lo.SomeBy(groups, func(name string) bool {
+		return r.Groups[name].HasPublicKey(pubKey)
+	})
+}
+
+// SetRecipientGroup replaces the group (or removes it if it has neither keys nor files) and re-encrypts secrets,
+// files current key cannot decrypt are re-encrypted for new recipients by members of their groups
+func (c *cryptor) SetRecipientGroup(name string, group RecipientGroup) error {
+	defer c.withWriteLock()()
+	if err := c.initData(); err != nil {
+		return err
+	}
+
+	groups := lo.Assign(c.secrets.Registry.Groups)
+	if len(group.PublicKeys) == 0 && len(group.Files) == 0 {
+		delete(groups, name)
+	} else {
+		groups[name] = group
+	}
+	registry := Registry{Files: c.secrets.Registry.Files, Groups: groups}
+	for _, relFilePath := range registry.Files {
+		if !lo.SomeBy(lo.Keys(c.secrets.Secrets), func(key string) bool { return registry.Allows(relFilePath, key) }) {
+			return errors.Errorf("secret file %q would not be decryptable by any allowed public key, add keys to group %q first", relFilePath, name)
+		}
+	}
+	c.secrets.Registry.Groups = lo.Ternary(len(groups) > 0, groups, nil)
+
+	if err := c.EncryptChanged(true, true); err != nil {
+		return errors.Wrapf(err, "failed to re-encrypt secrets for recipient group %q", name)
+	}
+	for _, relFilePath := range c.secrets.Registry.Files {
+		if !c.encryptedForAll(relFilePath, c.recipients(relFilePath)) {
+			c.consoleWriter.Println(color.YellowFmt("secret file %q is not encrypted for all its recipients yet, "+
+				"run `sc secrets hide` with a key which can decrypt it", relFilePath))
+		}
+	}
+	return c.MarshalSecretsFile()
+}
+
+// recipients returns normalized public keys the file must be encrypted for
+func (c *cryptor) recipients(relFilePath string) []string {
+	keys := lo.Uniq(append(lo.Keys(c.secrets.Secrets), TrimPubKey(c.currentPublicKey)))
+	res := lo.Filter(keys, func(key string, _ int) bool {
+		return c.secrets.Registry.Allows(relFilePath, key)
+	})
+	sort.Strings(res)
+	return res
+}
+
+// skipUndecryptable returns true if the file is encrypted, but not for the current key, and must be kept as is:
+// the key is not allowed to decrypt it or there is no revealed copy to encrypt it from
+func (c *cryptor) skipUndecryptable(relFilePath string) bool {
+	currentSecrets := c.secrets.Secrets[TrimPubKey(c.currentPublicKey)]
+	if len(currentSecrets.GetEncryptedContent(relFilePath)) > 0 || !c.encryptedForAny(relFilePath) {
+		return false
+	}
+	return !c.secrets.Registry.Allows(relFilePath, c.currentPublicKey) || !c.gitRepo.Exists(relFilePath)
+}
+
+// encryptedForAll returns true if the file is already encrypted for all the public keys
+func (c *cryptor) encryptedForAll(relFilePath string, publicKeys []string) bool {
+	return lo.EveryBy(publicKeys, func(key string) bool {
+		keySecrets := c.secrets.Secrets[key]
+		return len(keySecrets.GetEncryptedContent(relFilePath)) > 0
+	})
+}
+
+// encryptedForAny returns true if the file is encrypted for at least one public key
+func (c *cryptor) encryptedForAny(relFilePath string) bool {
+	return lo.SomeBy(lo.Values(c.secrets.Secrets), func(s EncryptedSecrets) bool {
+		return len(s.GetEncryptedContent(relFilePath)) > 0
+	})
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/secrets/ciphers"
+)
+
+func TestRegistryAllows(t *testing.T) {
+	RegisterTestingT(t)
+
+	registry := Registry{
+		Files: []string{".sc/stacks/common/secrets.yaml", ".sc/stacks/billing-prod/secrets.yaml"},
+		Groups: map[string]RecipientGroup{
+			"prod": {
+				PublicKeys: []string{"ssh-ed25519 AAAAci ci@prod", "ssh-rsa AAAAadmin"},
+				Files:      []string{".sc/stacks/*-prod/secrets.yaml"},
+			},
+			"admins": {
+				PublicKeys: []string{"ssh-rsa AAAAadmin admin@laptop"},
+				Files:      []string{".sc/stacks/*-prod/*"},
+			},
+		},
+	}
+
+	Expect(registry.GroupsOf(".sc/stacks/billing-prod/secrets.yaml")).To(Equal([]string{"admins", "prod"}))
+	Expect(registry.GroupsOf(".sc/stacks/common/secrets.yaml")).To(BeEmpty())
+	Expect(registry.GroupsOfKey("ssh-rsa AAAAadmin other@alias")).To(Equal([]string{"admins", "prod"}))
+
+	Expect(registry.Allows(".sc/stacks/common/secrets.yaml", "ssh-rsa AAAAdev")).To(BeTrue())
+	Expect(registry.Allows(".sc/stacks/billing-prod/secrets.yaml", "ssh-rsa AAAAdev")).To(BeFalse())
+	Expect(registry.Allows(".sc/stacks/billing-prod/secrets.yaml", "ssh-ed25519 AAAAci")).To(BeTrue())
+	Expect(registry.Allows(".sc/stacks/billing-prod/secrets.yaml", "ssh-rsa AAAAadmin")).To(BeTrue())
+}
+
+func TestSetRecipientGroup(t *testing.T) {
+	RegisterTestingT(t)
+	c, wd, cleanup := newTestCryptor(t)
+	defer cleanup()
+
+	_, otherPubKey, err := ciphers.GenerateEd25519KeyPair()
+	Expect(err).To(BeNil())
+	otherPubKeySSH, err := ciphers.MarshalEd25519PublicKey(otherPubKey)
+	Expect(err).To(BeNil())
+	otherKey := strings.TrimSpace(string(otherPubKeySSH))
+	currentKey := TrimPubKey(c.PublicKey())
+
+	Expect(c.AddFile("stacks/common/secrets.yaml")).To(Succeed())
+	Expect(c.AddFile("stacks/refapp/secrets.yaml")).To(Succeed())
+	Expect(c.AddPublicKey(otherKey)).To(Succeed())
+
+	encryptedFiles := func(pubKey string) []string {
+		files := c.GetSecretFiles().Secrets[pubKey].Files
+		res := make([]string, 0, len(files))
+		for _, f := range files {
+			res = append(res, f.Path)
+		}
+		return res
+	}
+	Expect(encryptedFiles(otherKey)).To(ConsistOf("stacks/common/secrets.yaml", "stacks/refapp/secrets.yaml"))
+
+	t.Run("rejects group no allowed key can decrypt", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		err := c.SetRecipientGroup("prod", RecipientGroup{PublicKeys: []string{"ssh-rsa AAAAunknown"}, Files: []string{"stacks/refapp/*"}})
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("would not be decryptable"))
+		Expect(c.GetSecretFiles().Registry.Groups).To(BeEmpty())
+	})
+
+	t.Run("files are encrypted only for recipients of their groups", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		Expect(c.SetRecipientGroup("prod", RecipientGroup{PublicKeys: []string{otherKey + " ci@prod"}, Files: []string{"stacks/refapp/*"}})).To(Succeed())
+
+		Expect(encryptedFiles(otherKey)).To(ConsistOf("stacks/common/secrets.yaml", "stacks/refapp/secrets.yaml"))
+		Expect(encryptedFiles(currentKey)).To(ConsistOf("stacks/common/secrets.yaml"))
+
+		store, err := os.ReadFile(path.Join(wd, api.ScConfigDirectory, EncryptedSecretFilesDataFileName))
+		Expect(err).To(BeNil())
+		Expect(string(store)).To(ContainSubstring("schemaVersion: 1"))
+
+		_, err = c.GetAndDecryptFileContent("stacks/refapp/secrets.yaml")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("restricted to recipient groups prod"))
+
+		// files current key cannot open are skipped
+		Expect(os.RemoveAll(path.Join(wd, "stacks/refapp/secrets.yaml"))).To(Succeed())
+		Expect(c.DecryptAll(true)).To(Succeed())
+		_, err = os.Stat(path.Join(wd, "stacks/refapp/secrets.yaml"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		// and kept as is when secrets are hidden
+		Expect(c.EncryptChanged(true, true)).To(Succeed())
+		Expect(encryptedFiles(otherKey)).To(ConsistOf("stacks/common/secrets.yaml", "stacks/refapp/secrets.yaml"))
+	})
+
+	t.Run("removed group makes store readable by older builds", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		Expect(c.SetRecipientGroup("prod", RecipientGroup{})).To(Succeed())
+		Expect(c.GetSecretFiles().Registry.Groups).To(BeEmpty())
+
+		store, err := os.ReadFile(path.Join(wd, api.ScConfigDirectory, EncryptedSecretFilesDataFileName))
+		Expect(err).To(BeNil())
+		Expect(string(store)).NotTo(ContainSubstring("schemaVersion"))
+	})
+}
@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+const (
+	KeyTypeRSA     = "rsa"
+	KeyTypeEd25519 = "ed25519"
+)
+
+// RevokedKey is a public key removed from recipients of secrets
+type RevokedKey struct {
+	PublicKey string   `json:"publicKey" yaml:"publicKey"`
+	Files     []string `json:"files" yaml:"files"`
+}
+
+type RotateParams struct {
+	RevokeKeys []string // public keys to remove, in addition to keys removed since the last rotation
+	KeyType    string   // type of the new key pair: rsa or ed25519 (default: type of the current key)
+	DryRun     bool     // only report affected files
+}
+
+// RotationReport lists secret files affected by the rotation
+type RotationReport struct {
+	Epoch        int           `json:"epoch" yaml:"epoch"`
+	Profile      string        `json:"profile" yaml:"profile"`
+	NewPublicKey string        `json:"newPublicKey,omitempty" yaml:"newPublicKey,omitempty"`
+	RevokedKeys  []string      `json:"revokedKeys" yaml:"revokedKeys"`
+	Files        []RotatedFile `json:"files" yaml:"files"`
+}
+
+type RotatedFile struct {
+	Path string `json:"path" yaml:"path"`
+	// ReEncrypted is false when the current key cannot decrypt the file, its ciphertext is kept for remaining recipients
+	ReEncrypted bool `json:"reEncrypted" yaml:"reEncrypted"`
+	// ExposedTo are revoked keys which could decrypt the file
+	ExposedTo []string `json:"exposedTo,omitempty" yaml:"exposedTo,omitempty"`
+	// ExposedValues are keys of `values` which should be rotated upstream, empty if the file is not a secrets descriptor
+	ExposedValues []string `json:"exposedValues,omitempty" yaml:"exposedValues,omitempty"`
+}
+
+// Exposed returns files which could be decrypted by revoked keys
+func (r *RotationReport) Exposed() []RotatedFile {
+	return lo.Filter(r.Files, func(f RotatedFile, _ int) bool {
+		return len(f.ExposedTo) > 0
+	})
+}
+
+// Rotate generates a new key pair for the current profile and re-encrypts all files the current key can decrypt
+// for the remaining recipients: the previous profile key and revoked keys are removed, key epoch is incremented.
+// Old ciphertexts stay in git history, so values readable by revoked keys are reported to be rotated upstream
+func (c *cryptor) Rotate(params RotateParams) (*RotationReport, error) {
+	defer c.withWriteLock()()
+
+	if err := c.initData(); err != nil {
+		return nil, err
+	}
+	currentKey := TrimPubKey(c.currentPublicKey)
+	keyType := params.KeyType
+	if keyType == "" {
+		keyType = lo.Ternary(strings.HasPrefix(currentKey, "ssh-ed25519"), KeyTypeEd25519, KeyTypeRSA)
+	}
+	if keyType != KeyTypeRSA && keyType != KeyTypeEd25519 {
+		return nil, errors.Errorf("unsupported key type %q, must be %q or %q", keyType, KeyTypeRSA, KeyTypeEd25519)
+	}
+
+	revoked := make(map[string][]string)
+	for _, revokedKey := range c.secrets.Registry.RevokedKeys {
+		revoked[revokedKey.PublicKey] = lo.Union(revoked[revokedKey.PublicKey], revokedKey.Files)
+	}
+	for _, pubKey := range params.RevokeKeys {
+		pubKey = TrimPubKey(pubKey)
+		if pubKey == currentKey {
+			return nil, errors.Errorf("public key %q of the current profile is replaced by rotation and cannot be revoked", pubKey)
+		}
+		keySecrets, found := c.secrets.Secrets[pubKey]
+		if _, alreadyRevoked := revoked[pubKey]; !found && !alreadyRevoked {
+			return nil, errors.Errorf("public key %q not found in secrets", pubKey)
+		}
+		revoked[pubKey] = lo.Union(revoked[pubKey], lo.Map(keySecrets.Files, func(f EncryptedSecretFile, _ int) string {
+			return f.Path
+		}))
+	}
+
+	contents, err := c.decryptRegisteredFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RotationReport{
+		Epoch:       c.secrets.Registry.KeyEpoch + 1,
+		Profile:     c.profile,
+		RevokedKeys: lo.Keys(revoked),
+	}
+	sort.Strings(report.RevokedKeys)
+	for _, relFilePath := range c.secrets.Registry.Files {
+		content, decrypted := contents[relFilePath]
+		file := RotatedFile{
+			Path:        relFilePath,
+			ReEncrypted: decrypted,
+			ExposedTo: lo.Filter(report.RevokedKeys, func(key string, _ int) bool {
+				return lo.Contains(revoked[key], relFilePath)
+			}),
+		}
+		if len(file.ExposedTo) > 0 && decrypted {
+			// files which are not secrets descriptors are reported as a whole
+			file.ExposedValues, _ = SecretValueKeys(content)
+		}
+		report.Files = append(report.Files, file)
+	}
+	if params.DryRun {
+		return report, nil
+	}
+
+	if c.profile == "" {
+		return nil, errors.New("profile is not configured, the new key cannot be saved")
+	}
+	if c.keyFromSigner {
+		return nil, errors.New("key pair of the profile is derived from ssh-agent key, rotate it by configuring another agent key")
+	}
+	if os.Getenv(api.ScConfigEnvVariable) != "" {
+		return nil, errors.Errorf("profile config is read from %q env variable and cannot be updated with the new key", api.ScConfigEnvVariable)
+	}
+	// profile config is read before anything is changed, so that the rest of its configuration is kept
+	profileCfg, err := api.ReadConfigFile(c.workDir, c.profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file of profile %q", c.profile)
+	}
+	generate := lo.Ternary(keyType == KeyTypeEd25519, generateEd25519KeyPair, generateRSAKeyPair)
+	privKey, pubKey, err := generate()
+	if err != nil {
+		return nil, err
+	}
+
+	removedKeys := append([]string{currentKey}, report.RevokedKeys...)
+	for _, key := range removedKeys {
+		delete(c.secrets.Secrets, key)
+	}
+	for name, group := range c.secrets.Registry.Groups {
+		wasMember := group.HasPublicKey(currentKey)
+		group.PublicKeys = lo.Filter(group.PublicKeys, func(key string, _ int) bool {
+			return !lo.Contains(removedKeys, TrimPubKey(key))
+		})
+		if wasMember {
+			group.PublicKeys = append(group.PublicKeys, pubKey)
+		}
+		c.secrets.Registry.Groups[name] = group
+	}
+	c.currentPrivateKey, c.currentPublicKey, c.privateKeyPassphrase = privKey, pubKey, ""
+	c.secrets.Secrets[pubKey] = EncryptedSecrets{}
+
+	acceptedChanges := make(map[string]bool)
+	for _, relFilePath := range c.secrets.Registry.Files {
+		if content, decrypted := contents[relFilePath]; decrypted {
+			if err := c.encryptChangedData(relFilePath, content, true, true, acceptedChanges); err != nil {
+				return nil, errors.Wrapf(err, "failed to re-encrypt secret file %q", relFilePath)
+			}
+		}
+	}
+	c.secrets.Registry.KeyEpoch = report.Epoch
+	c.secrets.Registry.RevokedKeys = nil
+
+	// the new key is saved before secrets are re-encrypted for it, the previous profile config is backed up
+	// until secrets file is written, so that secrets are always readable by one of the keys
+	backupPath, err := c.writeRotatedProfileConfig(profileCfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.MarshalSecretsFile(); err != nil {
+		if restoreErr := os.Rename(backupPath, api.ConfigFilePath(c.workDir, c.profile)); restoreErr != nil {
+			return nil, errors.Wrapf(err, "failed to marshal secrets file after rotation, previous profile config is kept in %q", backupPath)
+		}
+		return nil, errors.Wrapf(err, "failed to marshal secrets file after rotation, previous profile config is restored")
+	}
+	if err := os.Remove(backupPath); err != nil {
+		return nil, errors.Wrapf(err, "failed to remove backup of profile config %q", backupPath)
+	}
+	report.NewPublicKey = pubKey
+	return report, nil
+}
+
+// writeRotatedProfileConfig replaces keys of the current profile keeping the rest of its configuration,
+// previous config file is backed up (as a profile config, so that it's git-ignored the same way) and its path is returned
+func (c *cryptor) writeRotatedProfileConfig(cfg *api.ConfigFile) (string, error) {
+	profilePath := api.ConfigFilePath(c.workDir, c.profile)
+	backupPath := api.ConfigFilePath(c.workDir, c.profile+".pre-rotate")
+	previous, err := os.ReadFile(profilePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read config file of profile %q", c.profile)
+	}
+	if err := os.WriteFile(backupPath, previous, 0o600); err != nil {
+		return "", errors.Wrapf(err, "failed to back up config file of profile %q", c.profile)
+	}
+	rotated := *cfg
+	rotated.PrivateKeyPath, rotated.PublicKeyPath, rotated.PrivateKeyPassword = "", "", ""
+	rotated.PrivateKey, rotated.PublicKey = c.currentPrivateKey, c.currentPublicKey
+	if err := rotated.WriteConfigFile(c.workDir, c.profile); err != nil {
+		_ = os.Remove(backupPath)
+		return "", errors.Wrapf(err, "failed to write config file of profile %q", c.profile)
+	}
+	return backupPath, nil
+}
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/secrets/ciphers"
+	"github.com/simple-container-com/api/pkg/util/test"
+)
+
+func TestRotate(t *testing.T) {
+	RegisterTestingT(t)
+	c, wd, cleanup := newTestCryptor(t)
+	defer cleanup()
+
+	_, leftPubKey, err := ciphers.GenerateEd25519KeyPair()
+	Expect(err).To(BeNil())
+	leftPubKeySSH, err := ciphers.MarshalEd25519PublicKey(leftPubKey)
+	Expect(err).To(BeNil())
+	leftKey := strings.TrimSpace(string(leftPubKeySSH))
+	oldKey := TrimPubKey(c.PublicKey())
+
+	original, err := os.ReadFile(path.Join(wd, "stacks/common/secrets.yaml"))
+	Expect(err).To(BeNil())
+	Expect(c.AddFile("stacks/common/secrets.yaml")).To(Succeed())
+	Expect(c.AddPublicKey(leftKey)).To(Succeed())
+	Expect(c.RemovePublicKey(leftKey)).To(Succeed())
+	Expect(c.AddFile("stacks/refapp/secrets.yaml")).To(Succeed())
+	Expect(c.GetSecretFiles().Registry.RevokedKeys).To(Equal([]RevokedKey{{PublicKey: leftKey, Files: []string{"stacks/common/secrets.yaml"}}}))
+
+	storePath := path.Join(wd, api.ScConfigDirectory, EncryptedSecretFilesDataFileName)
+
+	t.Run("dry run only reports affected files", func(t *testing.T) {
+		RegisterTestingT(t)
+		store, err := os.ReadFile(storePath)
+		Expect(err).To(BeNil())
+
+		report, err := c.Rotate(RotateParams{DryRun: true})
+		Expect(err).To(BeNil())
+		Expect(report.Epoch).To(Equal(1))
+		Expect(report.NewPublicKey).To(BeEmpty())
+		Expect(report.RevokedKeys).To(Equal([]string{leftKey}))
+		Expect(report.Files).To(HaveLen(2))
+		Expect(report.Exposed()).To(Equal([]RotatedFile{{
+			Path:          "stacks/common/secrets.yaml",
+			ReEncrypted:   true,
+			ExposedTo:     []string{leftKey},
+			ExposedValues: []string{"CLOUDFLARE_API_TOKEN", "GITHUB_TOKEN", "MONGODB_ATLAS_PRIVATE_KEY", "MONGODB_ATLAS_PUBLIC_KEY"},
+		}}))
+
+		storeAfter, err := os.ReadFile(storePath)
+		Expect(err).To(BeNil())
+		Expect(storeAfter).To(Equal(store))
+		Expect(TrimPubKey(c.PublicKey())).To(Equal(oldKey))
+	})
+
+	t.Run("rejects unknown key", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := c.Rotate(RotateParams{RevokeKeys: []string{"ssh-rsa AAAAunknown"}, DryRun: true})
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("not found in secrets"))
+	})
+
+	t.Run("replaces profile key and re-encrypts secrets", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		report, err := c.Rotate(RotateParams{KeyType: KeyTypeEd25519})
+		Expect(err).To(BeNil())
+		Expect(report.NewPublicKey).To(HavePrefix("ssh-ed25519 "))
+		Expect(report.Exposed()).To(HaveLen(1))
+
+		files := c.GetSecretFiles()
+		Expect(files.Registry.KeyEpoch).To(Equal(1))
+		Expect(files.Registry.RevokedKeys).To(BeEmpty())
+		Expect(c.GetKnownPublicKeys()).To(ConsistOf(report.NewPublicKey))
+
+		cfg, err := api.ReadConfigFile(wd, "local-key-files")
+		Expect(err).To(BeNil())
+		Expect(cfg.PublicKey).To(Equal(report.NewPublicKey))
+		Expect(cfg.PrivateKeyPath).To(BeEmpty())
+		Expect(api.ConfigFilePath(wd, "local-key-files.pre-rotate")).ToNot(BeAnExistingFile())
+
+		// secrets are readable with the new profile key
+		rotated, err := NewCryptor(wd,
+			withGitDir("gitdir"),
+			WithKeysFromScConfig("local-key-files"),
+			WithConsoleReader(&test.ConsoleReaderMock{}),
+			WithConfirmationReader(&test.ConsoleReaderMock{}),
+		)
+		Expect(err).To(BeNil())
+		Expect(rotated.ReadSecretFiles()).To(Succeed())
+		content, err := rotated.GetAndDecryptFileContent("stacks/common/secrets.yaml")
+		Expect(err).To(BeNil())
+		Expect(content).To(Equal(original))
+	})
+}
+
+func TestRotate_ProfileConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("fails when profile config cannot be read", func(t *testing.T) {
+		RegisterTestingT(t)
+		c, wd, cleanup := newTestCryptor(t)
+		defer cleanup()
+		Expect(c.AddFile("stacks/common/secrets.yaml")).To(Succeed())
+		storePath := path.Join(wd, api.ScConfigDirectory, EncryptedSecretFilesDataFileName)
+		store, err := os.ReadFile(storePath)
+		Expect(err).To(BeNil())
+
+		Expect(os.WriteFile(api.ConfigFilePath(wd, "local-key-files"), []byte("privateKey: [\n"), 0o644)).To(Succeed())
+		_, err = c.Rotate(RotateParams{})
+		Expect(err).To(MatchError(ContainSubstring(`failed to read config file of profile "local-key-files"`)))
+
+		storeAfter, err := os.ReadFile(storePath)
+		Expect(err).To(BeNil())
+		Expect(storeAfter).To(Equal(store))
+	})
+
+	t.Run("restores profile config when secrets file cannot be written", func(t *testing.T) {
+		RegisterTestingT(t)
+		c, wd, cleanup := newTestCryptor(t)
+		defer cleanup()
+		Expect(c.AddFile("stacks/common/secrets.yaml")).To(Succeed())
+		profilePath := api.ConfigFilePath(wd, "local-key-files")
+		profile, err := os.ReadFile(profilePath)
+		Expect(err).To(BeNil())
+
+		// secrets file cannot be written over a directory
+		storePath := path.Join(wd, api.ScConfigDirectory, EncryptedSecretFilesDataFileName)
+		Expect(os.Remove(storePath)).To(Succeed())
+		Expect(os.Mkdir(storePath, 0o755)).To(Succeed())
+
+		_, err = c.Rotate(RotateParams{})
+		Expect(err).To(MatchError(ContainSubstring("previous profile config is restored")))
+		profileAfter, err := os.ReadFile(profilePath)
+		Expect(err).To(BeNil())
+		Expect(profileAfter).To(Equal(profile))
+		Expect(api.ConfigFilePath(wd, "local-key-files.pre-rotate")).ToNot(BeAnExistingFile())
+	})
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// sealedDataKeySize is the size of AES-256 key data is sealed with
+const sealedDataKeySize = 32
+
+// SealedData is data encrypted with a random AES-256-GCM key, which is in turn encrypted for every public key
+// allowed to decrypt the secret files the data is derived from, so that data of any size can be opened by anyone
+// who can reveal those files
+type SealedData struct {
+	// Keys are data keys encrypted with the cipher of the secrets store by public keys
+	Keys  map[string][]string `json:"keys" yaml:"keys"`
+	Nonce []byte              `json:"nonce" yaml:"nonce"`
+	Data  []byte              `json:"data" yaml:"data"`
+}
+
+// Seal encrypts data for public keys allowed to decrypt all of the secret files (see Registry.Allows),
+// for all public keys if no files are given
+func (c *cryptor) Seal(data []byte, relFilePaths ...string) (*SealedData, error) {
+	defer c.withReadLock()()
+
+	if err := c.initData(); err != nil {
+		return nil, err
+	}
+	publicKeys := lo.Filter(lo.Uniq(append(lo.Keys(c.secrets.Secrets), TrimPubKey(c.currentPublicKey))), func(key string, _ int) bool {
+		return key != "" && lo.EveryBy(relFilePaths, func(relFilePath string) bool {
+			return c.secrets.Registry.Allows(relFilePath, key)
+		})
+	})
+	if len(publicKeys) == 0 {
+		return nil, errors.Errorf("no public key is allowed to decrypt all of %q", relFilePaths)
+	}
+	sort.Strings(publicKeys)
+
+	dataKey := make([]byte, sealedDataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, errors.Wrapf(err, "failed to generate data key")
+	}
+	gcm, err := sealedDataCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	res := &SealedData{
+		Keys:  make(map[string][]string),
+		Nonce: make([]byte, gcm.NonceSize()),
+	}
+	if _, err := rand.Read(res.Nonce); err != nil {
+		return nil, errors.Wrapf(err, "failed to generate nonce")
+	}
+	res.Data = gcm.Seal(nil, res.Nonce, data, nil)
+
+	for _, publicKey := range publicKeys {
+		encryptedKey, err := c.encryptSecretData(publicKey, "sealed data key", dataKey)
+		if err != nil {
+			return nil, err
+		}
+		res.Keys[publicKey] = encryptedKey
+	}
+	return res, nil
+}
+
+// Open decrypts sealed data with the current private key
+func (c *cryptor) Open(sealed SealedData) ([]byte, error) {
+	defer c.withReadLock()()
+
+	if err := c.initData(); err != nil {
+		return nil, err
+	}
+	encryptedKey, found := sealed.Keys[TrimPubKey(c.currentPublicKey)]
+	if !found {
+		return nil, errors.Errorf("data is not sealed for the current public key")
+	}
+	dataKey, err := c.decryptSecretData(encryptedKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt data key")
+	}
+	gcm, err := sealedDataCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return nil, errors.Errorf("invalid nonce size %d", len(sealed.Nonce))
+	}
+	res, err := gcm.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt sealed data")
+	}
+	return res, nil
+}
+
+func sealedDataCipher(dataKey []byte) (cipher.AEAD, error) {
+	if len(dataKey) != sealedDataKeySize {
+		return nil, errors.Errorf("invalid data key size %d", len(dataKey))
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to init cipher")
+	}
+	return cipher.NewGCM(block)
+}
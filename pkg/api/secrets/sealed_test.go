@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api/secrets/ciphers"
+)
+
+func TestSeal(t *testing.T) {
+	RegisterTestingT(t)
+	c, _, cleanup := newTestCryptor(t)
+	defer cleanup()
+
+	_, teammatePubKey, err := ciphers.GenerateEd25519KeyPair()
+	Expect(err).To(BeNil())
+	teammatePubKeySSH, err := ciphers.MarshalEd25519PublicKey(teammatePubKey)
+	Expect(err).To(BeNil())
+	teammateKey := TrimPubKey(string(teammatePubKeySSH))
+	Expect(c.AddPublicKey(teammateKey)).To(Succeed())
+
+	data := bytes.Repeat([]byte(`{"plaintext":"secret-value"}`), 10000)
+	sealed, err := c.Seal(data)
+	Expect(err).To(BeNil())
+	Expect(sealed.Keys).To(HaveKey(TrimPubKey(c.PublicKey())))
+	Expect(sealed.Keys).To(HaveKey(teammateKey))
+	Expect(bytes.Contains(sealed.Data, []byte("secret-value"))).To(BeFalse())
+
+	opened, err := c.Open(*sealed)
+	Expect(err).To(BeNil())
+	Expect(opened).To(Equal(data))
+
+	tampered := *sealed
+	tampered.Data = append([]byte{sealed.Data[0] ^ 1}, sealed.Data[1:]...)
+	_, err = c.Open(tampered)
+	Expect(err).To(MatchError(ContainSubstring("failed to decrypt sealed data")))
+
+	foreign := *sealed
+	foreign.Keys = map[string][]string{teammateKey: sealed.Keys[teammateKey]}
+	_, err = c.Open(foreign)
+	Expect(err).To(MatchError(ContainSubstring("data is not sealed for the current public key")))
+}
+
+func TestSeal_RecipientGroups(t *testing.T) {
+	RegisterTestingT(t)
+	c, _, cleanup := newTestCryptor(t)
+	defer cleanup()
+
+	_, teammatePubKey, err := ciphers.GenerateEd25519KeyPair()
+	Expect(err).To(BeNil())
+	teammatePubKeySSH, err := ciphers.MarshalEd25519PublicKey(teammatePubKey)
+	Expect(err).To(BeNil())
+	teammateKey := TrimPubKey(string(teammatePubKeySSH))
+	currentKey := TrimPubKey(c.PublicKey())
+	Expect(c.AddPublicKey(teammateKey)).To(Succeed())
+	Expect(c.SetRecipientGroup("prod", RecipientGroup{PublicKeys: []string{currentKey}, Files: []string{"stacks/*-prod/*"}})).To(Succeed())
+	Expect(c.SetRecipientGroup("staging", RecipientGroup{PublicKeys: []string{teammateKey}, Files: []string{"stacks/*-staging/*"}})).To(Succeed())
+
+	data := []byte(`{"plaintext":"secret-value"}`)
+	for _, tc := range []struct {
+		name     string
+		files    []string
+		wantKeys []string
+		wantErr  string
+	}{
+		{name: "no files", wantKeys: []string{currentKey, teammateKey}},
+		{name: "unscoped file", files: []string{"stacks/common/secrets.yaml"}, wantKeys: []string{currentKey, teammateKey}},
+		{name: "scoped file", files: []string{"stacks/devops-prod/secrets.yaml"}, wantKeys: []string{currentKey}},
+		{name: "all files must be allowed", files: []string{"stacks/common/secrets.yaml", "stacks/devops-staging/secrets.yaml"}, wantKeys: []string{teammateKey}},
+		{name: "no key is allowed", files: []string{"stacks/devops-prod/secrets.yaml", "stacks/devops-staging/secrets.yaml"}, wantErr: "no public key is allowed"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+			sealed, err := c.Seal(data, tc.files...)
+			if tc.wantErr != "" {
+				Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				return
+			}
+			Expect(err).To(BeNil())
+			Expect(lo.Keys(sealed.Keys)).To(ConsistOf(tc.wantKeys))
+		})
+	}
+}
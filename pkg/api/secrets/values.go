@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+const secretValuesKey = "values"
+
+// StackSecretsFile returns path of secrets descriptor of the stack relative to repository root
+func StackSecretsFile(stacksDir, stackName string) string {
+	return path.Join(stacksDir, stackName, api.SecretsDescriptorFileName)
+}
+
+// UpdateSecretFile decrypts the secret file in memory, applies update to its content and encrypts the result.
+// Plaintext file is never created, but the copy revealed earlier is kept in sync unless it has changes which are not hidden yet
+func UpdateSecretFile(c Cryptor, relPath string, update func(content []byte) ([]byte, error)) error {
+	var content []byte
+	if lo.Contains(c.GetSecretFiles().Registry.Files, relPath) {
+		var err error
+		if content, err = c.GetAndDecryptFileContent(relPath); err != nil {
+			return err
+		}
+	}
+	revealed := c.GitRepo().Exists(relPath)
+	if revealed {
+		if revealedContent, err := readRevealedFile(c, relPath); err != nil {
+			return err
+		} else if !bytes.Equal(revealedContent, content) {
+			return errors.Errorf("revealed secret file %q has changes which are not hidden yet, run `sc secrets hide` first", relPath)
+		}
+	}
+	updated, err := update(content)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(updated, content) {
+		return nil
+	}
+	if err := c.EncryptContent(relPath, updated); err != nil {
+		return err
+	}
+	if revealed {
+		return writeRevealedFile(c, relPath, updated)
+	}
+	return nil
+}
+
+// SecretValueKeys returns sorted keys of `values` of secrets descriptor
+func SecretValueKeys(content []byte) ([]string, error) {
+	values, err := secretValuesNode(content)
+	if err != nil || values == nil {
+		return nil, err
+	}
+	res := lo.Keys(secretValueEntries(values))
+	sort.Strings(res)
+	return res, nil
+}
+
+// GetSecretValue returns value of the key in `values` of secrets descriptor (aliases are followed)
+func GetSecretValue(content []byte, key string) (string, bool, error) {
+	values, err := secretValuesNode(content)
+	if err != nil || values == nil {
+		return "", false, err
+	}
+	value, found := secretValueEntries(values)[key]
+	if !found {
+		return "", false, nil
+	}
+	if value.Kind != yaml.ScalarNode {
+		return "", false, errors.Errorf("value of %q is not a string", key)
+	}
+	return value.Value, true, nil
+}
+
+// SetSecretValue sets value of the key in `values` of secrets descriptor keeping comments and anchors of the document
+func SetSecretValue(content []byte, key, value string) ([]byte, error) {
+	doc, err := parseSecretsDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+	_, values := mappingEntry(root, secretValuesKey)
+	if values == nil || values.Kind != yaml.MappingNode {
+		if values != nil && !(values.Kind == yaml.ScalarNode && values.Tag == "!!null") {
+			return nil, errors.Errorf("%q of secrets descriptor is not a map", secretValuesKey)
+		}
+		newValues := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if values != nil {
+			*values = *newValues
+		} else {
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: secretValuesKey}, newValues)
+		}
+		_, values = mappingEntry(root, secretValuesKey)
+	}
+	newValue := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if strings.Contains(value, "\n") {
+		newValue.Style = yaml.LiteralStyle
+	}
+	if _, existing := mappingEntry(values, key); existing == nil {
+		values.Content = append(values.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, newValue)
+	} else if existing.Kind == yaml.AliasNode {
+		// replace alias with the value, the anchored value is used elsewhere
+		*existing = *newValue
+	} else {
+		// keep anchor and comments of the existing value
+		newValue.Anchor = existing.Anchor
+		newValue.HeadComment, newValue.LineComment, newValue.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+		*existing = *newValue
+	}
+	return encodeSecretsDocument(doc)
+}
+
+// UnsetSecretValue removes the key from `values` of secrets descriptor, returns false if key is not found
+func UnsetSecretValue(content []byte, key string) ([]byte, bool, error) {
+	doc, err := parseSecretsDocument(content)
+	if err != nil {
+		return nil, false, err
+	}
+	_, values := mappingEntry(doc.Content[0], secretValuesKey)
+	if values == nil || values.Kind != yaml.MappingNode {
+		return content, false, nil
+	}
+	for i := 0; i < len(values.Content); i += 2 {
+		if values.Content[i].Value != key {
+			continue
+		}
+		if anchor := values.Content[i+1].Anchor; anchor != "" && hasAlias(doc, anchor) {
+			return nil, false, errors.Errorf("value of %q is referenced as *%s elsewhere in the file", key, anchor)
+		}
+		values.Content = append(values.Content[:i], values.Content[i+2:]...)
+		res, err := encodeSecretsDocument(doc)
+		return res, true, err
+	}
+	return content, false, nil
+}
+
+// secretKeyValues returns `values` of secrets descriptor or values of KEY=VALUE lines of other secret files by their keys
+func secretKeyValues(content []byte) map[string]string {
+	res := make(map[string]string)
+	if keys, err := SecretValueKeys(content); err == nil && len(keys) > 0 {
+		for _, key := range keys {
+			if value, found, err := GetSecretValue(content, key); err == nil && found {
+				res[key] = value
+			}
+		}
+		return res
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if key, value, found := strings.Cut(strings.TrimSpace(line), "="); found && !strings.HasPrefix(key, "#") {
+			res[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+		}
+	}
+	return res
+}
+
+func secretValuesNode(content []byte) (*yaml.Node, error) {
+	doc, err := parseSecretsDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	_, values := mappingEntry(doc.Content[0], secretValuesKey)
+	if values == nil || values.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	return values, nil
+}
+
+func parseSecretsDocument(content []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse secrets descriptor")
+	}
+	if doc.Kind == 0 {
+		// empty file
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.Errorf("secrets descriptor is not a map")
+	}
+	return &doc, nil
+}
+
+func encodeSecretsDocument(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal secrets descriptor")
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal secrets descriptor")
+	}
+	return buf.Bytes(), nil
+}
+
+// secretValueEntries returns values of the mapping node by keys, following aliases and merge keys (<<)
+func secretValueEntries(mapping *yaml.Node) map[string]*yaml.Node {
+	res := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i].Value, resolveAlias(mapping.Content[i+1])
+		if key != "<<" {
+			continue
+		}
+		merged := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			merged = value.Content
+		}
+		for _, m := range merged {
+			if m = resolveAlias(m); m.Kind != yaml.MappingNode {
+				continue
+			}
+			for k, v := range secretValueEntries(m) {
+				if _, found := res[k]; !found {
+					res[k] = v
+				}
+			}
+		}
+	}
+	// explicit keys override merged ones
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if key := mapping.Content[i].Value; key != "<<" {
+			res[key] = resolveAlias(mapping.Content[i+1])
+		}
+	}
+	return res
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// mappingEntry returns key and value nodes of the mapping node
+func mappingEntry(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func hasAlias(node *yaml.Node, anchor string) bool {
+	if node.Kind == yaml.AliasNode && node.Value == anchor {
+		return true
+	}
+	for _, child := range node.Content {
+		if hasAlias(child, anchor) {
+			return true
+		}
+	}
+	return false
+}
+
+func readRevealedFile(c Cryptor, relPath string) ([]byte, error) {
+	file, err := c.GitRepo().OpenFile(relPath, os.O_RDONLY, fs.ModePerm)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open revealed secret file %q", relPath)
+	}
+	defer func() { _ = file.Close() }()
+	return io.ReadAll(file)
+}
+
+func writeRevealedFile(c Cryptor, relPath string, content []byte) error {
+	file, err := c.GitRepo().OpenFile(relPath, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, fs.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open revealed secret file %q", relPath)
+	}
+	defer func() { _ = file.Close() }()
+	if _, err := file.Write(content); err != nil {
+		return errors.Wrapf(err, "failed to write revealed secret file %q", relPath)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package secrets
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/simple-container-com/api/pkg/api/tests/testutil"
+	"github.com/simple-container-com/api/pkg/util/test"
+)
+
+const testSecretsDescriptor = `# secrets of the stack
+schemaVersion: 1.0
+defaults: &defaults
+  SHARED_TOKEN: shared # shared between stacks
+values:
+  <<: *defaults
+  # database password
+  DB_PASSWORD: &db old-password # rotated monthly
+  DB_PASSWORD_COPY: *db
+  API_TOKEN: token
+`
+
+func TestSecretValueKeys(t *testing.T) {
+	RegisterTestingT(t)
+
+	keys, err := SecretValueKeys([]byte(testSecretsDescriptor))
+	Expect(err).To(BeNil())
+	Expect(keys).To(Equal([]string{"API_TOKEN", "DB_PASSWORD", "DB_PASSWORD_COPY", "SHARED_TOKEN"}))
+
+	keys, err = SecretValueKeys(nil)
+	Expect(err).To(BeNil())
+	Expect(keys).To(BeEmpty())
+
+	_, err = SecretValueKeys([]byte("- not a map"))
+	Expect(err).NotTo(BeNil())
+}
+
+func TestGetSecretValue(t *testing.T) {
+	RegisterTestingT(t)
+
+	content := []byte(testSecretsDescriptor)
+	for key, expected := range map[string]string{
+		"DB_PASSWORD":      "old-password",
+		"DB_PASSWORD_COPY": "old-password",
+		"SHARED_TOKEN":     "shared",
+	} {
+		value, found, err := GetSecretValue(content, key)
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue(), key)
+		Expect(value).To(Equal(expected), key)
+	}
+
+	_, found, err := GetSecretValue(content, "MISSING")
+	Expect(err).To(BeNil())
+	Expect(found).To(BeFalse())
+}
+
+func TestSetSecretValue(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("keeps comments and anchors", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		res, err := SetSecretValue([]byte(testSecretsDescriptor), "DB_PASSWORD", "new-password")
+		Expect(err).To(BeNil())
+		Expect(string(res)).To(ContainSubstring("# secrets of the stack"))
+		Expect(string(res)).To(ContainSubstring("# database password"))
+		Expect(string(res)).To(ContainSubstring("DB_PASSWORD: &db new-password # rotated monthly"))
+		Expect(string(res)).To(ContainSubstring("<<: *defaults"))
+
+		value, _, err := GetSecretValue(res, "DB_PASSWORD_COPY")
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal("new-password"))
+	})
+
+	t.Run("replaces alias with value", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		res, err := SetSecretValue([]byte(testSecretsDescriptor), "DB_PASSWORD_COPY", "copy")
+		Expect(err).To(BeNil())
+		value, _, _ := GetSecretValue(res, "DB_PASSWORD")
+		Expect(value).To(Equal("old-password"))
+		value, _, _ = GetSecretValue(res, "DB_PASSWORD_COPY")
+		Expect(value).To(Equal("copy"))
+	})
+
+	t.Run("overrides merged value", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		res, err := SetSecretValue([]byte(testSecretsDescriptor), "SHARED_TOKEN", "own")
+		Expect(err).To(BeNil())
+		Expect(string(res)).To(ContainSubstring("SHARED_TOKEN: shared # shared between stacks"))
+		value, _, _ := GetSecretValue(res, "SHARED_TOKEN")
+		Expect(value).To(Equal("own"))
+	})
+
+	t.Run("adds values to empty file", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		res, err := SetSecretValue(nil, "CERT", "line1\nline2\n")
+		Expect(err).To(BeNil())
+		Expect(string(res)).To(Equal("values:\n  CERT: |\n    line1\n    line2\n"))
+
+		res, err = SetSecretValue([]byte("schemaVersion: 1.0\nvalues:\n"), "KEY", "value")
+		Expect(err).To(BeNil())
+		Expect(string(res)).To(Equal("schemaVersion: 1.0\nvalues:\n  KEY: value\n"))
+	})
+}
+
+func TestUnsetSecretValue(t *testing.T) {
+	RegisterTestingT(t)
+
+	res, found, err := UnsetSecretValue([]byte(testSecretsDescriptor), "API_TOKEN")
+	Expect(err).To(BeNil())
+	Expect(found).To(BeTrue())
+	Expect(string(res)).NotTo(ContainSubstring("API_TOKEN"))
+	Expect(string(res)).To(ContainSubstring("# database password"))
+
+	_, found, err = UnsetSecretValue([]byte(testSecretsDescriptor), "MISSING")
+	Expect(err).To(BeNil())
+	Expect(found).To(BeFalse())
+
+	_, _, err = UnsetSecretValue([]byte(testSecretsDescriptor), "DB_PASSWORD")
+	Expect(err).NotTo(BeNil())
+	Expect(err.Error()).To(ContainSubstring("referenced as *db"))
+}
+
+func TestUpdateSecretFile(t *testing.T) {
+	RegisterTestingT(t)
+
+	workDir, cleanup, err := testutil.CopyTempProject("testdata/repo")
+	defer cleanup()
+	Expect(err).To(BeNil())
+
+	consoleWriterMock := &test.ConsoleWriterMock{}
+	consoleWriterMock.On("Println", mock.Anything).Return()
+	consoleWriterMock.On("Println", mock.Anything, mock.Anything).Return()
+
+	got, err := NewCryptor(workDir,
+		withGitDir("gitdir"),
+		WithKeysFromScConfig("local-key-files"),
+		WithConsoleReader(&test.ConsoleReaderMock{}),
+		WithConfirmationReader(&test.ConsoleReaderMock{}),
+	)
+	Expect(err).To(BeNil())
+	got.(*cryptor).consoleWriter = consoleWriterMock
+
+	setToken := func(value string) func([]byte) ([]byte, error) {
+		return func(content []byte) ([]byte, error) {
+			return SetSecretValue(content, "GITHUB_TOKEN", value)
+		}
+	}
+
+	t.Run("revealed file is kept in sync", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		relPath := "stacks/common/secrets.yaml"
+		Expect(got.AddFile(relPath)).To(BeNil())
+		Expect(got.EncryptChanged(false, true)).To(BeNil())
+
+		Expect(UpdateSecretFile(got, relPath, setToken("new-token"))).To(BeNil())
+
+		revealed, err := os.ReadFile(path.Join(workDir, relPath))
+		Expect(err).To(BeNil())
+		Expect(string(revealed)).To(ContainSubstring("GITHUB_TOKEN: new-token"))
+		Expect(string(revealed)).To(ContainSubstring("# Only encrypted version of this file should be committed to the repo"))
+		content, err := got.GetAndDecryptFileContent(relPath)
+		Expect(err).To(BeNil())
+		Expect(content).To(Equal(revealed))
+
+		// changes of revealed file which are not hidden yet must not be lost
+		Expect(os.WriteFile(path.Join(workDir, relPath), append(revealed, []byte("# local change\n")...), 0o644)).To(Succeed())
+		err = UpdateSecretFile(got, relPath, setToken("other-token"))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("not hidden yet"))
+	})
+
+	t.Run("new file is never written to disk", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		relPath := StackSecretsFile("stacks", "billing")
+		Expect(UpdateSecretFile(got, relPath, setToken("billing-token"))).To(BeNil())
+
+		_, err := os.Stat(path.Join(workDir, relPath))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		Expect(got.GetSecretFiles().Registry.Files).To(ContainElement(relPath))
+
+		content, err := got.GetAndDecryptFileContent(relPath)
+		Expect(err).To(BeNil())
+		Expect(string(content)).To(Equal("values:\n  GITHUB_TOKEN: billing-token\n"))
+	})
+}
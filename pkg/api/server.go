@@ -19,17 +19,21 @@ type ProvisionParams struct {
 	SkipPreview  bool     `json:"skipPreview" yaml:"skipPreview"`
 	DetailedDiff bool     `json:"detailedDiff" yaml:"detailedDiff"` // Enable detailed diff output for granular change visibility
 	Timeouts     Timeouts `json:",inline" yaml:",inline"`
+	// OverridePolicies are names of policies which violations are ignored
+	OverridePolicies []string `json:"overridePolicies,omitempty" yaml:"overridePolicies,omitempty"`
 }
 
 // ServerDescriptor describes the server schema
 type ServerDescriptor struct {
-	SchemaVersion string                        `json:"schemaVersion" yaml:"schemaVersion"`
-	Provisioner   ProvisionerDescriptor         `json:"provisioner" yaml:"provisioner"`
-	Secrets       SecretsConfigDescriptor       `json:"secrets" yaml:"secrets"`
-	CiCd          CiCdDescriptor                `json:"cicd" yaml:"cicd"`
-	Templates     map[string]StackDescriptor    `json:"templates" yaml:"templates"`
-	Resources     PerStackResourcesDescriptor   `json:"resources" yaml:"resources"`
-	Variables     map[string]VariableDescriptor `json:"variables" yaml:"variables"`
+	SchemaVersion string                            `json:"schemaVersion" yaml:"schemaVersion"`
+	Provisioner   ProvisionerDescriptor             `json:"provisioner" yaml:"provisioner"`
+	Secrets       SecretsConfigDescriptor           `json:"secrets" yaml:"secrets"`
+	SecretSources map[string]SecretSourceDescriptor `json:"secretSources,omitempty" yaml:"secretSources,omitempty"`
+	CiCd          CiCdDescriptor                    `json:"cicd" yaml:"cicd"`
+	Templates     map[string]StackDescriptor        `json:"templates" yaml:"templates"`
+	Resources     PerStackResourcesDescriptor       `json:"resources" yaml:"resources"`
+	Variables     map[string]VariableDescriptor     `json:"variables" yaml:"variables"`
+	Policies      []PolicyDescriptor                `json:"policies,omitempty" yaml:"policies,omitempty"`
 }
 
 // ValuesOnly returns copy of descriptor without additional state (e.g. provisioner reference etc.)
@@ -38,10 +42,12 @@ func (sd *ServerDescriptor) ValuesOnly() *ServerDescriptor {
 		SchemaVersion: sd.SchemaVersion,
 		Provisioner:   sd.Provisioner.ValuesOnly(),
 		Secrets:       sd.Secrets,
+		SecretSources: sd.SecretSources,
 		CiCd:          sd.CiCd,
 		Templates:     sd.Templates,
 		Resources:     sd.Resources,
 		Variables:     sd.Variables,
+		Policies:      sd.Policies,
 	}
 }
 
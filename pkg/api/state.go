@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// StackState is the exported checkpoint of a stack, secrets in the deployment are decrypted
+type StackState struct {
+	Stack      string          `json:"stack" yaml:"stack"`
+	Version    int             `json:"version" yaml:"version"`
+	Deployment json.RawMessage `json:"deployment" yaml:"deployment"`
+}
+
+// ResourceCount returns number of resources in the deployment of the stack
+func (s *StackState) ResourceCount() (int, error) {
+	urns, err := s.ResourceURNs()
+	return len(urns), err
+}
+
+// ResourceURNs returns URNs of resources in the deployment of the stack
+func (s *StackState) ResourceURNs() ([]string, error) {
+	resources, err := s.resources()
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(resources, func(r stackStateResource, _ int) string {
+		return r.URN
+	}), nil
+}
+
+// resources returns resources of the deployment of the stack in the order of the deployment
+func (s *StackState) resources() ([]stackStateResource, error) {
+	if len(s.Deployment) == 0 {
+		return nil, nil
+	}
+	var deployment struct {
+		Resources []json.RawMessage `json:"resources"`
+	}
+	if err := json.Unmarshal(s.Deployment, &deployment); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal deployment of stack %q", s.Stack)
+	}
+	res := make([]stackStateResource, 0, len(deployment.Resources))
+	for _, raw := range deployment.Resources {
+		resource := stackStateResource{State: raw}
+		if err := json.Unmarshal(raw, &resource); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal resource of stack %q", s.Stack)
+		}
+		res = append(res, resource)
+	}
+	return res, nil
+}
+
+type stackStateResource struct {
+	URN   string          `json:"urn"`
+	State json.RawMessage `json:"-"`
+}
+
+// MigrateStateParams describes migration of stack states from the state storage of one profile to another
+type MigrateStateParams struct {
+	StacksDir   string `json:"stacksDir" yaml:"stacksDir"`
+	FromProfile string `json:"fromProfile" yaml:"fromProfile"`
+	ToProfile   string `json:"toProfile" yaml:"toProfile"`
+	// Stacks are parent stacks to migrate along with their child stacks, all parent stacks when empty
+	Stacks []string `json:"stacks,omitempty" yaml:"stacks,omitempty"`
+	DryRun bool     `json:"dryRun" yaml:"dryRun"`
+	// Force allows overwriting stacks which already have resources in the target state storage
+	Force bool `json:"force" yaml:"force"`
+}
+
+// StackMigration describes result of migration of a single stack
+type StackMigration struct {
+	Stack string `json:"stack" yaml:"stack"`
+	// Environment is empty for parent stacks
+	Environment     string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	SourceResources int    `json:"sourceResources" yaml:"sourceResources"`
+	TargetResources int    `json:"targetResources" yaml:"targetResources"`
+	// Skipped is the reason the stack was not migrated
+	Skipped string `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+}
+
+type StateMigrationReport struct {
+	FromProfile string           `json:"fromProfile" yaml:"fromProfile"`
+	ToProfile   string           `json:"toProfile" yaml:"toProfile"`
+	DryRun      bool             `json:"dryRun" yaml:"dryRun"`
+	Stacks      []StackMigration `json:"stacks" yaml:"stacks"`
+}
+
+// StateSnapshotIDTimeFormat is the format of the time snapshot IDs end with
+const StateSnapshotIDTimeFormat = "20060102T150405Z"
+
+// BackupStateParams describes backup of states of the parent stack and its child stacks
+type BackupStateParams struct {
+	StacksDir string `json:"stacksDir" yaml:"stacksDir"`
+	StackName string `json:"stackName" yaml:"stackName"`
+	// Location is the local directory or bucket URL (s3://, gs://, file://) snapshots are written to
+	Location string `json:"location" yaml:"location"`
+	// Keep is the number of the latest snapshots of the stack to keep, older ones are deleted (0 keeps all)
+	Keep int `json:"keep" yaml:"keep"`
+}
+
+// RestoreStateParams describes restore of states of stacks from the snapshot
+type RestoreStateParams struct {
+	StacksDir string `json:"stacksDir" yaml:"stacksDir"`
+	Location  string `json:"location" yaml:"location"`
+	Snapshot  string `json:"snapshot" yaml:"snapshot"`
+	// Preview only reports changes of states without restoring them
+	Preview bool `json:"preview" yaml:"preview"`
+}
+
+// StateSnapshot describes backup of states of the parent stack and its child stacks
+type StateSnapshot struct {
+	ID        string               `json:"id" yaml:"id"`
+	Stack     string               `json:"stack" yaml:"stack"`
+	CreatedAt time.Time            `json:"createdAt" yaml:"createdAt"`
+	Stacks    []StateSnapshotStack `json:"stacks" yaml:"stacks"`
+}
+
+type StateSnapshotStack struct {
+	Stack string `json:"stack" yaml:"stack"`
+	// Environment is empty for the parent stack
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Resources   int    `json:"resources" yaml:"resources"`
+	// File is the name of the file with the encrypted state within the snapshot
+	File string `json:"file" yaml:"file"`
+}
+
+// NewStateSnapshotID returns ID of the snapshot of the stack taken at the time
+func NewStateSnapshotID(stackName string, at time.Time) string {
+	return stackName + "-" + at.UTC().Format(StateSnapshotIDTimeFormat)
+}
+
+// StackRestore describes changes of the state of a stack restored from the snapshot
+type StackRestore struct {
+	Stack       string `json:"stack" yaml:"stack"`
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	// CurrentResources is the number of resources in the current state, -1 if the stack does not exist
+	CurrentResources  int `json:"currentResources" yaml:"currentResources"`
+	SnapshotResources int `json:"snapshotResources" yaml:"snapshotResources"`
+	// Added are URNs of resources which are in the snapshot but not in the current state
+	Added []string `json:"added,omitempty" yaml:"added,omitempty"`
+	// Changed are URNs of resources whose state in the snapshot differs from the current one
+	Changed []string `json:"changed,omitempty" yaml:"changed,omitempty"`
+	// Removed are URNs of resources which are in the current state but not in the snapshot
+	Removed []string `json:"removed,omitempty" yaml:"removed,omitempty"`
+}
+
+type StateRestoreReport struct {
+	Snapshot StateSnapshot  `json:"snapshot" yaml:"snapshot"`
+	Preview  bool           `json:"preview" yaml:"preview"`
+	Stacks   []StackRestore `json:"stacks" yaml:"stacks"`
+	// Backup is the snapshot of current states taken before the restore, nil if there were none to back up
+	Backup *StateSnapshot `json:"backup,omitempty" yaml:"backup,omitempty"`
+}
+
+// NewStackRestore compares current state of the stack (nil if it does not exist) with its state in the snapshot
+func NewStackRestore(stack StateSnapshotStack, current *StackState, snapshot StackState) (*StackRestore, error) {
+	snapshotResources, err := snapshot.resources()
+	if err != nil {
+		return nil, err
+	}
+	res := &StackRestore{
+		Stack:             stack.Stack,
+		Environment:       stack.Environment,
+		CurrentResources:  -1,
+		SnapshotResources: len(snapshotResources),
+	}
+	currentStates := make(map[string]json.RawMessage)
+	if current != nil {
+		currentResources, err := current.resources()
+		if err != nil {
+			return nil, err
+		}
+		res.CurrentResources = len(currentResources)
+		for _, r := range currentResources {
+			currentStates[r.URN] = r.State
+		}
+	}
+	for _, r := range snapshotResources {
+		currentState, found := currentStates[r.URN]
+		switch {
+		case !found:
+			res.Added = append(res.Added, r.URN)
+		case !jsonEqual(currentState, r.State):
+			res.Changed = append(res.Changed, r.URN)
+		}
+		delete(currentStates, r.URN)
+	}
+	res.Removed = lo.Keys(currentStates)
+	sort.Strings(res.Added)
+	sort.Strings(res.Changed)
+	sort.Strings(res.Removed)
+	return res, nil
+}
+
+// jsonEqual returns true if both JSON documents have the same content regardless of formatting and order of keys
+func jsonEqual(a, b json.RawMessage) bool {
+	var aValue, bValue any
+	if json.Unmarshal(a, &aValue) != nil || json.Unmarshal(b, &bValue) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// HasChanges returns true if restore changes the state of the stack
+func (r *StackRestore) HasChanges() bool {
+	return r.CurrentResources < 0 || len(r.Added) > 0 || len(r.Changed) > 0 || len(r.Removed) > 0
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestStackState_ResourceCount(t *testing.T) {
+	RegisterTestingT(t)
+
+	state := StackState{Stack: "infra", Version: 3, Deployment: []byte(`{"manifest":{},"resources":[{"urn":"a"},{"urn":"b"}]}`)}
+	count, err := state.ResourceCount()
+	Expect(err).To(BeNil())
+	Expect(count).To(Equal(2))
+
+	count, err = (&StackState{Stack: "empty"}).ResourceCount()
+	Expect(err).To(BeNil())
+	Expect(count).To(Equal(0))
+
+	_, err = (&StackState{Stack: "broken", Deployment: []byte(`{"resources":{}}`)}).ResourceCount()
+	Expect(err).To(MatchError(ContainSubstring(`failed to unmarshal deployment of stack "broken"`)))
+}
+
+func TestNewStackRestore(t *testing.T) {
+	RegisterTestingT(t)
+
+	stack := StateSnapshotStack{Stack: "billing", Environment: "production"}
+	snapshot := StackState{Stack: "billing--production", Deployment: []byte(`{"resources":[
+		{"urn":"urn:stack","outputs":{}},
+		{"urn":"urn:bucket","outputs":{"name":"bucket-1"}},
+		{"urn":"urn:db","outputs":{"host":"db","port":5432}}
+	]}`)}
+
+	restore, err := NewStackRestore(stack, nil, snapshot)
+	Expect(err).To(BeNil())
+	Expect(restore.CurrentResources).To(Equal(-1))
+	Expect(restore.SnapshotResources).To(Equal(3))
+	Expect(restore.Added).To(Equal([]string{"urn:bucket", "urn:db", "urn:stack"}))
+	Expect(restore.HasChanges()).To(BeTrue())
+
+	current := StackState{Stack: "billing--production", Deployment: []byte(`{"resources":[
+		{"urn":"urn:stack","outputs":{}},
+		{"outputs":{"port":5432,"host":"db"},"urn":"urn:db"},
+		{"urn":"urn:bucket","outputs":{"name":"bucket-2"}},
+		{"urn":"urn:queue","outputs":{}}
+	]}`)}
+	restore, err = NewStackRestore(stack, &current, snapshot)
+	Expect(err).To(BeNil())
+	Expect(restore.CurrentResources).To(Equal(4))
+	Expect(restore.Added).To(BeEmpty())
+	Expect(restore.Changed).To(Equal([]string{"urn:bucket"}))
+	Expect(restore.Removed).To(Equal([]string{"urn:queue"}))
+	Expect(restore.HasChanges()).To(BeTrue())
+
+	restore, err = NewStackRestore(stack, &snapshot, snapshot)
+	Expect(err).To(BeNil())
+	Expect(restore.HasChanges()).To(BeFalse())
+}
+
+func TestNewStateSnapshotID(t *testing.T) {
+	RegisterTestingT(t)
+
+	at := time.Date(2026, 10, 16, 12, 30, 5, 0, time.FixedZone("CEST", 2*60*60))
+	Expect(NewStateSnapshotID("devops", at)).To(Equal("devops-20261016T103005Z"))
+}
@@ -21,8 +21,16 @@ func (n *noopProvisioner) ProvisionStack(context.Context, *ConfigFile, Stack, Pr
 	return nil
 }
 func (n *noopProvisioner) SetPublicKey(pubKey string) { n.pubKey = pubKey }
-func (n *noopProvisioner) DeployStack(context.Context, *ConfigFile, Stack, DeployParams) error {
-	return nil
+func (n *noopProvisioner) DeployStack(context.Context, *ConfigFile, Stack, DeployParams) (*UpdateResult, error) {
+	return &UpdateResult{}, nil
+}
+
+func (n *noopProvisioner) RollbackStack(context.Context, *ConfigFile, Stack, DeployParams, DeployRevision) (*UpdateResult, error) {
+	return &UpdateResult{}, nil
+}
+
+func (n *noopProvisioner) StackHistory(context.Context, *ConfigFile, Stack, StackParams) ([]StackHistoryEntry, error) {
+	return nil, nil
 }
 
 func (n *noopProvisioner) DestroyChildStack(context.Context, *ConfigFile, Stack, DestroyParams, bool) error {
@@ -41,6 +49,22 @@ func (n *noopProvisioner) OutputsStack(context.Context, *ConfigFile, Stack, Stac
 	return &OutputsResult{}, nil
 }
 
+func (n *noopProvisioner) DriftStack(context.Context, *ConfigFile, Stack, StackParams) (*DriftReport, error) {
+	return &DriftReport{}, nil
+}
+
+func (n *noopProvisioner) ExportStack(context.Context, *ConfigFile, Stack, StackParams) (*StackState, error) {
+	return nil, nil
+}
+
+func (n *noopProvisioner) ImportStack(context.Context, *ConfigFile, Stack, StackParams, StackState) error {
+	return nil
+}
+
+func (n *noopProvisioner) UnlockStack(context.Context, *ConfigFile, Stack, StackParams) (*StackLock, error) {
+	return nil, nil
+}
+
 func (n *noopProvisioner) CancelStack(context.Context, *ConfigFile, Stack, StackParams) error {
 	return nil
 }
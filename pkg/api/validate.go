@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+type ValidateParams struct {
+	StacksDir   string `json:"stacksDir" yaml:"stacksDir"`
+	StackName   string `json:"stack" yaml:"stack"`             // validate only this stack (default: all stacks)
+	Environment string `json:"environment" yaml:"environment"` // validate client stacks only for this environment (default: all environments)
+}
+
+// UnresolvedReference is a placeholder in stack configuration which cannot be resolved
+type UnresolvedReference struct {
+	Stack       string `json:"stack" yaml:"stack"`
+	File        string `json:"file" yaml:"file"`
+	Path        string `json:"path" yaml:"path"` // YAML path within the file, e.g. stacks.prod.config.env.DB_URL
+	Placeholder string `json:"placeholder" yaml:"placeholder"`
+	Extension   string `json:"extension,omitempty" yaml:"extension,omitempty"`
+	Reason      string `json:"reason" yaml:"reason"`
+	Ambiguous   bool   `json:"ambiguous,omitempty" yaml:"ambiguous,omitempty"` // neither a known extension nor a value
+	// Deferred placeholders are not evaluated by validation because of their side effects (e.g. commands)
+	Deferred bool `json:"deferred,omitempty" yaml:"deferred,omitempty"`
+}
+
+// ValidationReport is the result of validating stack configurations
+type ValidationReport struct {
+	Stacks     []string              `json:"stacks" yaml:"stacks"`
+	References []UnresolvedReference `json:"references" yaml:"references"`
+}
+
+// Add adds reference to the report unless the same reference is already reported
+func (r *ValidationReport) Add(ref UnresolvedReference) {
+	if !lo.Contains(r.References, ref) {
+		r.References = append(r.References, ref)
+	}
+}
+
+// Sort orders references by stack, file and path
+func (r *ValidationReport) Sort() {
+	sort.SliceStable(r.References, func(i, j int) bool {
+		a, b := r.References[i], r.References[j]
+		if a.Stack != b.Stack {
+			return a.Stack < b.Stack
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Path < b.Path
+	})
+}
+
+// Unresolved returns references which cannot be resolved, omitting deferred ones
+func (r *ValidationReport) Unresolved() []UnresolvedReference {
+	return lo.Filter(r.References, func(ref UnresolvedReference, _ int) bool {
+		return !ref.Deferred
+	})
+}
+
+// Valid returns true when all references are resolved or deferred
+func (r *ValidationReport) Valid() bool {
+	return len(r.Unresolved()) == 0
+}
+
+// String returns consolidated report, one line per reference
+func (r *ValidationReport) String() string {
+	var sb strings.Builder
+	for _, ref := range r.References {
+		kind := "unresolved"
+		if ref.Ambiguous {
+			kind = "ambiguous"
+		} else if ref.Deferred {
+			kind = "deferred"
+		}
+		ext := lo.Ternary(ref.Extension != "", ref.Extension, "-")
+		sb.WriteString(fmt.Sprintf("%s:%s: %s %s (extension: %s): %s\n", ref.File, ref.Path, kind, ref.Placeholder, ext, ref.Reason))
+	}
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package api
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValidationReport(t *testing.T) {
+	RegisterTestingT(t)
+
+	report := &ValidationReport{}
+	Expect(report.Valid()).To(BeTrue())
+
+	cmdRef := UnresolvedReference{
+		Stack: "billing", File: ".sc/stacks/billing/client.yaml", Path: "stacks.prod.config.env.VERSION",
+		Placeholder: "${cmd:git describe}", Extension: "cmd", Reason: `command "git describe" is not run in dry run`, Deferred: true,
+	}
+	report.Add(cmdRef)
+	Expect(report.Valid()).To(BeTrue())
+	Expect(report.Unresolved()).To(BeEmpty())
+
+	secretRef := UnresolvedReference{
+		Stack: "billing", File: ".sc/stacks/billing/client.yaml", Path: "stacks.prod.config.secrets.DB_PASSWORD",
+		Placeholder: "${secret:DB_PASSWORD}", Extension: "secret", Reason: `secret "DB_PASSWORD" not found in stack "billing"`,
+	}
+	report.Add(UnresolvedReference{
+		Stack: "infra", File: ".sc/stacks/infra/server.yaml", Path: "resources.resources.prod.resources.db.config.name",
+		Placeholder: "${nme}", Reason: "no extension specified and value is not found", Ambiguous: true,
+	})
+	report.Add(secretRef)
+	report.Add(secretRef)
+	report.Sort()
+
+	Expect(report.Valid()).To(BeFalse())
+	Expect(report.References).To(HaveLen(3))
+	Expect(report.Unresolved()).To(HaveLen(2))
+	Expect(report.References[0].Stack).To(Equal("billing"))
+	Expect(report.String()).To(Equal(
+		`.sc/stacks/billing/client.yaml:stacks.prod.config.env.VERSION: deferred ${cmd:git describe} (extension: cmd): command "git describe" is not run in dry run` + "\n" +
+			`.sc/stacks/billing/client.yaml:stacks.prod.config.secrets.DB_PASSWORD: unresolved ${secret:DB_PASSWORD} (extension: secret): secret "DB_PASSWORD" not found in stack "billing"` + "\n" +
+			`.sc/stacks/infra/server.yaml:resources.resources.prod.resources.db.config.name: ambiguous ${nme} (extension: -): no extension specified and value is not found` + "\n"))
+}
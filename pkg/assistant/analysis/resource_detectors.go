@@ -638,7 +638,7 @@ func (d *DatabaseDetector) getRecommendedResource(dbType string) string {
 		"postgresql":    "aws-rds-postgres or gcp-cloudsql-postgres or kubernetes-helm-postgres-operator",
 		"mysql":         "aws-rds-mysql",
 		"mongodb":       "mongodb-atlas",
-		"redis":         "gcp-redis or kubernetes-helm-redis-operator",
+		"redis":         "aws-elasticache-redis or gcp-redis or kubernetes-helm-redis-operator",
 		"sqlite":        "Consider upgrading to managed database for production",
 		"elasticsearch": "Consider managed Elasticsearch service",
 	}
@@ -1007,7 +1007,7 @@ func (d *QueueDetector) getRecommendedResource(queueType string) string {
 	recommendations := map[string]string{
 		"rabbitmq":         "kubernetes-helm-rabbitmq-operator",
 		"kafka":            "Consider managed Kafka service",
-		"aws_sqs":          "Use AWS SQS with ${auth:aws}",
+		"aws_sqs":          "aws-sqs-sns",
 		"redis_pubsub":     "gcp-redis or kubernetes-helm-redis-operator",
 		"gcp_pubsub":       "Use GCP Pub/Sub with ${auth:gcloud}",
 		"azure_servicebus": "Use Azure Service Bus",
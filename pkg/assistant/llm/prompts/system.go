@@ -426,6 +426,8 @@ resources:
 #### AWS Resources:
 - aws-rds-postgres: PostgreSQL database
 - aws-rds-mysql: MySQL database
+- aws-elasticache-redis: ElastiCache Redis/Valkey
+- aws-sqs-sns: SQS queues and SNS topics
 - ecr-repository: Container registry
 - s3-bucket: S3 storage bucket
 
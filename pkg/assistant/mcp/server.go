@@ -2151,6 +2151,8 @@ func (h *DefaultMCPHandler) getFallbackSupportedResources() *SupportedResourcesR
 		{Type: "ecr-repository", Name: "ECR Repository", Provider: "aws", Description: "Amazon ECR container registry", Properties: map[string]string{}},
 		{Type: "aws-rds-postgres", Name: "RDS PostgreSQL", Provider: "aws", Description: "Amazon RDS PostgreSQL database", Properties: map[string]string{}},
 		{Type: "aws-rds-mysql", Name: "RDS MySQL", Provider: "aws", Description: "Amazon RDS MySQL database", Properties: map[string]string{}},
+		{Type: "aws-elasticache-redis", Name: "ElastiCache Redis", Provider: "aws", Description: "Amazon ElastiCache Redis/Valkey", Properties: map[string]string{}},
+		{Type: "aws-sqs-sns", Name: "SQS/SNS Messaging", Provider: "aws", Description: "Amazon SQS queues and SNS topics", Properties: map[string]string{}},
 
 		// GCP Resources
 		{Type: "gcp-bucket", Name: "Cloud Storage Bucket", Provider: "gcp", Description: "Google Cloud Storage bucket", Properties: map[string]string{}},
@@ -2171,7 +2173,7 @@ func (h *DefaultMCPHandler) getFallbackSupportedResources() *SupportedResourcesR
 	}
 
 	fallbackProviders := []ProviderInfo{
-		{Name: "aws", DisplayName: "Amazon Web Services", Resources: []string{"s3-bucket", "ecr-repository", "aws-rds-postgres", "aws-rds-mysql"}, Description: "AWS cloud services"},
+		{Name: "aws", DisplayName: "Amazon Web Services", Resources: []string{"s3-bucket", "ecr-repository", "aws-rds-postgres", "aws-rds-mysql", "aws-elasticache-redis", "aws-sqs-sns"}, Description: "AWS cloud services"},
 		{Name: "gcp", DisplayName: "Google Cloud Platform", Resources: []string{"gcp-bucket", "gcp-redis", "gcp-cloudsql-postgres", "gcp-artifact-registry"}, Description: "Google Cloud services"},
 		{Name: "mongodb", DisplayName: "MongoDB Atlas", Resources: []string{"mongodb-atlas"}, Description: "MongoDB Atlas managed database"},
 		{Name: "kubernetes", DisplayName: "Kubernetes", Resources: []string{"helm-postgres", "helm-redis", "helm-rabbitmq"}, Description: "Kubernetes resources"},
@@ -3012,6 +3014,8 @@ func (h *DefaultMCPHandler) getResourceSchemaContext() string {
 - **ecr-repository**: Container registry  
 - **aws-rds-postgres**: PostgreSQL database
 - **aws-rds-mysql**: MySQL database
+- **aws-elasticache-redis**: ElastiCache Redis/Valkey
+- **aws-sqs-sns**: SQS queues and SNS topics
 
 #### GCP Resources:
 - **gcp-bucket**: Cloud Storage bucket
@@ -869,6 +869,8 @@ func (d *DevOpsMode) listResources(opts ResourceOptions) error {
 			"s3-bucket - Amazon S3 storage bucket",
 			"aws-rds-postgres - Amazon RDS PostgreSQL database",
 			"aws-rds-mysql - Amazon RDS MySQL database",
+			"aws-elasticache-redis - Amazon ElastiCache Redis/Valkey",
+			"aws-sqs-sns - Amazon SQS queues and SNS topics",
 			"ecr-repository - Amazon ECR container registry",
 		},
 		"gcp": {
@@ -1148,6 +1150,13 @@ func (d *DevOpsMode) createResourceTemplate(resourceType, resourceName string) m
 		resource["engineVersion"] = "8.0"
 		resource["username"] = "admin"
 		resource["databaseName"] = "main"
+	case "aws-elasticache-redis":
+		resource["name"] = resourceName
+		resource["nodeType"] = "cache.t3.micro"
+	case "aws-sqs-sns":
+		resource["queues"] = []map[string]interface{}{
+			{"name": resourceName, "deadLetterQueue": map[string]interface{}{"maxReceiveCount": 5}},
+		}
 	case "gcp-bucket":
 		resource["name"] = resourceName
 		resource["location"] = "US"
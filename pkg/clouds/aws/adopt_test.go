@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func TestReadConfig_Adoption(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("rds postgres", func(t *testing.T) {
+		RegisterTestingT(t)
+		out, err := ReadRdsPostgresConfig(&api.Config{Config: map[string]any{
+			"adopt":              true,
+			"instanceIdentifier": "legacy-postgres",
+			"password":           "secret",
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		pg := out.Config.(*PostgresConfig)
+		Expect(pg.Adopt).To(BeTrue())
+		Expect(pg.InstanceIdentifier).To(Equal("legacy-postgres"))
+		Expect(pg.Password).To(Equal("secret"))
+	})
+
+	t.Run("rds mysql", func(t *testing.T) {
+		RegisterTestingT(t)
+		out, err := ReadRdsMysqlConfig(&api.Config{Config: map[string]any{
+			"adopt":              true,
+			"instanceIdentifier": "legacy-mysql",
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		mysql := out.Config.(*MysqlConfig)
+		Expect(mysql.Adopt).To(BeTrue())
+		Expect(mysql.InstanceIdentifier).To(Equal("legacy-mysql"))
+	})
+
+	t.Run("s3 bucket", func(t *testing.T) {
+		RegisterTestingT(t)
+		out, err := S3BucketReadConfig(&api.Config{Config: map[string]any{
+			"name":       "uploads",
+			"adopt":      true,
+			"bucketName": "legacy-uploads-bucket",
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		b := out.Config.(*S3Bucket)
+		Expect(b.Name).To(Equal("uploads"))
+		Expect(b.Adopt).To(BeTrue())
+		Expect(b.BucketName).To(Equal("legacy-uploads-bucket"))
+	})
+
+	t.Run("ecr repository", func(t *testing.T) {
+		RegisterTestingT(t)
+		out, err := EcrRepositoryReadConfig(&api.Config{Config: map[string]any{
+			"name":           "images",
+			"adopt":          true,
+			"repositoryName": "legacy-images",
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		repo := out.Config.(*EcrRepository)
+		Expect(repo.Adopt).To(BeTrue())
+		Expect(repo.RepositoryName).To(Equal("legacy-images"))
+		Expect(repo.LifecyclePolicy).To(BeNil())
+	})
+
+	t.Run("adoption is off by default", func(t *testing.T) {
+		RegisterTestingT(t)
+		out, err := ReadRdsPostgresConfig(&api.Config{Config: map[string]any{"name": "db"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.Config.(*PostgresConfig).Adopt).To(BeFalse())
+	})
+}
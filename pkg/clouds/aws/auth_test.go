@@ -330,6 +330,8 @@ func TestInitRegistration(t *testing.T) {
 		ResourceTypeEcrRepository,
 		ResourceTypeRdsPostgres,
 		ResourceTypeRdsMysql,
+		ResourceTypeElasticacheRedis,
+		ResourceTypeSqsSns,
 		ResourceTypeCloudTrailSecurityAlerts,
 	} {
 		Expect(providers).To(HaveKey(key), "provider config %q must be registered by init()", key)
@@ -62,3 +62,8 @@ func ToAwsLambdaConfig(tpl any, stackCfg *api.StackConfigSingleImage) (any, erro
 
 	return res, nil
 }
+
+// ReadAwsLambdaInput reads LambdaInput previously produced by ToAwsLambdaConfig
+func ReadAwsLambdaInput(config *api.Config) (api.Config, error) {
+	return api.ConvertConfig(config, &LambdaInput{})
+}
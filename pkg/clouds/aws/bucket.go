@@ -12,6 +12,9 @@ type S3Bucket struct {
 	*api.StaticSiteConfig `json:",inline,omitempty" yaml:",inline,omitempty"`
 	Name                  string `json:"name,omitempty" yaml:"name,omitempty"`
 	AllowOnlyHttps        bool   `json:"allowOnlyHttps" yaml:"allowOnlyHttps"`
+	// Resource adoption fields
+	Adopt      bool   `json:"adopt,omitempty" yaml:"adopt,omitempty"`
+	BucketName string `json:"bucketName,omitempty" yaml:"bucketName,omitempty"`
 }
 
 func S3BucketReadConfig(config *api.Config) (api.Config, error) {
@@ -11,6 +11,9 @@ type EcrRepository struct {
 	AccountConfig   `json:",inline" yaml:",inline"`
 	Name            string              `json:"name,omitempty" yaml:"name,omitempty"`
 	LifecyclePolicy *EcrLifecyclePolicy `json:"lifecyclePolicy" yaml:"lifecyclePolicy"`
+	// Resource adoption fields
+	Adopt          bool   `json:"adopt,omitempty" yaml:"adopt,omitempty"`
+	RepositoryName string `json:"repositoryName,omitempty" yaml:"repositoryName,omitempty"`
 }
 
 type EcrLifecyclePolicy struct {
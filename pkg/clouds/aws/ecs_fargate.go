@@ -342,6 +342,11 @@ func ToEcsFargateConfig(tpl any, composeCfg compose.Config, stackCfg *api.StackC
 	return res, nil
 }
 
+// ReadEcsFargateInput reads EcsFargateInput previously produced by ToEcsFargateConfig
+func ReadEcsFargateInput(config *api.Config) (api.Config, error) {
+	return api.ConvertConfig(config, &EcsFargateInput{})
+}
+
 type EcsFargateDependsOn struct {
 	Container string `json:"container" yaml:"container"`
 	Condition string `json:"condition" yaml:"condition"`
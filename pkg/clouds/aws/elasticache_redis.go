@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+const (
+	ResourceTypeElasticacheRedis = "aws-elasticache-redis"
+)
+
+type ElasticacheRedisConfig struct {
+	AccountConfig `json:",inline" yaml:",inline"`
+	Name          string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Engine is either "redis" (default) or "valkey"
+	Engine        string `json:"engine,omitempty" yaml:"engine,omitempty"`
+	EngineVersion string `json:"engineVersion,omitempty" yaml:"engineVersion,omitempty"`
+	NodeType      string `json:"nodeType,omitempty" yaml:"nodeType,omitempty"`
+	// NumCacheClusters is the number of nodes, automatic failover is enabled when there is more than one
+	NumCacheClusters *int `json:"numCacheClusters,omitempty" yaml:"numCacheClusters,omitempty"`
+	// ParameterGroupFamily is required along with Parameters (e.g. redis7, valkey8)
+	ParameterGroupFamily string            `json:"parameterGroupFamily,omitempty" yaml:"parameterGroupFamily,omitempty"`
+	Parameters           map[string]string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	// AuthToken enables in-transit encryption and AUTH, clients must connect using TLS then
+	AuthToken              string `json:"authToken,omitempty" yaml:"authToken,omitempty"`
+	AtRestEncryption       *bool  `json:"atRestEncryption,omitempty" yaml:"atRestEncryption,omitempty"`
+	SnapshotRetentionLimit *int   `json:"snapshotRetentionLimit,omitempty" yaml:"snapshotRetentionLimit,omitempty"`
+}
+
+func ReadElasticacheRedisConfig(config *api.Config) (api.Config, error) {
+	return api.ConvertConfig(config, &ElasticacheRedisConfig{})
+}
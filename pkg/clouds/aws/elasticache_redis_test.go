@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func TestReadElasticacheRedisConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	out, err := ReadElasticacheRedisConfig(&api.Config{Config: map[string]any{
+		"account":              "123456789012",
+		"engine":               "valkey",
+		"engineVersion":        "8.0",
+		"nodeType":             "cache.t4g.small",
+		"numCacheClusters":     2,
+		"parameterGroupFamily": "valkey8",
+		"parameters": map[string]any{
+			"maxmemory-policy": "allkeys-lru",
+		},
+		"authToken": "token-of-at-least-16-chars",
+	}})
+	Expect(err).ToNot(HaveOccurred())
+	redis, ok := out.Config.(*ElasticacheRedisConfig)
+	Expect(ok).To(BeTrue())
+	Expect(redis.Account).To(Equal("123456789012"))
+	Expect(redis.Engine).To(Equal("valkey"))
+	Expect(redis.NodeType).To(Equal("cache.t4g.small"))
+	Expect(redis.NumCacheClusters).To(Equal(lo.ToPtr(2)))
+	Expect(redis.ParameterGroupFamily).To(Equal("valkey8"))
+	Expect(redis.Parameters).To(Equal(map[string]string{"maxmemory-policy": "allkeys-lru"}))
+	Expect(redis.AuthToken).To(Equal("token-of-at-least-16-chars"))
+	Expect(redis.AtRestEncryption).To(BeNil())
+
+	local := elasticacheRedisLocalContext(api.LocalResource{Host: "localhost", Port: "16379"})
+	Expect(local.Env).To(Equal(map[string]string{"REDIS_HOST": "localhost", "REDIS_PORT": "16379"}))
+}
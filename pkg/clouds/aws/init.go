@@ -29,10 +29,20 @@ func init() {
 		ResourceTypeRdsPostgres: ReadRdsPostgresConfig,
 		ResourceTypeRdsMysql:    ReadRdsMysqlConfig,
 
+		// elasticache
+		ResourceTypeElasticacheRedis: ReadElasticacheRedisConfig,
+
+		// messaging
+		ResourceTypeSqsSns: ReadSqsSnsConfig,
+
 		// security
 		ResourceTypeCloudTrailSecurityAlerts: ReadCloudTrailSecurityAlertsConfig,
 	})
 
+	api.RegisterSecretSources(api.SecretSourcesRegisterMap{
+		SecretsTypeAWSSecretsManager: NewSecretsManagerSource,
+	})
+
 	api.RegisterProvisionerFieldConfig(api.ProvisionerFieldConfigRegister{
 		StateStorageTypeS3Bucket:  ReadStateStorageConfig,
 		SecretsProviderTypeAwsKms: ReadSecretsProviderConfig,
@@ -50,6 +60,26 @@ func init() {
 		TemplateTypeAwsLambda: ToAwsLambdaConfig,
 	})
 
+	api.RegisterDeployInputReader(api.ConfigRegisterMap{
+		TemplateTypeEcsFargate: ReadEcsFargateInput,
+		TemplateTypeAwsLambda:  ReadAwsLambdaInput,
+	})
+
+	api.RegisterPolicyResourceTypes(api.PolicyResourceTypesRegister{
+		ResourceTypeS3Bucket:         {"aws:s3/bucket:Bucket"},
+		ResourceTypeEcrRepository:    {"aws:ecr/repository:Repository"},
+		ResourceTypeRdsPostgres:      {"aws:rds/instance:Instance"},
+		ResourceTypeRdsMysql:         {"aws:rds/instance:Instance"},
+		ResourceTypeElasticacheRedis: {"aws:elasticache/replicationGroup:ReplicationGroup"},
+		ResourceTypeSqsSns:           {"aws:sqs/queue:Queue", "aws:sns/topic:Topic"},
+	})
+
+	api.RegisterLocalStandIns(api.LocalStandInsRegister{
+		ResourceTypeRdsPostgres:      {Kind: api.LocalStandInPostgres, Context: rdsPostgresLocalContext},
+		ResourceTypeRdsMysql:         {Kind: api.LocalStandInMysql, Context: rdsMysqlLocalContext},
+		ResourceTypeElasticacheRedis: {Kind: api.LocalStandInRedis, Context: elasticacheRedisLocalContext},
+	})
+
 	api.RegisterCloudHelper(api.CloudHelpersRegisterMap{
 		helpers.CHCloudwatchAlertLambda:   helpers.NewCloudwatchLambdaHelper,
 		helpers.CHHealthBridgeAlertLambda: helpers.NewHealthBridgeLambdaHelper,
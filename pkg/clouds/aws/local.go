@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/util"
+)
+
+// rdsPostgresLocalContext provides the same env variables and template values as rds postgres does in the cloud
+func rdsPostgresLocalContext(res api.LocalResource) api.LocalResourceContext {
+	resName := res.Descriptor.Name
+	if cfg, ok := res.Descriptor.Config.Config.(*PostgresConfig); ok && cfg.Name != "" {
+		resName = cfg.Name
+	}
+	return rdsLocalContext(res, resName, map[string]string{
+		"PGHOST":     res.Host,
+		"PGPORT":     res.Port,
+		"PGUSER":     res.User,
+		"PGDATABASE": res.Database,
+		"PGPASSWORD": res.Password,
+	})
+}
+
+// rdsMysqlLocalContext provides the same env variables and template values as rds mysql does in the cloud
+func rdsMysqlLocalContext(res api.LocalResource) api.LocalResourceContext {
+	resName := res.Descriptor.Name
+	if cfg, ok := res.Descriptor.Config.Config.(*MysqlConfig); ok && cfg.Name != "" {
+		resName = cfg.Name
+	}
+	return rdsLocalContext(res, resName, map[string]string{
+		"MYSQL_HOST":     res.Host,
+		"MYSQL_PORT":     res.Port,
+		"MYSQL_USER":     res.User,
+		"MYSQL_DB":       res.Database,
+		"MYSQL_PASSWORD": res.Password,
+	})
+}
+
+// rdsLocalContext adds every variable both as is and suffixed with the name of the resource
+func rdsLocalContext(res api.LocalResource, resName string, vars map[string]string) api.LocalResourceContext {
+	env := make(map[string]string, len(vars)*2)
+	for name, value := range vars {
+		env[util.ToEnvVariableName(name)] = value
+		env[util.ToEnvVariableName(fmt.Sprintf("%s_%s", name, resName))] = value
+	}
+	return api.LocalResourceContext{
+		Env: env,
+		TplValues: map[string]string{
+			"url":      net.JoinHostPort(res.Host, res.Port),
+			"host":     res.Host,
+			"port":     res.Port,
+			"user":     res.User,
+			"database": res.Database,
+			"password": res.Password,
+		},
+	}
+}
+
+// elasticacheRedisLocalContext provides the same env variables and template values as elasticache redis does in the cloud,
+// local redis has no auth token so REDIS_PASSWORD is not set
+func elasticacheRedisLocalContext(res api.LocalResource) api.LocalResourceContext {
+	return api.LocalResourceContext{
+		Env: map[string]string{
+			"REDIS_HOST": res.Host,
+			"REDIS_PORT": res.Port,
+		},
+		TplValues: map[string]string{
+			"host": res.Host,
+			"port": res.Port,
+		},
+	}
+}
@@ -37,6 +37,10 @@ type MysqlConfig struct {
 	// encrypted must do it out-of-band: snapshot → encrypted-copy →
 	// restore → re-import.
 	StorageEncrypted *bool `json:"storageEncrypted,omitempty" yaml:"storageEncrypted,omitempty"`
+	// Resource adoption fields: InstanceIdentifier is the identifier of the existing RDS instance,
+	// Password must be its master password since it cannot be read back from AWS
+	Adopt              bool   `json:"adopt,omitempty" yaml:"adopt,omitempty"`
+	InstanceIdentifier string `json:"instanceIdentifier,omitempty" yaml:"instanceIdentifier,omitempty"`
 }
 
 func ReadRdsMysqlConfig(config *api.Config) (api.Config, error) {
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+type secretsManagerSource struct {
+	config *SecretsConfig
+}
+
+// NewSecretsManagerSource returns reader of AWS Secrets Manager, paths are names or ARNs of secrets
+func NewSecretsManagerSource(config api.Config) (api.SecretSource, error) {
+	cfg, ok := config.Config.(*SecretsConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert aws secrets manager config %T", config.Config)
+	}
+	return &secretsManagerSource{config: cfg}, nil
+}
+
+func (s *secretsManagerSource) ReadSecret(ctx context.Context, path string) (string, error) {
+	loadOpts := []func(*config.LoadOptions) error{}
+	if s.config.Region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(s.config.Region))
+	}
+	if s.config.AccessKey != "" && s.config.SecretAccessKey != "" {
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(s.config.AccessKey, s.config.SecretAccessKey, ""),
+		))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load AWS config for secrets manager")
+	}
+
+	out, err := secretsmanager.NewFromConfig(awsCfg).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: &path,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get secret %q from secrets manager", path)
+	}
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	return string(out.SecretBinary), nil
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+const (
+	ResourceTypeSqsSns = "aws-sqs-sns"
+
+	// sqsMaxVisibilityTimeoutSec is the max visibility timeout AWS allows (12 hours)
+	sqsMaxVisibilityTimeoutSec = 43200
+)
+
+// SqsSnsConfig declares SNS topics, SQS queues and subscriptions of queues to topics
+type SqsSnsConfig struct {
+	AccountConfig `json:",inline" yaml:",inline"`
+	Topics        []SnsTopic        `json:"topics,omitempty" yaml:"topics,omitempty"`
+	Queues        []SqsQueue        `json:"queues,omitempty" yaml:"queues,omitempty"`
+	Subscriptions []SnsSubscription `json:"subscriptions,omitempty" yaml:"subscriptions,omitempty"`
+	// Alerts configures notifications of CloudWatch alarms on the depth of dead-letter queues
+	Alerts *SqsDlqAlertsConfig `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+}
+
+type SnsTopic struct {
+	Name                      string `json:"name" yaml:"name"`
+	Fifo                      bool   `json:"fifo,omitempty" yaml:"fifo,omitempty"`
+	ContentBasedDeduplication bool   `json:"contentBasedDeduplication,omitempty" yaml:"contentBasedDeduplication,omitempty"`
+}
+
+type SqsQueue struct {
+	Name                      string `json:"name" yaml:"name"`
+	Fifo                      bool   `json:"fifo,omitempty" yaml:"fifo,omitempty"`
+	ContentBasedDeduplication bool   `json:"contentBasedDeduplication,omitempty" yaml:"contentBasedDeduplication,omitempty"`
+	VisibilityTimeoutSec      *int   `json:"visibilityTimeoutSec,omitempty" yaml:"visibilityTimeoutSec,omitempty"`
+	MessageRetentionSec       *int   `json:"messageRetentionSec,omitempty" yaml:"messageRetentionSec,omitempty"`
+	DelaySec                  *int   `json:"delaySec,omitempty" yaml:"delaySec,omitempty"`
+	// DeadLetterQueue creates a dead-letter queue receiving messages that failed to be processed
+	DeadLetterQueue *SqsDeadLetterQueue `json:"deadLetterQueue,omitempty" yaml:"deadLetterQueue,omitempty"`
+}
+
+type SqsDeadLetterQueue struct {
+	// MaxReceiveCount is the number of receives before a message is moved to the dead-letter queue, defaults to 5
+	MaxReceiveCount     int  `json:"maxReceiveCount,omitempty" yaml:"maxReceiveCount,omitempty"`
+	MessageRetentionSec *int `json:"messageRetentionSec,omitempty" yaml:"messageRetentionSec,omitempty"`
+}
+
+type SnsSubscription struct {
+	Topic              string         `json:"topic" yaml:"topic"`
+	Queue              string         `json:"queue" yaml:"queue"`
+	RawMessageDelivery bool           `json:"rawMessageDelivery,omitempty" yaml:"rawMessageDelivery,omitempty"`
+	FilterPolicy       map[string]any `json:"filterPolicy,omitempty" yaml:"filterPolicy,omitempty"`
+}
+
+type SqsDlqAlertsConfig struct {
+	// Threshold is the number of messages in a dead-letter queue raising the alarm, defaults to 1
+	Threshold float64          `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	PeriodSec int              `json:"periodSec,omitempty" yaml:"periodSec,omitempty"`
+	Slack     *api.SlackCfg    `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Discord   *api.DiscordCfg  `json:"discord,omitempty" yaml:"discord,omitempty"`
+	Telegram  *api.TelegramCfg `json:"telegram,omitempty" yaml:"telegram,omitempty"`
+	Email     *api.EmailCfg    `json:"email,omitempty" yaml:"email,omitempty"`
+}
+
+func ReadSqsSnsConfig(config *api.Config) (api.Config, error) {
+	return api.ConvertConfig(config, &SqsSnsConfig{})
+}
+
+// Validate checks that names are unique and subscriptions only reference declared topics and queues
+func (c *SqsSnsConfig) Validate() error {
+	topics := make(map[string]SnsTopic, len(c.Topics))
+	for _, topic := range c.Topics {
+		if topic.Name == "" {
+			return errors.Errorf("topic name must not be empty")
+		}
+		if _, ok := topics[topic.Name]; ok {
+			return errors.Errorf("topic %q is declared more than once", topic.Name)
+		}
+		topics[topic.Name] = topic
+	}
+
+	queues := make(map[string]SqsQueue, len(c.Queues))
+	for _, queue := range c.Queues {
+		if queue.Name == "" {
+			return errors.Errorf("queue name must not be empty")
+		}
+		if _, ok := queues[queue.Name]; ok {
+			return errors.Errorf("queue %q is declared more than once", queue.Name)
+		}
+		if queue.VisibilityTimeoutSec != nil && (*queue.VisibilityTimeoutSec < 0 || *queue.VisibilityTimeoutSec > sqsMaxVisibilityTimeoutSec) {
+			return errors.Errorf("visibilityTimeoutSec of queue %q must be between 0 and %d", queue.Name, sqsMaxVisibilityTimeoutSec)
+		}
+		if queue.DeadLetterQueue != nil && queue.DeadLetterQueue.MaxReceiveCount < 0 {
+			return errors.Errorf("maxReceiveCount of dead-letter queue of %q must not be negative", queue.Name)
+		}
+		queues[queue.Name] = queue
+	}
+
+	for _, sub := range c.Subscriptions {
+		topic, ok := topics[sub.Topic]
+		if !ok {
+			return errors.Errorf("subscription of queue %q references undeclared topic %q", sub.Queue, sub.Topic)
+		}
+		queue, ok := queues[sub.Queue]
+		if !ok {
+			return errors.Errorf("subscription to topic %q references undeclared queue %q", sub.Topic, sub.Queue)
+		}
+		// FIFO queues can only subscribe to FIFO topics
+		if queue.Fifo && !topic.Fifo {
+			return errors.Errorf("fifo queue %q cannot subscribe to standard topic %q", sub.Queue, sub.Topic)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func TestReadSqsSnsConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	out, err := ReadSqsSnsConfig(&api.Config{Config: map[string]any{
+		"account": "123456789012",
+		"topics": []any{
+			map[string]any{"name": "orders", "fifo": true},
+		},
+		"queues": []any{
+			map[string]any{
+				"name":                 "order-processing",
+				"fifo":                 true,
+				"visibilityTimeoutSec": 120,
+				"deadLetterQueue":      map[string]any{"maxReceiveCount": 3},
+			},
+		},
+		"subscriptions": []any{
+			map[string]any{
+				"topic":              "orders",
+				"queue":              "order-processing",
+				"rawMessageDelivery": true,
+				"filterPolicy":       map[string]any{"type": []any{"created"}},
+			},
+		},
+		"alerts": map[string]any{
+			"threshold": 10,
+			"slack":     map[string]any{"webhookUrl": "https://hooks.slack.com/xxx"},
+		},
+	}})
+	Expect(err).ToNot(HaveOccurred())
+	cfg, ok := out.Config.(*SqsSnsConfig)
+	Expect(ok).To(BeTrue())
+	Expect(cfg.Account).To(Equal("123456789012"))
+	Expect(cfg.Topics).To(Equal([]SnsTopic{{Name: "orders", Fifo: true}}))
+	Expect(cfg.Queues).To(HaveLen(1))
+	Expect(cfg.Queues[0].VisibilityTimeoutSec).To(Equal(lo.ToPtr(120)))
+	Expect(cfg.Queues[0].DeadLetterQueue.MaxReceiveCount).To(Equal(3))
+	Expect(cfg.Subscriptions[0].RawMessageDelivery).To(BeTrue())
+	Expect(cfg.Subscriptions[0].FilterPolicy).To(HaveKey("type"))
+	Expect(cfg.Alerts.Threshold).To(Equal(float64(10)))
+	Expect(cfg.Alerts.Slack.WebhookUrl).To(Equal("https://hooks.slack.com/xxx"))
+	Expect(cfg.Validate()).To(Succeed())
+}
+
+func TestSqsSnsConfig_Validate(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, tc := range []struct {
+		name    string
+		cfg     SqsSnsConfig
+		wantErr string
+	}{
+		{
+			name: "duplicate queue",
+			cfg: SqsSnsConfig{
+				Queues: []SqsQueue{{Name: "jobs"}, {Name: "jobs"}},
+			},
+			wantErr: `queue "jobs" is declared more than once`,
+		},
+		{
+			name: "visibility timeout out of range",
+			cfg: SqsSnsConfig{
+				Queues: []SqsQueue{{Name: "jobs", VisibilityTimeoutSec: lo.ToPtr(50000)}},
+			},
+			wantErr: "visibilityTimeoutSec of queue \"jobs\" must be between 0 and 43200",
+		},
+		{
+			name: "undeclared topic",
+			cfg: SqsSnsConfig{
+				Queues:        []SqsQueue{{Name: "jobs"}},
+				Subscriptions: []SnsSubscription{{Topic: "events", Queue: "jobs"}},
+			},
+			wantErr: `references undeclared topic "events"`,
+		},
+		{
+			name: "undeclared queue",
+			cfg: SqsSnsConfig{
+				Topics:        []SnsTopic{{Name: "events"}},
+				Subscriptions: []SnsSubscription{{Topic: "events", Queue: "jobs"}},
+			},
+			wantErr: `references undeclared queue "jobs"`,
+		},
+		{
+			name: "fifo queue subscribed to standard topic",
+			cfg: SqsSnsConfig{
+				Topics:        []SnsTopic{{Name: "events"}},
+				Queues:        []SqsQueue{{Name: "jobs", Fifo: true}},
+				Subscriptions: []SnsSubscription{{Topic: "events", Queue: "jobs"}},
+			},
+			wantErr: `fifo queue "jobs" cannot subscribe to standard topic "events"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+			Expect(tc.cfg.Validate()).To(MatchError(ContainSubstring(tc.wantErr)))
+		})
+	}
+}
@@ -76,3 +76,8 @@ func ToCloudRunConfig(tpl any, composeCfg compose.Config, stackCfg *api.StackCon
 
 	return res, nil
 }
+
+// ReadCloudRunInput reads CloudRunInput previously produced by ToCloudRunConfig
+func ReadCloudRunInput(config *api.Config) (api.Config, error) {
+	return api.ConvertConfig(config, &CloudRunInput{})
+}
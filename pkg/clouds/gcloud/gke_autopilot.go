@@ -175,6 +175,11 @@ func ToGkeAutopilotConfig(tpl any, composeCfg compose.Config, stackCfg *api.Stac
 	return res, nil
 }
 
+// ReadGkeAutopilotInput reads GkeAutopilotInput previously produced by ToGkeAutopilotConfig
+func ReadGkeAutopilotInput(config *api.Config) (api.Config, error) {
+	return api.ConvertConfig(config, &GkeAutopilotInput{})
+}
+
 // Validate validates the ExternalEgressIpConfig
 func (c *ExternalEgressIpConfig) Validate() error {
 	if !c.Enabled {
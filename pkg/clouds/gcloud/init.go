@@ -38,6 +38,10 @@ func init() {
 		ResourceTypeRemoteDockerImagePush: DockerRemoteImagePushReadConfig,
 	})
 
+	api.RegisterSecretSources(api.SecretSourcesRegisterMap{
+		SecretsTypeGCPSecretsManager: NewSecretManagerSource,
+	})
+
 	api.RegisterProvisionerFieldConfig(api.ProvisionerFieldConfigRegister{
 		StateStorageTypeGcpBucket: ReadStateStorageConfig,
 		SecretsProviderTypeGcpKms: ReadSecretsProviderConfig,
@@ -51,4 +55,23 @@ func init() {
 	api.RegisterCloudStaticSiteConverter(api.CloudStaticSiteConfigRegister{
 		TemplateTypeStaticWebsite: ToStaticSiteConfig,
 	})
+
+	api.RegisterDeployInputReader(api.ConfigRegisterMap{
+		TemplateTypeGcpCloudrun:  ReadCloudRunInput,
+		TemplateTypeGkeAutopilot: ReadGkeAutopilotInput,
+	})
+
+	api.RegisterPolicyResourceTypes(api.PolicyResourceTypesRegister{
+		ResourceTypePostgresGcpCloudsql: {"gcp:sql/databaseInstance:DatabaseInstance"},
+		ResourceTypeRedis:               {"gcp:redis/instance:Instance"},
+		ResourceTypeBucket:              {"gcp:storage/bucket:Bucket"},
+		ResourceTypePubSub:              {"gcp:pubsub/topic:Topic", "gcp:pubsub/subscription:Subscription"},
+		ResourceTypeArtifactRegistry:    {"gcp:artifactregistry/repository:Repository"},
+		ResourceTypeGkeAutopilot:        {"gcp:container/cluster:Cluster"},
+	})
+
+	api.RegisterLocalStandIns(api.LocalStandInsRegister{
+		ResourceTypePostgresGcpCloudsql: {Kind: api.LocalStandInPostgres, Context: cloudsqlPostgresLocalContext},
+		ResourceTypeRedis:               {Kind: api.LocalStandInRedis, Context: redisLocalContext},
+	})
 }
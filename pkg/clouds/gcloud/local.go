@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package gcloud
+
+import (
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+// cloudsqlPostgresLocalContext provides the same env variables and template values as cloudsql postgres does in the cloud
+func cloudsqlPostgresLocalContext(res api.LocalResource) api.LocalResourceContext {
+	return api.LocalResourceContext{
+		Env: map[string]string{
+			"POSTGRES_USERNAME": res.User,
+			"POSTGRES_DATABASE": res.Database,
+			"POSTGRES_HOST":     res.Host,
+			"POSTGRES_PORT":     res.Port,
+			"POSTGRES_PASSWORD": res.Password,
+			"PGHOST":            res.Host,
+			"PGPORT":            res.Port,
+			"PGDATABASE":        res.Database,
+			"PGUSER":            res.User,
+			"PGPASSWORD":        res.Password,
+		},
+		TplValues: map[string]string{
+			"password": res.Password,
+			"user":     res.User,
+			"database": res.Database,
+			"host":     res.Host,
+			"port":     res.Port,
+		},
+	}
+}
+
+// redisLocalContext provides the same env variables and template values as memorystore redis does in the cloud
+func redisLocalContext(res api.LocalResource) api.LocalResourceContext {
+	return api.LocalResourceContext{
+		Env: map[string]string{
+			"REDIS_HOST": res.Host,
+			"REDIS_PORT": res.Port,
+		},
+		TplValues: map[string]string{
+			"host": res.Host,
+			"port": res.Port,
+		},
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package gcloud
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/api/option"
+	"google.golang.org/api/secretmanager/v1"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+type secretManagerSource struct {
+	config *SecretsProviderConfig
+}
+
+// NewSecretManagerSource returns reader of GCP Secret Manager, paths are names of secrets in the project of config
+// (latest version is read) or full resource names of secret versions
+func NewSecretManagerSource(config api.Config) (api.SecretSource, error) {
+	cfg, ok := config.Config.(*SecretsProviderConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert gcp secret manager config %T", config.Config)
+	}
+	return &secretManagerSource{config: cfg}, nil
+}
+
+func (s *secretManagerSource) ReadSecret(ctx context.Context, path string) (string, error) {
+	var opts []option.ClientOption
+	if s.config.Credentials.Credentials.Credentials != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(s.config.Credentials.Credentials.Credentials))) //nolint:staticcheck // SA1019: no in-memory replacement available
+	}
+	service, err := secretmanager.NewService(ctx, opts...)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to initialize secret manager client")
+	}
+
+	name := path
+	if !strings.HasPrefix(path, "projects/") {
+		name = fmt.Sprintf("projects/%s/secrets/%s", s.config.ProjectId, path)
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	version, err := service.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to access secret %q in secret manager", name)
+	}
+	if version.Payload == nil {
+		return "", errors.Errorf("secret %q has no payload", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(version.Payload.Data)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decode payload of secret %q", name)
+	}
+	return string(data), nil
+}
@@ -28,4 +28,8 @@ func init() {
 	api.RegisterCloudComposeConverter(api.CloudComposeConfigRegister{
 		TemplateTypeKubernetesCloudrun: ToKubernetesRunConfig,
 	})
+
+	api.RegisterDeployInputReader(api.ConfigRegisterMap{
+		TemplateTypeKubernetesCloudrun: ReadKubeRunInput,
+	})
 }
@@ -245,3 +245,8 @@ func ToKubernetesRunConfig(tpl any, composeCfg compose.Config, stackCfg *api.Sta
 
 	return res, nil
 }
+
+// ReadKubeRunInput reads KubeRunInput previously produced by ToKubernetesRunConfig
+func ReadKubeRunInput(config *api.Config) (api.Config, error) {
+	return api.ConvertConfig(config, &KubeRunInput{})
+}
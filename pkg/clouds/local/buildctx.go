@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package local
+
+import (
+	"archive/tar"
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// tarBuildContext streams build context directory as tar archive skipping paths matched by .dockerignore,
+// returns path of the dockerfile within the context
+func tarBuildContext(contextDir, dockerfile string) (io.ReadCloser, string, error) {
+	if filepath.IsAbs(dockerfile) {
+		rel, err := filepath.Rel(contextDir, dockerfile)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return nil, "", errors.Errorf("dockerfile %q must be within build context %q", dockerfile, contextDir)
+		}
+		dockerfile = rel
+	}
+	if _, err := os.Stat(filepath.Join(contextDir, dockerfile)); err != nil {
+		return nil, "", errors.Wrapf(err, "failed to find dockerfile %q in %q", dockerfile, contextDir)
+	}
+	ignored, err := readDockerignore(contextDir)
+	if err != nil {
+		return nil, "", err
+	}
+	dockerfile = filepath.ToSlash(dockerfile)
+
+	reader, writer := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(writer)
+		err := filepath.Walk(contextDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(contextDir, path)
+			if err != nil || rel == "." {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if rel != dockerfile && isIgnored(rel, ignored) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			link := ""
+			if info.Mode()&os.ModeSymlink != 0 {
+				if link, err = os.Readlink(path); err != nil {
+					return err
+				}
+			}
+			header, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			header.Name = rel
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		_ = writer.CloseWithError(err)
+	}()
+	return reader, dockerfile, nil
+}
+
+// readDockerignore returns patterns of .dockerignore (exclusions with `!` are not supported)
+func readDockerignore(contextDir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(contextDir, ".dockerignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read .dockerignore")
+	}
+	defer f.Close()
+	var res []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		res = append(res, strings.Trim(filepath.ToSlash(filepath.Clean(line)), "/"))
+	}
+	return res, scanner.Err()
+}
+
+// isIgnored returns true if path or any of its parent directories matches any of the patterns
+func isIgnored(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		for p := path; p != "." && p != "/"; p = filepath.ToSlash(filepath.Dir(p)) {
+			if matched, _ := filepath.Match(pattern, p); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/compose"
+	"github.com/simple-container-com/api/pkg/provisioner/placeholders"
+	"github.com/simple-container-com/api/pkg/template"
+)
+
+const (
+	LabelProject = "simple-container.com/local-project"
+	LabelService = "simple-container.com/local-service"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Project is the client stack prepared to run locally: stand-ins of the used resources and services of the compose file
+type Project struct {
+	// Name is used as the name of the network and prefix of the containers
+	Name     string
+	StandIns []StandIn
+	Services []Service
+	// Warnings describe parts of the stack which cannot run locally
+	Warnings []string
+}
+
+// StandIn is the container substituting cloud resource
+type StandIn struct {
+	ResourceName  string
+	ResourceType  string
+	ContainerName string
+	Image         string
+	Env           []string
+	HealthCheck   []string
+}
+
+// Service is the container of the compose service the stack runs
+type Service struct {
+	Name          string
+	ContainerName string
+	// Image is either the image to pull or the tag of the image built from BuildContext
+	Image        string
+	BuildContext string
+	Dockerfile   string
+	BuildArgs    map[string]*string
+	Entrypoint   []string
+	Command      []string
+	Env          map[string]string
+	Ports        []Port
+}
+
+type Port struct {
+	Container int
+	Host      string
+	Protocol  string
+}
+
+type ProjectParams struct {
+	StackName   string
+	Environment string
+	Config      *api.StackConfigCompose
+	Compose     compose.Config
+	// Resources are resources declared in server.yaml of the parent stack for the environment stack uses
+	Resources map[string]api.ResourceDescriptor
+}
+
+// ProjectName returns name of the local project of the stack in the environment
+func ProjectName(stackName, env string) string {
+	return invalidNameChars.ReplaceAllString(fmt.Sprintf("sc-%s-%s", stackName, env), "-")
+}
+
+// NewProject prepares stack to run locally: resources in `uses` are substituted with stand-ins providing
+// the same env variables and ${resource:...} placeholders as in the cloud, services listed in `runs`
+// get env of the compose file, stand-ins and the stack (in the increasing order of precedence)
+func NewProject(params ProjectParams) (*Project, error) {
+	if params.Compose.Project == nil {
+		return nil, errors.Errorf("compose config is nil")
+	}
+	res := &Project{Name: ProjectName(params.StackName, params.Environment)}
+
+	resourcesEnv := make(map[string]string)
+	tplValues := make(map[string]map[string]string)
+	uses := lo.Uniq(params.Config.Uses)
+	sort.Strings(uses)
+	for _, resName := range uses {
+		desc, found := params.Resources[resName]
+		if !found {
+			return nil, errors.Errorf("resource %q used by stack %q is not declared in parent stack", resName, params.StackName)
+		}
+		standIn, found := api.LocalStandInFor(desc.Type)
+		if !found {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("resource %q of type %q cannot run locally, its env variables are not provided", resName, desc.Type))
+			continue
+		}
+		containerName := res.containerName(resName)
+		spec, err := newStandInSpec(standIn.Kind, params.StackName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to init stand-in of resource %q", resName)
+		}
+		resCtx := standIn.Context(api.LocalResource{
+			Descriptor: desc,
+			StackName:  params.StackName,
+			Host:       containerName,
+			Port:       strconv.Itoa(spec.port),
+			User:       spec.user,
+			Password:   spec.password,
+			Database:   spec.database,
+		})
+		for name, value := range resCtx.Env {
+			if _, exists := resourcesEnv[name]; !exists {
+				resourcesEnv[name] = value
+			}
+		}
+		tplValues[resName] = resCtx.TplValues
+		res.StandIns = append(res.StandIns, StandIn{
+			ResourceName:  resName,
+			ResourceType:  desc.Type,
+			ContainerName: containerName,
+			Image:         spec.image,
+			Env:           spec.env,
+			HealthCheck:   spec.healthCheck,
+		})
+	}
+	if len(params.Config.Dependencies) > 0 {
+		res.Warnings = append(res.Warnings, "dependencies on resources of other stacks cannot run locally, ${dependency:...} placeholders are not resolved")
+	}
+
+	stackEnv := lo.Assign(params.Config.Env, params.Config.Secrets)
+	if err := resolveResourcePlaceholders(&stackEnv, tplValues); err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve placeholders of stack %q", params.StackName)
+	}
+
+	services := lo.Associate(params.Compose.Project.Services, func(svc types.ServiceConfig) (string, types.ServiceConfig) {
+		return svc.Name, svc
+	})
+	for _, svcName := range params.Config.Runs {
+		svc, found := services[svcName]
+		if !found {
+			return nil, errors.Errorf("service %s not found in docker-compose config", svcName)
+		}
+		service, err := res.toService(svc, params.Compose.Project.WorkingDir)
+		if err != nil {
+			return nil, err
+		}
+		service.Env = lo.Assign(service.Env, resourcesEnv, stackEnv)
+		res.Services = append(res.Services, service)
+	}
+	if len(res.Services) == 0 {
+		return nil, errors.Errorf("stack %q does not run any services, `runs` must list services of the compose file", params.StackName)
+	}
+	return res, nil
+}
+
+func (p *Project) containerName(name string) string {
+	return invalidNameChars.ReplaceAllString(fmt.Sprintf("%s-%s", p.Name, name), "-")
+}
+
+func (p *Project) toService(svc types.ServiceConfig, workingDir string) (Service, error) {
+	res := Service{
+		Name:          svc.Name,
+		ContainerName: p.containerName(svc.Name),
+		Image:         svc.Image,
+		Entrypoint:    svc.Entrypoint,
+		Command:       svc.Command,
+		Env:           make(map[string]string),
+	}
+	for name, value := range svc.Environment {
+		if value != nil {
+			res.Env[name] = *value
+		} else if hostValue, found := os.LookupEnv(name); found {
+			res.Env[name] = hostValue
+		}
+	}
+	if svc.Build != nil {
+		res.BuildContext = svc.Build.Context
+		if !filepath.IsAbs(res.BuildContext) {
+			res.BuildContext = filepath.Join(workingDir, res.BuildContext)
+		}
+		res.Dockerfile = lo.Ternary(svc.Build.Dockerfile != "", svc.Build.Dockerfile, "Dockerfile")
+		res.BuildArgs = svc.Build.Args
+		res.Image = fmt.Sprintf("%s:local", strings.ToLower(res.ContainerName))
+	} else if res.Image == "" {
+		return res, errors.Errorf("service %s has neither image nor build in docker-compose config", svc.Name)
+	}
+	for _, port := range svc.Ports {
+		res.Ports = append(res.Ports, Port{
+			Container: int(port.Target),
+			// unlike docker compose, unpublished ports are published on the same port to match the cloud
+			Host:     lo.Ternary(port.Published != "", port.Published, strconv.Itoa(int(port.Target))),
+			Protocol: lo.Ternary(port.Protocol != "", port.Protocol, "tcp"),
+		})
+	}
+	return res, nil
+}
+
+// resolveResourcePlaceholders resolves ${resource:<name>.<key>} placeholders the same way compute processors do in the cloud
+func resolveResourcePlaceholders(obj any, tplValues map[string]map[string]string) error {
+	return placeholders.New().Apply(obj, placeholders.WithExtensions(map[string]template.Extension{
+		"resource": func(noSubs string, path string, defaultValue *string) (string, error) {
+			pathParts := strings.SplitN(path, ".", 2)
+			if len(pathParts) < 2 {
+				return noSubs, fmt.Errorf("resource placeholder requires format ${resource:name.property}, got: %s", path)
+			}
+			if values, ok := tplValues[pathParts[0]]; ok {
+				if value, ok := values[pathParts[1]]; ok {
+					return value, nil
+				}
+			}
+			return noSubs, nil
+		},
+	}))
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package local
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/types"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/compose"
+)
+
+func init() {
+	api.RegisterLocalStandIns(api.LocalStandInsRegister{
+		"test-postgres": {Kind: api.LocalStandInPostgres, Context: func(res api.LocalResource) api.LocalResourceContext {
+			return api.LocalResourceContext{
+				Env: map[string]string{
+					"PGHOST":     res.Host,
+					"PGPORT":     res.Port,
+					"PGPASSWORD": res.Password,
+				},
+				TplValues: map[string]string{
+					"user": res.User,
+					"host": res.Host,
+				},
+			}
+		}},
+	})
+}
+
+func testComposeConfig() compose.Config {
+	return compose.Config{Project: &types.Project{
+		WorkingDir: "/stacks/billing",
+		Services: types.Services{
+			{
+				Name:  "api",
+				Build: &types.BuildConfig{Context: "."},
+				Environment: types.MappingWithEquals{
+					"PGHOST":    lo.ToPtr("localhost"),
+					"LOG_LEVEL": lo.ToPtr("debug"),
+				},
+				Ports: []types.ServicePortConfig{{Target: 8080}, {Target: 9090, Published: "19090"}},
+			},
+			{
+				Name:  "worker",
+				Image: "billing-worker:latest",
+			},
+		},
+	}}
+}
+
+func TestNewProject(t *testing.T) {
+	RegisterTestingT(t)
+
+	project, err := NewProject(ProjectParams{
+		StackName:   "billing",
+		Environment: "local",
+		Compose:     testComposeConfig(),
+		Config: &api.StackConfigCompose{
+			Runs: []string{"api"},
+			Uses: []string{"db", "bucket"},
+			Env: map[string]string{
+				"DB_USER":   "${resource:db.user}",
+				"LOG_LEVEL": "info",
+			},
+			Secrets: map[string]string{
+				"DB_URL": "postgres://${resource:db.host}/billing",
+			},
+		},
+		Resources: map[string]api.ResourceDescriptor{
+			"db":     {Type: "test-postgres", Name: "db"},
+			"bucket": {Type: "test-bucket", Name: "bucket"},
+		},
+	})
+	Expect(err).To(BeNil())
+
+	Expect(project.Name).To(Equal("sc-billing-local"))
+	Expect(project.Warnings).To(HaveLen(1))
+	Expect(project.Warnings[0]).To(ContainSubstring(`resource "bucket" of type "test-bucket" cannot run locally`))
+
+	Expect(project.StandIns).To(HaveLen(1))
+	standIn := project.StandIns[0]
+	Expect(standIn.ContainerName).To(Equal("sc-billing-local-db"))
+	Expect(standIn.Image).To(HavePrefix("postgres:"))
+	Expect(standIn.Env).To(ContainElements("POSTGRES_USER=billing", "POSTGRES_DB=billing"))
+
+	Expect(project.Services).To(HaveLen(1))
+	svc := project.Services[0]
+	Expect(svc.ContainerName).To(Equal("sc-billing-local-api"))
+	Expect(svc.Image).To(Equal("sc-billing-local-api:local"))
+	Expect(svc.BuildContext).To(Equal("/stacks/billing"))
+	Expect(svc.Dockerfile).To(Equal("Dockerfile"))
+	Expect(svc.Ports).To(Equal([]Port{
+		{Container: 8080, Host: "8080", Protocol: "tcp"},
+		{Container: 9090, Host: "19090", Protocol: "tcp"},
+	}))
+
+	// stand-in overrides compose env, stack overrides both
+	Expect(svc.Env).To(HaveKeyWithValue("PGHOST", "sc-billing-local-db"))
+	Expect(svc.Env).To(HaveKeyWithValue("PGPORT", "5432"))
+	Expect(svc.Env).To(HaveKeyWithValue("PGPASSWORD", Not(BeEmpty())))
+	Expect(svc.Env).To(HaveKeyWithValue("LOG_LEVEL", "info"))
+	Expect(svc.Env).To(HaveKeyWithValue("DB_USER", "billing"))
+	Expect(svc.Env).To(HaveKeyWithValue("DB_URL", "postgres://sc-billing-local-db/billing"))
+}
+
+func TestNewProjectErrors(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("undeclared resource", func(t *testing.T) {
+		_, err := NewProject(ProjectParams{
+			StackName: "billing", Environment: "local", Compose: testComposeConfig(),
+			Config: &api.StackConfigCompose{Runs: []string{"api"}, Uses: []string{"db"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring(`resource "db" used by stack "billing" is not declared`)))
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		_, err := NewProject(ProjectParams{
+			StackName: "billing", Environment: "local", Compose: testComposeConfig(),
+			Config: &api.StackConfigCompose{Runs: []string{"frontend"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("service frontend not found")))
+	})
+
+	t.Run("no services", func(t *testing.T) {
+		_, err := NewProject(ProjectParams{
+			StackName: "billing", Environment: "local", Compose: testComposeConfig(),
+			Config: &api.StackConfigCompose{},
+		})
+		Expect(err).To(MatchError(ContainSubstring("does not run any services")))
+	})
+}
+
+func TestIsIgnored(t *testing.T) {
+	RegisterTestingT(t)
+
+	patterns := []string{"node_modules", "*.log", "build/tmp"}
+	Expect(isIgnored("node_modules", patterns)).To(BeTrue())
+	Expect(isIgnored("node_modules/lib/index.js", patterns)).To(BeTrue())
+	Expect(isIgnored("app.log", patterns)).To(BeTrue())
+	Expect(isIgnored("build/tmp/out", patterns)).To(BeTrue())
+	Expect(isIgnored("build/main.go", patterns)).To(BeFalse())
+	Expect(isIgnored("src/app.go", patterns)).To(BeFalse())
+}
@@ -0,0 +1,334 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package local
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/client"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+const (
+	standInReadyTimeout = 3 * time.Minute
+	standInPollInterval = 2 * time.Second
+)
+
+// Runner runs local projects via Docker API
+type Runner struct {
+	docker *client.Client
+	log    logger.Logger
+	output io.Writer
+}
+
+func NewRunner(log logger.Logger) (*Runner, error) {
+	docker, err := client.New(client.FromEnv)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to init docker client")
+	}
+	return &Runner{docker: docker, log: log, output: os.Stdout}, nil
+}
+
+// Up starts stand-ins and services of the project (replacing containers left by the previous run).
+// Unless detached, logs of services are followed until they exit or ctx is canceled, after which project is removed.
+func (r *Runner) Up(ctx context.Context, project *Project, params api.UpParams) error {
+	for _, warning := range project.Warnings {
+		r.log.Warn(ctx, "%s", warning)
+	}
+	if err := r.Down(ctx, project.Name); err != nil {
+		return err
+	}
+	serviceIDs, err := r.start(ctx, project, params)
+	if err != nil {
+		r.cleanup(ctx, project.Name)
+		return err
+	}
+	if params.Detach {
+		r.log.Info(ctx, "%s", color.GreenFmt("Stack is running locally as %q, run `sc down -s %s -e %s` to remove it",
+			project.Name, params.StackName, params.Environment))
+		return nil
+	}
+	defer r.cleanup(ctx, project.Name)
+	r.log.Info(ctx, "%s", color.GreenFmt("Stack is running locally as %q, press Ctrl+C to stop", project.Name))
+	r.followLogs(ctx, project, serviceIDs)
+	return nil
+}
+
+// Down removes containers and network of the project
+func (r *Runner) Down(ctx context.Context, projectName string) error {
+	filters := make(client.Filters).Add("label", fmt.Sprintf("%s=%s", LabelProject, projectName))
+	containers, err := r.docker.ContainerList(ctx, client.ContainerListOptions{All: true, Filters: filters})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list containers of %q", projectName)
+	}
+	for _, c := range containers.Items {
+		r.log.Debug(ctx, "removing container %s of %q", c.ID, projectName)
+		if _, err := r.docker.ContainerRemove(ctx, c.ID, client.ContainerRemoveOptions{Force: true, RemoveVolumes: true}); err != nil {
+			return errors.Wrapf(err, "failed to remove container %s of %q", c.ID, projectName)
+		}
+	}
+	networks, err := r.docker.NetworkList(ctx, client.NetworkListOptions{Filters: filters})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list networks of %q", projectName)
+	}
+	for _, n := range networks.Items {
+		if _, err := r.docker.NetworkRemove(ctx, n.ID, client.NetworkRemoveOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to remove network %s of %q", n.ID, projectName)
+		}
+	}
+	return nil
+}
+
+// cleanup removes project even if ctx is already canceled
+func (r *Runner) cleanup(ctx context.Context, projectName string) {
+	r.log.Info(ctx, "Removing local stack %q...", projectName)
+	if err := r.Down(context.Background(), projectName); err != nil {
+		r.log.Error(ctx, "failed to remove local stack %q: %v", projectName, err)
+	}
+}
+
+// start runs stand-ins, waits for them to become ready and runs services, returns IDs of services' containers
+func (r *Runner) start(ctx context.Context, project *Project, params api.UpParams) (map[string]string, error) {
+	labels := map[string]string{LabelProject: project.Name}
+	if _, err := r.docker.NetworkCreate(ctx, project.Name, client.NetworkCreateOptions{Driver: "bridge", Labels: labels}); err != nil {
+		return nil, errors.Wrapf(err, "failed to create network %q", project.Name)
+	}
+
+	standInIDs := make(map[string]string, len(project.StandIns))
+	for _, standIn := range project.StandIns {
+		r.log.Info(ctx, "Starting %s stand-in of resource %q...", standIn.Image, standIn.ResourceName)
+		if err := r.ensureImage(ctx, standIn.Image, params.Pull); err != nil {
+			return nil, err
+		}
+		id, err := r.run(ctx, project, standIn.ContainerName, standIn.ResourceName, &container.Config{
+			Image: standIn.Image,
+			Env:   standIn.Env,
+			Healthcheck: &container.HealthConfig{
+				Test:     standIn.HealthCheck,
+				Interval: standInPollInterval,
+				Timeout:  5 * time.Second,
+				Retries:  int(standInReadyTimeout / standInPollInterval),
+			},
+		}, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to start stand-in of resource %q", standIn.ResourceName)
+		}
+		standInIDs[standIn.ResourceName] = id
+	}
+	for _, standIn := range project.StandIns {
+		if err := r.waitHealthy(ctx, standInIDs[standIn.ResourceName]); err != nil {
+			return nil, errors.Wrapf(err, "stand-in of resource %q is not ready", standIn.ResourceName)
+		}
+	}
+
+	serviceIDs := make(map[string]string, len(project.Services))
+	for _, svc := range project.Services {
+		if svc.BuildContext != "" {
+			if err := r.build(ctx, svc, params.Pull); err != nil {
+				return nil, err
+			}
+		} else if err := r.ensureImage(ctx, svc.Image, params.Pull); err != nil {
+			return nil, err
+		}
+		exposed := network.PortSet{}
+		bindings := network.PortMap{}
+		for _, p := range svc.Ports {
+			port, err := network.ParsePort(fmt.Sprintf("%d/%s", p.Container, p.Protocol))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid port %d of service %q", p.Container, svc.Name)
+			}
+			exposed[port] = struct{}{}
+			bindings[port] = append(bindings[port], network.PortBinding{HostPort: p.Host})
+		}
+		envNames := lo.Keys(svc.Env)
+		sort.Strings(envNames)
+		r.log.Info(ctx, "Starting service %q...", svc.Name)
+		id, err := r.run(ctx, project, svc.ContainerName, svc.Name, &container.Config{
+			Image:        svc.Image,
+			Entrypoint:   svc.Entrypoint,
+			Cmd:          svc.Command,
+			Env:          lo.Map(envNames, func(name string, _ int) string { return name + "=" + svc.Env[name] }),
+			ExposedPorts: exposed,
+			Tty:          true,
+		}, bindings)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to start service %q", svc.Name)
+		}
+		serviceIDs[svc.Name] = id
+		for _, p := range svc.Ports {
+			r.log.Info(ctx, "Service %q is listening on localhost:%s", svc.Name, p.Host)
+		}
+	}
+	return serviceIDs, nil
+}
+
+// run creates and starts container in the network of the project, where it is reachable by alias
+func (r *Runner) run(ctx context.Context, project *Project, name, alias string, cfg *container.Config, ports network.PortMap) (string, error) {
+	cfg.Labels = map[string]string{LabelProject: project.Name, LabelService: alias}
+	created, err := r.docker.ContainerCreate(ctx, client.ContainerCreateOptions{
+		Name:   name,
+		Config: cfg,
+		HostConfig: &container.HostConfig{
+			NetworkMode:  container.NetworkMode(project.Name),
+			PortBindings: ports,
+		},
+		NetworkingConfig: &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				project.Name: {Aliases: []string{alias}},
+			},
+		},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create container %q", name)
+	}
+	for _, warning := range created.Warnings {
+		r.log.Warn(ctx, "container %q: %s", name, warning)
+	}
+	if _, err := r.docker.ContainerStart(ctx, created.ID, client.ContainerStartOptions{}); err != nil {
+		return "", errors.Wrapf(err, "failed to start container %q", name)
+	}
+	return created.ID, nil
+}
+
+func (r *Runner) waitHealthy(ctx context.Context, id string) error {
+	deadline := time.Now().Add(standInReadyTimeout)
+	for {
+		res, err := r.docker.ContainerInspect(ctx, id, client.ContainerInspectOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to inspect container %s", id)
+		}
+		state := res.Container.State
+		switch {
+		case state == nil:
+		case !state.Running:
+			return errors.Errorf("container %s exited with code %d", id, state.ExitCode)
+		case state.Health != nil && string(state.Health.Status) == "healthy":
+			return nil
+		case state.Health != nil && string(state.Health.Status) == "unhealthy":
+			return errors.Errorf("container %s is unhealthy", id)
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("timed out waiting for container %s to become healthy", id)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(standInPollInterval):
+		}
+	}
+}
+
+func (r *Runner) ensureImage(ctx context.Context, image string, pull bool) error {
+	if !pull {
+		if _, err := r.docker.ImageInspect(ctx, image); err == nil {
+			return nil
+		}
+	}
+	r.log.Info(ctx, "Pulling image %q...", image)
+	resp, err := r.docker.ImagePull(ctx, image, client.ImagePullOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull image %q", image)
+	}
+	defer resp.Close()
+	if err := resp.Wait(ctx); err != nil {
+		return errors.Wrapf(err, "failed to pull image %q", image)
+	}
+	return nil
+}
+
+// buildMessage is a message of the image build stream
+type buildMessage struct {
+	Stream      string `json:"stream"`
+	Error       string `json:"error"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+func (r *Runner) build(ctx context.Context, svc Service, pull bool) error {
+	r.log.Info(ctx, "Building image %q of service %q...", svc.Image, svc.Name)
+	buildCtx, dockerfile, err := tarBuildContext(svc.BuildContext, svc.Dockerfile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to prepare build context of service %q", svc.Name)
+	}
+	defer buildCtx.Close()
+	resp, err := r.docker.ImageBuild(ctx, buildCtx, client.ImageBuildOptions{
+		Tags:       []string{svc.Image},
+		Dockerfile: dockerfile,
+		BuildArgs:  svc.BuildArgs,
+		Remove:     true,
+		PullParent: pull,
+		Labels:     map[string]string{LabelService: svc.Name},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to build image of service %q", svc.Name)
+	}
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg buildMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to read build output of service %q", svc.Name)
+		}
+		if msg.ErrorDetail != nil || msg.Error != "" {
+			return errors.Errorf("failed to build image of service %q: %s", svc.Name,
+				lo.Ternary(msg.Error != "", msg.Error, lo.FromPtr(msg.ErrorDetail).Message))
+		}
+		if line := strings.TrimSpace(msg.Stream); line != "" {
+			r.log.Debug(ctx, "%s: %s", svc.Name, line)
+		}
+	}
+}
+
+// followLogs prints logs of services prefixed with their names until all of them exit or ctx is canceled
+func (r *Runner) followLogs(ctx context.Context, project *Project, serviceIDs map[string]string) {
+	width := lo.Max(lo.Map(project.Services, func(svc Service, _ int) int { return len(svc.Name) }))
+	var outputLock sync.Mutex
+	var wg sync.WaitGroup
+	for _, svc := range project.Services {
+		wg.Add(1)
+		go func(name, id string) {
+			defer wg.Done()
+			prefix := color.BlueFmt("%-*s |", width, name)
+			logs, err := r.docker.ContainerLogs(ctx, id, client.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+			if err != nil {
+				r.log.Error(ctx, "failed to follow logs of service %q: %v", name, err)
+				return
+			}
+			defer logs.Close()
+			scanner := bufio.NewScanner(logs)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				outputLock.Lock()
+				_, _ = fmt.Fprintf(r.output, "%s %s\n", prefix, strings.TrimRight(scanner.Text(), "\r"))
+				outputLock.Unlock()
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if res, err := r.docker.ContainerInspect(ctx, id, client.ContainerInspectOptions{}); err == nil && res.Container.State != nil {
+				r.log.Warn(ctx, "service %q exited with code %d", name, res.Container.State.ExitCode)
+			}
+		}(svc.Name, serviceIDs[svc.Name])
+	}
+	wg.Wait()
+}
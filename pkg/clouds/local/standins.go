@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package local
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+// standInSpec describes container of the stand-in: image, credentials it is initialized with and how to check its readiness
+type standInSpec struct {
+	image       string
+	port        int
+	env         []string
+	healthCheck []string
+	user        string
+	password    string
+	database    string
+}
+
+// newStandInSpec returns spec of the stand-in with database and user named after the stack, as they are in the cloud
+func newStandInSpec(kind string, stackName string) (standInSpec, error) {
+	password, err := randomPassword()
+	if err != nil {
+		return standInSpec{}, err
+	}
+	switch kind {
+	case api.LocalStandInPostgres:
+		return standInSpec{
+			image: "postgres:16-alpine",
+			port:  5432,
+			env: []string{
+				"POSTGRES_USER=" + stackName,
+				"POSTGRES_PASSWORD=" + password,
+				"POSTGRES_DB=" + stackName,
+			},
+			healthCheck: []string{"CMD-SHELL", fmt.Sprintf("pg_isready -h 127.0.0.1 -U %q -d %q", stackName, stackName)},
+			user:        stackName,
+			password:    password,
+			database:    stackName,
+		}, nil
+	case api.LocalStandInMysql:
+		return standInSpec{
+			image: "mysql:8.4",
+			port:  3306,
+			env: []string{
+				"MYSQL_USER=" + stackName,
+				"MYSQL_PASSWORD=" + password,
+				"MYSQL_DATABASE=" + stackName,
+				"MYSQL_ROOT_PASSWORD=" + password,
+			},
+			// server listens on TCP only after initialization is complete
+			healthCheck: []string{"CMD-SHELL", "mysqladmin ping -h 127.0.0.1 --silent"},
+			user:        stackName,
+			password:    password,
+			database:    stackName,
+		}, nil
+	case api.LocalStandInRedis:
+		return standInSpec{
+			image:       "redis:7-alpine",
+			port:        6379,
+			healthCheck: []string{"CMD", "redis-cli", "ping"},
+		}, nil
+	case api.LocalStandInMongodb:
+		return standInSpec{
+			image: "mongo:7",
+			port:  27017,
+			env: []string{
+				"MONGO_INITDB_ROOT_USERNAME=" + stackName,
+				"MONGO_INITDB_ROOT_PASSWORD=" + password,
+				"MONGO_INITDB_DATABASE=" + stackName,
+			},
+			healthCheck: []string{"CMD", "mongosh", "--quiet", "--eval", "db.adminCommand('ping')"},
+			user:        stackName,
+			password:    password,
+			database:    stackName,
+		}, nil
+	default:
+		return standInSpec{}, errors.Errorf("unsupported stand-in kind %q", kind)
+	}
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrapf(err, "failed to generate password")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -14,4 +14,12 @@ func init() {
 		// mongodb
 		ResourceTypeMongodbAtlas: ReadAtlasConfig,
 	})
+
+	api.RegisterPolicyResourceTypes(api.PolicyResourceTypesRegister{
+		ResourceTypeMongodbAtlas: {"mongodbatlas:index/cluster:Cluster"},
+	})
+
+	api.RegisterLocalStandIns(api.LocalStandInsRegister{
+		ResourceTypeMongodbAtlas: {Kind: api.LocalStandInMongodb, Context: atlasLocalContext},
+	})
 }
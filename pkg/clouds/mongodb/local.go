@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package mongodb
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+// atlasLocalContext provides the same env variables and template values as mongodb atlas does in the cloud.
+// User of the local stand-in is created in admin database, hence authSource in the uri.
+func atlasLocalContext(res api.LocalResource) api.LocalResourceContext {
+	toUri := func(dbName string) string {
+		return fmt.Sprintf("mongodb://%s@%s/%s?authSource=admin",
+			url.UserPassword(res.User, res.Password).String(), net.JoinHostPort(res.Host, res.Port), dbName)
+	}
+	return api.LocalResourceContext{
+		Env: map[string]string{
+			"MONGO_USER":     res.User,
+			"MONGO_DATABASE": res.Database,
+			"MONGO_PASSWORD": res.Password,
+			"MONGO_URI":      toUri(res.Database),
+		},
+		TplValues: map[string]string{
+			"uri":      toUri(res.Database),
+			"dbName":   res.Database,
+			"password": res.Password,
+			"user":     res.User,
+			"oplogUri": toUri("local"),
+		},
+	}
+}
@@ -4,6 +4,8 @@
 package api
 
 import (
+	"github.com/samber/lo"
+
 	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 
 	"github.com/simple-container-com/api/pkg/api"
@@ -24,6 +26,9 @@ const (
 	// ClientStackTag identifies the client stack for nested stacks
 	ClientStackTag = "simple-container.com/client-stack"
 
+	// PromotedFromTag identifies the environment deployed images were promoted from
+	PromotedFromTag = "simple-container.com/promoted-from"
+
 	// GCP labels - cannot contain dots or slashes, using underscores instead
 	// GCPStackTag identifies the stack name
 	GCPStackTag = "simple_container_com_stack"
@@ -36,6 +41,9 @@ const (
 
 	// GCPClientStackTag identifies the client stack for nested stacks
 	GCPClientStackTag = "simple_container_com_client_stack"
+
+	// GCPPromotedFromTag identifies the environment deployed images were promoted from
+	GCPPromotedFromTag = "simple_container_com_promoted_from"
 )
 
 // Tags represents a set of tags/labels that can be applied to cloud resources
@@ -44,6 +52,8 @@ type Tags struct {
 	Environment string
 	ParentStack *string
 	ClientStack *string
+	// PromotedFrom is the environment deployed images were promoted from
+	PromotedFrom *string
 }
 
 // ToAWSTags converts Tags to AWS tag format
@@ -61,6 +71,10 @@ func (t *Tags) ToAWSTags() sdk.StringMap {
 		tags[ClientStackTag] = sdk.String(*t.ClientStack)
 	}
 
+	if t.PromotedFrom != nil && *t.PromotedFrom != "" {
+		tags[PromotedFromTag] = sdk.String(*t.PromotedFrom)
+	}
+
 	return tags
 }
 
@@ -79,14 +93,19 @@ func (t *Tags) ToGCPLabels() map[string]string {
 		labels[GCPClientStackTag] = *t.ClientStack
 	}
 
+	if t.PromotedFrom != nil && *t.PromotedFrom != "" {
+		labels[GCPPromotedFromTag] = *t.PromotedFrom
+	}
+
 	return labels
 }
 
 // BuildTagsFromStackParams creates Tags from StackParams
 func BuildTagsFromStackParams(params api.StackParams) *Tags {
 	tags := &Tags{
-		StackName:   params.StackName,
-		Environment: params.Environment,
+		StackName:    params.StackName,
+		Environment:  params.Environment,
+		PromotedFrom: lo.EmptyableToPtr(params.PromotedFrom),
 	}
 	return tags
 }
@@ -94,10 +113,11 @@ func BuildTagsFromStackParams(params api.StackParams) *Tags {
 // BuildTagsFromStackParamsWithParent creates Tags from StackParams with parent and client stack info
 func BuildTagsFromStackParamsWithParent(params api.StackParams, parentStack, clientStack *string) *Tags {
 	tags := &Tags{
-		StackName:   params.StackName,
-		Environment: params.Environment,
-		ParentStack: parentStack,
-		ClientStack: clientStack,
+		StackName:    params.StackName,
+		Environment:  params.Environment,
+		ParentStack:  parentStack,
+		ClientStack:  clientStack,
+		PromotedFrom: lo.EmptyableToPtr(params.PromotedFrom),
 	}
 	return tags
 }
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/s3"
+	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/aws"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+)
+
+// adoptedS3BucketIgnoreChanges are bucket settings (ACL, website, CORS, policies etc.) left as they are on adoption
+var adoptedS3BucketIgnoreChanges = []string{
+	"acl", "grants", "policy", "website", "corsRules", "lifecycleRules", "versioning", "loggings",
+	"serverSideEncryptionConfiguration", "replicationConfiguration", "objectLockConfiguration",
+	"accelerationStatus", "requestPayer", "forceDestroy", "tags",
+}
+
+// validateAdoptedS3Bucket rejects settings which cannot be applied to an adopted bucket: its website
+// and policy are left as they are
+func validateAdoptedS3Bucket(bucketCfg *aws.S3Bucket, descriptorName string) error {
+	if err := pApi.ValidateAdoptionConfig(bucketCfg.Adopt, bucketCfg.BucketName, descriptorName); err != nil {
+		return err
+	}
+	if bucketCfg.StaticSiteConfig != nil {
+		return errors.Errorf("static site is not supported for adopted bucket %q of resource %q, "+
+			"remove static site settings or configure the website of the bucket outside of Simple Container", bucketCfg.BucketName, descriptorName)
+	}
+	if bucketCfg.AllowOnlyHttps {
+		return errors.Errorf("allowOnlyHttps is not supported for adopted bucket %q of resource %q: its bucket policy is left unchanged",
+			bucketCfg.BucketName, descriptorName)
+	}
+	return nil
+}
+
+// AdoptS3Bucket imports an existing S3 bucket into Pulumi state and creates a user having access to it
+func AdoptS3Bucket(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params pApi.ProvisionParams) (*api.ResourceOutput, error) {
+	if input.Descriptor.Type != aws.ResourceTypeS3Bucket {
+		return nil, errors.Errorf("unsupported bucket type %q", input.Descriptor.Type)
+	}
+
+	bucketCfg, ok := input.Descriptor.Config.Config.(*aws.S3Bucket)
+	if !ok {
+		return nil, errors.Errorf("failed to convert bucket config for %q", input.Descriptor.Type)
+	}
+
+	if err := validateAdoptedS3Bucket(bucketCfg, input.Descriptor.Name); err != nil {
+		return nil, err
+	}
+
+	// Use identical naming functions as provisioning to ensure export compatibility
+	bucketName := input.ToResName(lo.If(bucketCfg.Name == "", input.Descriptor.Name).Else(bucketCfg.Name))
+	opts := []sdk.ResourceOption{sdk.Provider(params.Provider)}
+
+	pApi.LogAdoptionWarnings(ctx, input, params, "S3 bucket", bucketCfg.BucketName)
+
+	params.Log.Info(ctx.Context(), "adopting existing S3 bucket %q", bucketCfg.BucketName)
+
+	adoptionOpts := pApi.AdoptionProtectionOptions(adoptedS3BucketIgnoreChanges)
+	bucket, err := s3.NewBucket(ctx, bucketName, &s3.BucketArgs{
+		Bucket: sdk.String(bucketCfg.BucketName),
+	}, append(append(opts, sdk.Import(sdk.ID(bucketCfg.BucketName))), adoptionOpts...)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to import S3 bucket %q", bucketCfg.BucketName)
+	}
+
+	// Export bucket name and region (same as provisioning)
+	ctx.Export(toBucketNameExport(bucketName), bucket.Bucket)
+	ctx.Export(toBucketRegionExport(bucketName), bucket.Region)
+
+	var tags sdk.StringMap
+	if input.StackParams != nil {
+		tags = pApi.BuildTagsFromStackParams(*input.StackParams).ToAWSTags()
+	}
+
+	params.Log.Info(ctx.Context(), "configure user having write access to adopted s3 bucket %q...", bucketCfg.BucketName)
+	user, err := iam.NewUser(ctx, fmt.Sprintf("%s-user", bucketName), &iam.UserArgs{
+		ForceDestroy: sdk.BoolPtr(true),
+		Tags:         tags,
+	}, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to provision user for bucket %q", bucketCfg.BucketName)
+	}
+	ctx.Export(toBucketUserExport(bucketName), user.Name)
+
+	accessKey, err := iam.NewAccessKey(ctx, fmt.Sprintf("%s-access-key", bucketName), &iam.AccessKeyArgs{
+		User: user.ID(),
+	}, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to provision access key for bucket %q", bucketCfg.BucketName)
+	}
+	ctx.Export(toBucketAccessKeySecretExport(bucketName), accessKey.Secret)
+	ctx.Export(toBucketAccessKeyIdExport(bucketName), accessKey.ID())
+
+	// Access is granted with the policy of the user, so that the existing bucket policy stays untouched
+	_, err = iam.NewUserPolicy(ctx, fmt.Sprintf("%s-user-policy", bucketName), &iam.UserPolicyArgs{
+		User: user.Name,
+		Policy: bucket.Arn.ApplyT(func(bucketArn string) (string, error) {
+			policy, err := json.Marshal(map[string]any{
+				"Version": "2012-10-17",
+				"Statement": []map[string]any{
+					{
+						"Effect":   "Allow",
+						"Action":   "s3:*",
+						"Resource": []string{bucketArn, bucketArn + "/*"},
+					},
+				},
+			})
+			return string(policy), err
+		}).(sdk.StringOutput),
+	}, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to provision user policy for bucket %q", bucketCfg.BucketName)
+	}
+
+	params.Log.Info(ctx.Context(), "successfully adopted S3 bucket %q", bucketCfg.BucketName)
+
+	return &api.ResourceOutput{Ref: &PrivateBucketOutput{
+		Bucket:          bucket,
+		User:            user,
+		AccessKey:       accessKey,
+		AccessKeySecret: accessKey.Secret,
+	}}, nil
+}
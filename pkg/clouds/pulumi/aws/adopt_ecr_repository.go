@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ecr"
+	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/aws"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+)
+
+// adoptedEcrRepositoryIgnoreChanges are settings of an adopted repository which are left as they are
+var adoptedEcrRepositoryIgnoreChanges = []string{
+	"forceDelete", "imageTagMutability", "imageScanningConfiguration", "encryptionConfigurations", "tags",
+}
+
+// AdoptEcrRepository imports an existing ECR repository into Pulumi state without modifying it
+func AdoptEcrRepository(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params pApi.ProvisionParams) (*api.ResourceOutput, error) {
+	if input.Descriptor.Type != aws.ResourceTypeEcrRepository {
+		return nil, errors.Errorf("unsupported ECR repository type %q", input.Descriptor.Type)
+	}
+
+	ecrCfg, ok := input.Descriptor.Config.Config.(*aws.EcrRepository)
+	if !ok {
+		return nil, errors.Errorf("failed to convert ECR repository config for %q", input.Descriptor.Type)
+	}
+
+	if err := pApi.ValidateAdoptionConfig(ecrCfg.Adopt, ecrCfg.RepositoryName, input.Descriptor.Name); err != nil {
+		return nil, err
+	}
+
+	// Use identical naming as provisioning to ensure export compatibility
+	ecrRepoName := fmt.Sprintf("%s-%s", stack.Name, ecrCfg.Name)
+
+	pApi.LogAdoptionWarnings(ctx, input, params, "ECR repository", ecrCfg.RepositoryName)
+
+	params.Log.Info(ctx.Context(), "adopting existing ECR repository %q", ecrCfg.RepositoryName)
+
+	adoptionOpts := pApi.AdoptionProtectionOptions(adoptedEcrRepositoryIgnoreChanges)
+	opts := []sdk.ResourceOption{sdk.Provider(params.Provider), sdk.DependsOn(params.ComputeContext.Dependencies())}
+	ecrRepo, err := ecr.NewRepository(ctx, ecrRepoName, &ecr.RepositoryArgs{
+		Name: sdk.String(ecrCfg.RepositoryName),
+	}, append(append(opts, sdk.Import(sdk.ID(ecrCfg.RepositoryName))), adoptionOpts...)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to import ECR repository %q", ecrCfg.RepositoryName)
+	}
+	ctx.Export(toEcrRepositoryURLExport(ecrRepoName), ecrRepo.RepositoryUrl)
+	ctx.Export(toEcrRepositoryIDExport(ecrRepoName), ecrRepo.RegistryId)
+
+	// Existing lifecycle policy is only replaced when it is configured explicitly
+	if ecrCfg.LifecyclePolicy != nil {
+		lifecyclePolicyDocument, err := json.Marshal(ecrCfg.LifecyclePolicy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal ECR lifecycle policy for ECR registry %s", ecrRepoName)
+		}
+		_, err = ecr.NewLifecyclePolicy(ctx, fmt.Sprintf("%s-lc-policy", ecrRepoName), &ecr.LifecyclePolicyArgs{
+			Repository: ecrRepo.Name,
+			Policy:     sdk.String(lifecyclePolicyDocument),
+		}, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create ecr lifecycle policy for ECR registry %s", ecrRepoName)
+		}
+	}
+
+	registryPassword := ecrRegistryPassword(ctx, params, ecrRepo, ecrRepoName)
+	ctx.Export(toEcrRepositoryPasswordExport(ecrRepoName), sdk.ToSecret(registryPassword))
+
+	params.Log.Info(ctx.Context(), "successfully adopted ECR repository %q", ecrCfg.RepositoryName)
+
+	return &api.ResourceOutput{Ref: EcsFargateRepository{Repository: ecrRepo, Password: registryPassword}}, nil
+}
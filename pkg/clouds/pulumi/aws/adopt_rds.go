@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/rds"
+	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/aws"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+)
+
+// adoptedRdsInstanceIgnoreChanges are properties of an adopted instance which are never changed
+var adoptedRdsInstanceIgnoreChanges = []string{
+	// Master password cannot be read from AWS
+	"password",
+	// Instance configuration that might drift or be managed outside of Pulumi
+	"instanceClass", "allocatedStorage", "maxAllocatedStorage", "engineVersion", "storageEncrypted",
+	"parameterGroupName", "optionGroupName", "dbSubnetGroupName", "vpcSecurityGroupIds",
+	"backupRetentionPeriod", "backupWindow", "maintenanceWindow", "multiAz", "publiclyAccessible",
+	"skipFinalSnapshot", "finalSnapshotIdentifier", "applyImmediately", "tags",
+}
+
+type adoptRdsInstanceInput struct {
+	resourceType       string
+	resName            string
+	instanceIdentifier string
+	password           string
+	databaseName       *string
+}
+
+// AdoptRdsPostgres imports an existing RDS Postgres instance into Pulumi state without modifying it
+func AdoptRdsPostgres(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params pApi.ProvisionParams) (*api.ResourceOutput, error) {
+	if input.Descriptor.Type != aws.ResourceTypeRdsPostgres {
+		return nil, errors.Errorf("unsupported resource type %q", input.Descriptor.Type)
+	}
+
+	postgresCfg, ok := input.Descriptor.Config.Config.(*aws.PostgresConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert postgres config for %q", input.Descriptor.Type)
+	}
+
+	if err := pApi.ValidateAdoptionConfig(postgresCfg.Adopt, postgresCfg.InstanceIdentifier, input.Descriptor.Name); err != nil {
+		return nil, err
+	}
+
+	// Use identical naming functions as provisioning to ensure export compatibility
+	postgresResName := lo.If(postgresCfg.Name == "", input.Descriptor.Name).Else(postgresCfg.Name)
+	postgresName := toRdsPostgresName(postgresResName, input.StackParams.Environment)
+
+	instance, err := adoptRdsInstance(ctx, input, params, adoptRdsInstanceInput{
+		resourceType:       "RDS Postgres instance",
+		resName:            postgresName,
+		instanceIdentifier: postgresCfg.InstanceIdentifier,
+		password:           postgresCfg.Password,
+		databaseName:       postgresCfg.DatabaseName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Export(toPostgresInstanceArnExport(postgresName), instance.Arn)
+	ctx.Export(toPostgresInstanceEndpointExport(postgresName), instance.Endpoint)
+	ctx.Export(toPostgresInstanceUsernameExport(postgresName), instance.Username)
+	ctx.Export(toPostgresInstancePasswordExport(postgresName), sdk.ToSecret(sdk.String(postgresCfg.Password)))
+
+	params.Log.Info(ctx.Context(), "successfully adopted RDS Postgres instance %q", postgresCfg.InstanceIdentifier)
+
+	return &api.ResourceOutput{Ref: instance}, nil
+}
+
+// AdoptRdsMysql imports an existing RDS MySQL instance into Pulumi state without modifying it
+func AdoptRdsMysql(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params pApi.ProvisionParams) (*api.ResourceOutput, error) {
+	if input.Descriptor.Type != aws.ResourceTypeRdsMysql {
+		return nil, errors.Errorf("unsupported resource type %q", input.Descriptor.Type)
+	}
+
+	mysqlCfg, ok := input.Descriptor.Config.Config.(*aws.MysqlConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert mysql config for %q", input.Descriptor.Type)
+	}
+
+	if err := pApi.ValidateAdoptionConfig(mysqlCfg.Adopt, mysqlCfg.InstanceIdentifier, input.Descriptor.Name); err != nil {
+		return nil, err
+	}
+
+	// Use identical naming functions as provisioning to ensure export compatibility
+	mysqlResName := lo.If(mysqlCfg.Name == "", input.Descriptor.Name).Else(mysqlCfg.Name)
+	mysqlName := toRdsMysqlName(mysqlResName, input.StackParams.Environment)
+
+	instance, err := adoptRdsInstance(ctx, input, params, adoptRdsInstanceInput{
+		resourceType:       "RDS MySQL instance",
+		resName:            mysqlName,
+		instanceIdentifier: mysqlCfg.InstanceIdentifier,
+		password:           mysqlCfg.Password,
+		databaseName:       mysqlCfg.DatabaseName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Export(toMysqlInstanceArnExport(mysqlName), instance.Arn)
+	ctx.Export(toMysqlInstanceEndpointExport(mysqlName), instance.Endpoint)
+	ctx.Export(toMysqlInstanceUsernameExport(mysqlName), instance.Username)
+	ctx.Export(toMysqlInstancePasswordExport(mysqlName), sdk.ToSecret(sdk.String(mysqlCfg.Password)))
+
+	params.Log.Info(ctx.Context(), "successfully adopted RDS MySQL instance %q", mysqlCfg.InstanceIdentifier)
+
+	return &api.ResourceOutput{Ref: instance}, nil
+}
+
+// adoptRdsInstance imports the existing RDS instance keeping its current configuration,
+// subnet group and security groups of the instance are left as they are
+func adoptRdsInstance(ctx *sdk.Context, input api.ResourceInput, params pApi.ProvisionParams, adoptInput adoptRdsInstanceInput) (*rds.Instance, error) {
+	pApi.LogAdoptionWarnings(ctx, input, params, adoptInput.resourceType, adoptInput.instanceIdentifier)
+
+	if adoptInput.password == "" {
+		// master password cannot be read from AWS, compute processors need it to create database users
+		params.Log.Warn(ctx.Context(), "password not provided in config for adopted instance %q, compute processor will not be able to create database users", adoptInput.instanceIdentifier)
+	}
+
+	params.Log.Info(ctx.Context(), "fetching existing RDS instance details for %q", adoptInput.instanceIdentifier)
+	existing, err := rds.LookupInstance(ctx, &rds.LookupInstanceArgs{
+		DbInstanceIdentifier: lo.ToPtr(adoptInput.instanceIdentifier),
+	}, sdk.Provider(params.Provider))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to lookup existing %s %q", adoptInput.resourceType, adoptInput.instanceIdentifier)
+	}
+	params.Log.Info(ctx.Context(), "found existing instance with engine %q %q, class %q, storage %dGB",
+		existing.Engine, existing.EngineVersion, existing.DbInstanceClass, existing.AllocatedStorage)
+
+	var dbName sdk.StringPtrInput
+	if adoptInput.databaseName != nil {
+		dbName = sdk.StringPtr(*adoptInput.databaseName)
+	} else if existing.DbName != "" {
+		dbName = sdk.StringPtr(existing.DbName)
+	}
+
+	adoptionOpts := pApi.AdoptionProtectionOptions(adoptedRdsInstanceIgnoreChanges)
+	opts := append([]sdk.ResourceOption{
+		sdk.Provider(params.Provider),
+		// Import the existing instance without creating or modifying it
+		sdk.Import(sdk.ID(adoptInput.instanceIdentifier)),
+	}, adoptionOpts...)
+
+	instance, err := rds.NewInstance(ctx, adoptInput.resName, &rds.InstanceArgs{
+		Identifier:          sdk.String(adoptInput.instanceIdentifier),
+		DbName:              dbName,
+		InstanceClass:       sdk.String(existing.DbInstanceClass),
+		AllocatedStorage:    sdk.Int(existing.AllocatedStorage),
+		Engine:              sdk.String(existing.Engine),
+		EngineVersion:       sdk.String(existing.EngineVersion),
+		DbSubnetGroupName:   sdk.String(existing.DbSubnetGroup),
+		VpcSecurityGroupIds: sdk.ToStringArray(existing.VpcSecurityGroups),
+		Username:            sdk.String(existing.MasterUsername),
+		StorageEncrypted:    sdk.Bool(existing.StorageEncrypted),
+	}, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to import %s %q", adoptInput.resourceType, adoptInput.instanceIdentifier)
+	}
+	return instance, nil
+}
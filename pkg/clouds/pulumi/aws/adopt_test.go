@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ecr"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/rds"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/s3"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger"
+	"github.com/simple-container-com/api/pkg/clouds/aws"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+)
+
+// adoptionMocks records registered resources, so that tests can check how they are imported
+type adoptionMocks struct {
+	mu        sync.Mutex
+	resources map[string]sdk.MockResourceArgs
+}
+
+func (m *adoptionMocks) NewResource(args sdk.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resources[args.TypeToken] = args
+
+	outputs := args.Inputs.Mappable()
+	id := args.ID
+	if id == "" {
+		id = args.Name + "-id"
+	}
+	switch args.TypeToken {
+	case "aws:s3/bucket:Bucket":
+		outputs["arn"] = "arn:aws:s3:::" + id
+		outputs["region"] = "eu-central-1"
+	case "aws:iam/user:User":
+		outputs["name"] = args.Name
+	case "aws:iam/accessKey:AccessKey":
+		outputs["secret"] = "secret"
+	}
+	return id, resource.NewPropertyMapFromMap(outputs), nil
+}
+
+func (m *adoptionMocks) Call(args sdk.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func TestAdoptS3Bucket(t *testing.T) {
+	RegisterTestingT(t)
+
+	mocks := &adoptionMocks{resources: make(map[string]sdk.MockResourceArgs)}
+	err := sdk.RunErr(func(ctx *sdk.Context) error {
+		out, err := AdoptS3Bucket(ctx, api.Stack{Name: "infra"}, api.ResourceInput{
+			Descriptor: &api.ResourceDescriptor{
+				Type: aws.ResourceTypeS3Bucket,
+				Name: "uploads",
+				Config: api.Config{Config: &aws.S3Bucket{
+					Adopt:      true,
+					BucketName: "legacy-uploads-bucket",
+				}},
+			},
+			StackParams: &api.StackParams{StackName: "infra", Environment: "staging"},
+		}, pApi.ProvisionParams{Log: logger.New()})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.Ref).To(BeAssignableToTypeOf(&PrivateBucketOutput{}))
+		return nil
+	}, sdk.WithMocks("test", "test", mocks))
+	Expect(err).ToNot(HaveOccurred())
+
+	bucket, ok := mocks.resources["aws:s3/bucket:Bucket"]
+	Expect(ok).To(BeTrue())
+	Expect(bucket.Name).To(Equal("uploads--staging"))
+	Expect(bucket.ID).To(Equal("legacy-uploads-bucket"), "bucket must be imported, not created")
+	Expect(bucket.RegisterRPC.GetProtect()).To(BeTrue())
+	Expect(bucket.RegisterRPC.GetIgnoreChanges()).To(Equal(adoptedS3BucketIgnoreChanges))
+
+	// access is granted with a new user, the bucket policy is never created or replaced
+	Expect(mocks.resources).To(HaveKey("aws:iam/userPolicy:UserPolicy"))
+	Expect(mocks.resources).ToNot(HaveKey("aws:s3/bucketPolicy:BucketPolicy"))
+	Expect(mocks.resources["aws:iam/user:User"].ID).To(BeEmpty())
+}
+
+func TestValidateAdoptedS3Bucket(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, tc := range []struct {
+		name    string
+		cfg     aws.S3Bucket
+		wantErr string
+	}{
+		{
+			name: "valid",
+			cfg:  aws.S3Bucket{Adopt: true, BucketName: "legacy"},
+		},
+		{
+			name:    "bucket name is required",
+			cfg:     aws.S3Bucket{Adopt: true},
+			wantErr: "resource name is required",
+		},
+		{
+			name:    "static site is rejected",
+			cfg:     aws.S3Bucket{Adopt: true, BucketName: "legacy", StaticSiteConfig: &api.StaticSiteConfig{Domain: "www.example.com"}},
+			wantErr: "static site is not supported",
+		},
+		{
+			name:    "https only is rejected",
+			cfg:     aws.S3Bucket{Adopt: true, BucketName: "legacy", AllowOnlyHttps: true},
+			wantErr: "allowOnlyHttps is not supported",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+			err := validateAdoptedS3Bucket(&tc.cfg, "uploads")
+			if tc.wantErr == "" {
+				Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+		})
+	}
+}
+
+func TestAdoptionIgnoreChanges(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, tc := range []struct {
+		name          string
+		resource      any
+		ignoreChanges []string
+		mustIgnore    []string
+	}{
+		{
+			name:          "s3 bucket",
+			resource:      s3.Bucket{},
+			ignoreChanges: adoptedS3BucketIgnoreChanges,
+			mustIgnore:    []string{"acl", "policy", "website", "corsRules", "forceDestroy"},
+		},
+		{
+			name:          "rds instance",
+			resource:      rds.Instance{},
+			ignoreChanges: adoptedRdsInstanceIgnoreChanges,
+			mustIgnore:    []string{"password", "instanceClass", "engineVersion", "vpcSecurityGroupIds"},
+		},
+		{
+			name:          "ecr repository",
+			resource:      ecr.Repository{},
+			ignoreChanges: adoptedEcrRepositoryIgnoreChanges,
+			mustIgnore:    []string{"forceDelete", "imageTagMutability"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+			Expect(tc.ignoreChanges).To(ContainElements(tc.mustIgnore))
+			// a misspelled property is silently not ignored and the adopted resource would be updated
+			Expect(pulumiPropertyNames(tc.resource)).To(ContainElements(tc.ignoreChanges))
+		})
+	}
+}
+
+// pulumiPropertyNames returns names of output properties of the resource declared with `pulumi` tags
+func pulumiPropertyNames(res any) []string {
+	var names []string
+	resType := reflect.TypeOf(res)
+	for i := 0; i < resType.NumField(); i++ {
+		if name, _, _ := strings.Cut(resType.Field(i).Tag.Get("pulumi"), ","); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -112,6 +112,11 @@ func Lambda(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params p
 		return nil, errors.Wrapf(err, "failed to create iam role")
 	}
 
+	// allow used resources to grant access to the role lambda is running with
+	if err := params.ComputeContext.RunPostProcessors(lambdaExecutionRole, lambdaExecutionRole); err != nil {
+		return nil, errors.Wrapf(err, "failed to run post processors on lambda execution role")
+	}
+
 	// Attach the necessary AWS managed policies to the role created
 	rolePolicyAttachmentName := fmt.Sprintf("%s-policy-attachment", stack.Name)
 	params.Log.Info(ctx.Context(), "configure role policy attachment %q for %q in %q...", rolePolicyAttachmentName, stack.Name, deployParams.Environment)
@@ -249,7 +254,7 @@ func Lambda(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params p
 	lambdaFuncArgs := lambda.FunctionArgs{
 		PackageType: sdk.String("Image"),
 		Role:        lambdaExecutionRole.Arn,
-		ImageUri:    image.image.ImageName,
+		ImageUri:    image.imageName,
 		Tags:        tags,
 		MemorySize:  sdk.IntPtr(lambdaSizeMb),
 		Timeout:     sdk.IntPtr(lo.If(stackConfig.Timeout != nil, lo.FromPtr(stackConfig.Timeout)).Else(10)),
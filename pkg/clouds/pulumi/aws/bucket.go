@@ -51,6 +51,10 @@ func S3Bucket(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params
 		return nil, errors.Errorf("failed to convert bucket config for %q", input.Descriptor.Type)
 	}
 
+	if bucketCfg.Adopt {
+		return AdoptS3Bucket(ctx, stack, input, params)
+	}
+
 	bucketName := input.ToResName(lo.If(bucketCfg.Name == "", input.Descriptor.Name).Else(bucketCfg.Name))
 	params.Log.Info(ctx.Context(), "configure private s3 bucket %q for %q in %q",
 		bucketName, input.StackParams.StackName, input.StackParams.Environment)
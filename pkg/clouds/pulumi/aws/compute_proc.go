@@ -6,11 +6,13 @@ package aws
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
 	"github.com/pulumi/pulumi-random/sdk/v4/go/random"
 	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 
@@ -412,3 +414,211 @@ func S3BucketComputeProcessor(ctx *sdk.Context, stack api.Stack, input api.Resou
 		Ref: parentStackName,
 	}, nil
 }
+
+func ElasticacheRedisComputeProcessor(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, collector pApi.ComputeContextCollector, params pApi.ProvisionParams) (*api.ResourceOutput, error) {
+	if params.ParentStack == nil {
+		return nil, errors.Errorf("parent stack must not be nil for compute processor for %q", stack.Name)
+	}
+	parentStackName := params.ParentStack.StackName
+
+	redisCfg, ok := input.Descriptor.Config.Config.(*aws.ElasticacheRedisConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert elasticache redis config for %q", input.Descriptor.Type)
+	}
+
+	redisResName := lo.If(redisCfg.Name == "", input.Descriptor.Name).Else(redisCfg.Name)
+	redisEnv := lo.If(params.ParentStack.ParentEnv != "", params.ParentStack.ParentEnv).Else(input.StackParams.Environment)
+	redisName := toElasticacheRedisName(redisResName, redisEnv)
+	if stackUsesStaticEgressIP(stack, input.StackParams.Environment) {
+		return nil, errors.Errorf("elasticache redis %q is not reachable from stack %q in %q: the stack uses staticEgressIP "+
+			"and runs in its own VPC, while the redis cluster is placed in the default VPC", redisName, stack.Name, input.StackParams.Environment)
+	}
+
+	// Create a StackReference to the parent stack
+	suffix := lo.If(params.ParentStack.DependsOnResource != nil, "--"+lo.FromPtr(params.ParentStack.DependsOnResource).Name).Else("")
+	params.Log.Info(ctx.Context(), "getting parent's (%q) outputs for elasticache redis %q (%q)", params.ParentStack.FullReference, redisName, suffix)
+	parentRef, err := sdk.NewStackReference(ctx, fmt.Sprintf("%s--%s--%s%s--redis-ref", stack.Name, params.ParentStack.StackName, input.Descriptor.Name, suffix), &sdk.StackReferenceArgs{
+		Name: sdk.String(params.ParentStack.FullReference).ToStringOutput(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	redisHostExport := toElasticacheRedisHostExport(redisName)
+	redisHost, err := pApi.GetParentOutput(parentRef, redisHostExport, params.ParentStack.FullReference, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get redis host from parent stack for %q (%q)", stack.Name, redisHostExport)
+	} else if redisHost == "" {
+		return nil, errors.Errorf("redis host is empty for %q (%q)", stack.Name, redisName)
+	}
+	redisPortExport := toElasticacheRedisPortExport(redisName)
+	redisPort, err := pApi.GetParentOutput(parentRef, redisPortExport, params.ParentStack.FullReference, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get redis port from parent stack for %q (%q)", stack.Name, redisPortExport)
+	} else if redisPort == "" {
+		redisPort = strconv.Itoa(elasticacheRedisPort)
+		params.Log.Warn(ctx.Context(), "redis's port %q wasn't found in the outputs, fallback to default port %s", redisName, redisPort)
+	}
+	var redisPassword string
+	if redisCfg.AuthToken != "" {
+		redisPasswordExport := toElasticacheRedisPasswordExport(redisName)
+		redisPassword, err = pApi.GetParentOutput(parentRef, redisPasswordExport, params.ParentStack.FullReference, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get redis password from parent stack for %q (%q)", stack.Name, redisPasswordExport)
+		}
+	}
+
+	if !params.ParentStack.UsesResource {
+		params.Log.Warn(ctx.Context(), "elasticache redis %q only supports `uses`, but it wasn't explicitly declared as being used", redisName)
+		return nil, errors.Errorf("elasticache redis %q only supports `uses`, but it wasn't explicitly declared as being used", redisName)
+	}
+
+	collector.AddOutput(ctx, parentRef.Name.ApplyT(func(refName any) any {
+		collector.AddEnvVariableIfNotExist(util.ToEnvVariableName("REDIS_HOST"), redisHost,
+			input.Descriptor.Type, input.Descriptor.Name, parentStackName)
+		collector.AddEnvVariableIfNotExist(util.ToEnvVariableName("REDIS_PORT"), redisPort,
+			input.Descriptor.Type, input.Descriptor.Name, parentStackName)
+		tplValues := map[string]string{
+			"host": redisHost,
+			"port": redisPort,
+		}
+		if redisPassword != "" {
+			collector.AddSecretEnvVariableIfNotExist(util.ToEnvVariableName("REDIS_PASSWORD"), redisPassword,
+				input.Descriptor.Type, input.Descriptor.Name, parentStackName)
+			tplValues["password"] = redisPassword
+		}
+		collector.AddResourceTplExtension(input.Descriptor.Name, tplValues)
+
+		return nil
+	}))
+
+	return &api.ResourceOutput{
+		Ref: parentStackName,
+	}, nil
+}
+
+func SqsSnsComputeProcessor(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, collector pApi.ComputeContextCollector, params pApi.ProvisionParams) (*api.ResourceOutput, error) {
+	if params.ParentStack == nil {
+		return nil, errors.Errorf("parent stack must not be nil for compute processor for %q", stack.Name)
+	}
+	parentStackName := params.ParentStack.StackName
+
+	sqsSnsCfg, ok := input.Descriptor.Config.Config.(*aws.SqsSnsConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert sqs/sns config for %q", input.Descriptor.Type)
+	}
+	resName := input.ToResName(input.Descriptor.Name)
+
+	// Create a StackReference to the parent stack
+	suffix := lo.If(params.ParentStack.DependsOnResource != nil, "--"+lo.FromPtr(params.ParentStack.DependsOnResource).Name).Else("")
+	params.Log.Info(ctx.Context(), "getting parent's (%q) outputs for sqs/sns %q (%q)", params.ParentStack.FullReference, resName, suffix)
+	parentRef, err := sdk.NewStackReference(ctx, fmt.Sprintf("%s--%s--%s%s--sqs-sns-ref", stack.Name, params.ParentStack.StackName, input.Descriptor.Name, suffix), &sdk.StackReferenceArgs{
+		Name: sdk.String(params.ParentStack.FullReference).ToStringOutput(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	getParentOutput := func(export string) (string, error) {
+		value, err := pApi.GetParentOutput(parentRef, export, params.ParentStack.FullReference, false)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get %q from parent stack for %q", export, stack.Name)
+		} else if value == "" {
+			return "", errors.Errorf("%q is empty for %q (%q)", export, stack.Name, resName)
+		}
+		return value, nil
+	}
+
+	envVariables := make(map[string]string)
+	tplValues := make(map[string]string)
+	var queueArns, topicArns []string
+	for _, topic := range sqsSnsCfg.Topics {
+		topicArn, err := getParentOutput(toSnsTopicArnExport(resName, topic.Name))
+		if err != nil {
+			return nil, err
+		}
+		topicArns = append(topicArns, topicArn)
+		envVariables[fmt.Sprintf("SNS_TOPIC_%s_ARN", util.ToEnvVariableName(topic.Name))] = topicArn
+		tplValues[fmt.Sprintf("topic.%s.arn", topic.Name)] = topicArn
+	}
+	for _, queue := range sqsSnsCfg.Queues {
+		queueEnvName := util.ToEnvVariableName(queue.Name)
+		queueUrl, err := getParentOutput(toSqsQueueUrlExport(resName, queue.Name))
+		if err != nil {
+			return nil, err
+		}
+		queueArn, err := getParentOutput(toSqsQueueArnExport(resName, queue.Name))
+		if err != nil {
+			return nil, err
+		}
+		queueArns = append(queueArns, queueArn)
+		envVariables[fmt.Sprintf("SQS_QUEUE_%s_URL", queueEnvName)] = queueUrl
+		envVariables[fmt.Sprintf("SQS_QUEUE_%s_ARN", queueEnvName)] = queueArn
+		tplValues[fmt.Sprintf("queue.%s.url", queue.Name)] = queueUrl
+		tplValues[fmt.Sprintf("queue.%s.arn", queue.Name)] = queueArn
+
+		if queue.DeadLetterQueue == nil {
+			continue
+		}
+		dlqUrl, err := getParentOutput(toSqsDlqUrlExport(resName, queue.Name))
+		if err != nil {
+			return nil, err
+		}
+		dlqArn, err := getParentOutput(toSqsDlqArnExport(resName, queue.Name))
+		if err != nil {
+			return nil, err
+		}
+		queueArns = append(queueArns, dlqArn)
+		envVariables[fmt.Sprintf("SQS_QUEUE_%s_DLQ_URL", queueEnvName)] = dlqUrl
+		envVariables[fmt.Sprintf("SQS_QUEUE_%s_DLQ_ARN", queueEnvName)] = dlqArn
+		tplValues[fmt.Sprintf("queue.%s.dlqUrl", queue.Name)] = dlqUrl
+		tplValues[fmt.Sprintf("queue.%s.dlqArn", queue.Name)] = dlqArn
+	}
+
+	if !params.ParentStack.UsesResource {
+		params.Log.Warn(ctx.Context(), "sqs/sns %q only supports `uses`, but it wasn't explicitly declared as being used", resName)
+		return nil, errors.Errorf("sqs/sns %q only supports `uses`, but it wasn't explicitly declared as being used", resName)
+	}
+
+	collector.AddOutput(ctx, parentRef.Name.ApplyT(func(refName any) any {
+		for name, value := range envVariables {
+			collector.AddEnvVariableIfNotExist(name, value,
+				input.Descriptor.Type, input.Descriptor.Name, parentStackName)
+		}
+		collector.AddResourceTplExtension(input.Descriptor.Name, tplValues)
+
+		return nil
+	}))
+
+	if len(queueArns) == 0 && len(topicArns) == 0 {
+		return &api.ResourceOutput{
+			Ref: parentStackName,
+		}, nil
+	}
+
+	accessPolicy, err := sqsSnsAccessPolicy(queueArns, topicArns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build access policy for sqs/sns %q", resName)
+	}
+	// ECS and lambda run post processors on their roles, so that access is granted to declared queues and topics only
+	collector.AddPostProcessor(&iam.Role{}, func(r any) error {
+		role, ok := r.(*iam.Role)
+		if !ok {
+			return errors.Errorf("%T is not *iam.Role", r)
+		}
+		policyName := fmt.Sprintf("%s--%s--%s%s--sqs-sns-policy", stack.Name, parentStackName, input.Descriptor.Name, suffix)
+		params.Log.Info(ctx.Context(), "attaching policy %q granting access to sqs/sns %q", policyName, resName)
+		_, err := iam.NewRolePolicy(ctx, policyName, &iam.RolePolicyArgs{
+			Role:   role.Name,
+			Policy: sdk.String(accessPolicy),
+		}, sdk.Provider(params.Provider))
+		if err != nil {
+			return errors.Wrapf(err, "failed to create policy granting access to sqs/sns %q", resName)
+		}
+		return nil
+	})
+
+	return &api.ResourceOutput{
+		Ref: parentStackName,
+	}, nil
+}
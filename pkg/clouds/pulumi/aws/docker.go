@@ -23,8 +23,8 @@ type dockerImage struct {
 }
 
 type dockerImageOut struct {
-	image   *docker.Image
-	addOpts []sdk.ResourceOption
+	imageName sdk.StringOutput
+	addOpts   []sdk.ResourceOption
 }
 
 func buildAndPushDockerImageV2(ctx *sdk.Context, stack api.Stack, params pApi.ProvisionParams, deployParams api.StackParams, image dockerImage) (*dockerImageOut, error) {
@@ -51,7 +51,7 @@ func buildAndPushDockerImageV2(ctx *sdk.Context, stack api.Stack, params pApi.Pr
 			image.name, image.context, stack.Name, deployParams.Environment)
 	}
 	return &dockerImageOut{
-		image:   out.Image,
-		addOpts: out.AddOpts,
+		imageName: out.ImageName,
+		addOpts:   out.AddOpts,
 	}, nil
 }
@@ -30,6 +30,10 @@ func EcrRepository(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, p
 		return nil, errors.Errorf("failed to convert bucket config for %q", input.Descriptor.Type)
 	}
 
+	if ecrCfg.Adopt {
+		return AdoptEcrRepository(ctx, stack, input, params)
+	}
+
 	ecrRepoName := ecrCfg.Name
 	repo, err := createEcrRegistry(ctx, stack, params, *input.StackParams, ecrRepoName, ecrCfg)
 	if err != nil {
@@ -76,7 +80,14 @@ func createEcrRegistry(ctx *sdk.Context, stack api.Stack, params pApi.ProvisionP
 		return res, errors.Wrapf(err, "failed to create ecr lifecycle policy for ECR registry %s", ecrRepoName)
 	}
 
-	registryPassword := ecrRepo.RegistryId.ApplyT(func(registryId string) (string, error) {
+	res.Password = ecrRegistryPassword(ctx, params, ecrRepo, ecrRepoName)
+	ctx.Export(toEcrRepositoryPasswordExport(ecrRepoName), sdk.ToSecret(res.Password))
+
+	return res, nil
+}
+
+func ecrRegistryPassword(ctx *sdk.Context, params pApi.ProvisionParams, ecrRepo *ecr.Repository, ecrRepoName string) sdk.StringOutput {
+	return ecrRepo.RegistryId.ApplyT(func(registryId string) (string, error) {
 		// Fetch the auth token for the registry
 		creds, err := ecr.GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenArgs{
 			RegistryId: lo.ToPtr(registryId),
@@ -91,11 +102,6 @@ func createEcrRegistry(ctx *sdk.Context, stack api.Stack, params pApi.ProvisionP
 		}
 		return strings.TrimPrefix(string(decodedCreds), "AWS:"), nil
 	}).(sdk.StringOutput)
-
-	res.Password = registryPassword
-	ctx.Export(toEcrRepositoryPasswordExport(ecrRepoName), sdk.ToSecret(registryPassword))
-
-	return res, nil
 }
 
 func toEcrRepositoryIDExport(ecrRepoName string) string {
@@ -272,6 +272,11 @@ func createEcsFargateCluster(ctx *sdk.Context, stack api.Stack, params pApi.Prov
 	ref.ExecRole = taskExecRole
 	ctx.Export(fmt.Sprintf("%s-exec-role-arn", ecsSimpleClusterName), taskExecRole.Arn)
 
+	// allow used resources to grant access to the role tasks are running with
+	if err := params.ComputeContext.RunPostProcessors(taskExecRole, taskExecRole); err != nil {
+		return errors.Wrapf(err, "failed to run post processors on IAM role for stack %q in %q", stack.Name, deployParams.Environment)
+	}
+
 	var volumes ecsV6.TaskDefinitionVolumeArray
 	for _, v := range crInput.Volumes {
 		efsName := fmt.Sprintf("%s-%s-fs", ecsSimpleClusterName, v.Name)
@@ -988,7 +993,7 @@ func buildAndPushECSFargateImages(ctx *sdk.Context, stack api.Stack, params pApi
 		}
 		return &ECRImage{
 			Container: container,
-			ImageName: image.image.ImageName,
+			ImageName: image.imageName,
 			AddOpts:   image.addOpts,
 		}, nil
 	})
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/elasticache"
+	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/aws"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+)
+
+const (
+	elasticacheRedisPort = 6379
+	// elasticacheMaxIdLength is the max length of IDs of replication groups
+	elasticacheMaxIdLength = 40
+)
+
+func ElasticacheRedis(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params pApi.ProvisionParams) (*api.ResourceOutput, error) {
+	if input.Descriptor.Type != aws.ResourceTypeElasticacheRedis {
+		return nil, errors.Errorf("unsupported resource type %q", input.Descriptor.Type)
+	}
+
+	redisCfg, ok := input.Descriptor.Config.Config.(*aws.ElasticacheRedisConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert elasticache redis config for %q", input.Descriptor.Type)
+	}
+
+	accountConfig := &aws.AccountConfig{}
+	err := api.ConvertAuth(&redisCfg.AccountConfig, accountConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert aws account config")
+	}
+	redisCfg.AccountConfig = *accountConfig
+
+	redisResName := lo.If(redisCfg.Name == "", input.Descriptor.Name).Else(redisCfg.Name)
+	redisName := toElasticacheRedisName(redisResName, input.StackParams.Environment)
+	if len(redisName) > elasticacheMaxIdLength {
+		return nil, errors.Errorf("name of elasticache redis %q must not be longer than %d characters", redisName, elasticacheMaxIdLength)
+	}
+	if len(redisCfg.Parameters) > 0 && redisCfg.ParameterGroupFamily == "" {
+		return nil, errors.Errorf("parameterGroupFamily is required when parameters are set for elasticache redis %q", redisName)
+	}
+
+	opts := []sdk.ResourceOption{sdk.Provider(params.Provider)}
+	tags := pApi.BuildTagsFromStackParams(*input.StackParams).ToAWSTags()
+
+	// ECS and Lambda stacks run in default subnets of the default VPC, so the cluster is placed there too.
+	// Stacks with staticEgressIP run in their own VPCs and cannot reach it (see stackUsesStaticEgressIP)
+	subnets, err := createDefaultSubnetsInRegionV5(ctx, redisCfg.AccountConfig, input.StackParams.Environment, params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get or create default subnets in region")
+	}
+	opts = append(opts, sdk.DependsOn(subnets.Resources()))
+
+	params.Log.Info(ctx.Context(), "configure elasticache redis %q for %q in %q",
+		redisName, input.StackParams.StackName, input.StackParams.Environment)
+
+	vpc, err := ec2.NewDefaultVpc(ctx, fmt.Sprintf("%s-vpc", redisName), nil, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create default vpc for elasticache redis %q", redisName)
+	}
+
+	securityGroupName := fmt.Sprintf("%s-sg", redisName)
+	params.Log.Info(ctx.Context(), "configure security group for elasticache redis %s...", securityGroupName)
+	redisSg, err := ec2.NewSecurityGroup(ctx, securityGroupName, &ec2.SecurityGroupArgs{
+		Name:  sdk.String(securityGroupName),
+		VpcId: vpc.ID(),
+		Tags:  tags,
+		Ingress: &ec2.SecurityGroupIngressArray{
+			&ec2.SecurityGroupIngressArgs{
+				Protocol:   sdk.String("tcp"),
+				FromPort:   sdk.Int(elasticacheRedisPort),
+				ToPort:     sdk.Int(elasticacheRedisPort),
+				CidrBlocks: sdk.StringArray{vpc.CidrBlock},
+			},
+		},
+	}, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create security group for elasticache redis %q", redisName)
+	}
+
+	subnetGroupName := fmt.Sprintf("%s-subnet-group", redisName)
+	params.Log.Info(ctx.Context(), "configure subnet group for elasticache redis %s...", subnetGroupName)
+	subnetGroup, err := elasticache.NewSubnetGroup(ctx, subnetGroupName, &elasticache.SubnetGroupArgs{
+		Name:      sdk.String(subnetGroupName),
+		SubnetIds: subnets.Ids(),
+		Tags:      tags,
+	}, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create subnet group for elasticache redis %q", redisName)
+	}
+
+	var parameterGroupName sdk.StringPtrInput
+	if redisCfg.ParameterGroupFamily != "" {
+		paramGroupName := fmt.Sprintf("%s-params", redisName)
+		params.Log.Info(ctx.Context(), "configure parameter group for elasticache redis %s...", paramGroupName)
+		paramGroup, err := elasticache.NewParameterGroup(ctx, paramGroupName, &elasticache.ParameterGroupArgs{
+			Name:       sdk.String(paramGroupName),
+			Family:     sdk.String(redisCfg.ParameterGroupFamily),
+			Parameters: toElasticacheParameters(redisCfg.Parameters),
+			Tags:       tags,
+		}, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create parameter group for elasticache redis %q", redisName)
+		}
+		parameterGroupName = paramGroup.Name
+	}
+
+	numCacheClusters := lo.FromPtrOr(redisCfg.NumCacheClusters, 1)
+	replicationGroupArgs := &elasticache.ReplicationGroupArgs{
+		ReplicationGroupId:       sdk.String(redisName),
+		Description:              sdk.String(fmt.Sprintf("%s for %s in %s", redisResName, input.StackParams.StackName, input.StackParams.Environment)),
+		Engine:                   sdk.String(lo.If(redisCfg.Engine != "", redisCfg.Engine).Else("redis")),
+		NodeType:                 sdk.String(lo.If(redisCfg.NodeType != "", redisCfg.NodeType).Else("cache.t3.micro")),
+		NumCacheClusters:         sdk.Int(numCacheClusters),
+		AutomaticFailoverEnabled: sdk.Bool(numCacheClusters > 1),
+		Port:                     sdk.Int(elasticacheRedisPort),
+		ParameterGroupName:       parameterGroupName,
+		SubnetGroupName:          subnetGroup.Name,
+		SecurityGroupIds:         sdk.StringArray{redisSg.ID()},
+		AtRestEncryptionEnabled:  sdk.Bool(lo.FromPtrOr(redisCfg.AtRestEncryption, true)),
+		SnapshotRetentionLimit:   sdk.IntPtrFromPtr(redisCfg.SnapshotRetentionLimit),
+		Tags:                     tags,
+	}
+	if redisCfg.EngineVersion != "" {
+		replicationGroupArgs.EngineVersion = sdk.String(redisCfg.EngineVersion)
+	}
+	if redisCfg.AuthToken != "" {
+		// AUTH is only supported along with in-transit encryption
+		replicationGroupArgs.TransitEncryptionEnabled = sdk.Bool(true)
+		replicationGroupArgs.AuthToken = sdk.ToSecret(redisCfg.AuthToken).(sdk.StringOutput)
+	}
+
+	params.Log.Info(ctx.Context(), "configure elasticache replication group %s...", redisName)
+	replicationGroup, err := elasticache.NewReplicationGroup(ctx, redisName, replicationGroupArgs, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create elasticache redis %q", redisName)
+	}
+
+	ctx.Export(toElasticacheRedisHostExport(redisName), replicationGroup.PrimaryEndpointAddress)
+	ctx.Export(toElasticacheRedisPortExport(redisName), replicationGroup.Port.ApplyT(func(port *int) string {
+		return strconv.Itoa(lo.FromPtrOr(port, elasticacheRedisPort))
+	}).(sdk.StringOutput))
+
+	if redisCfg.AuthToken != "" {
+		secretName := toSecretName(*input.StackParams, input.Descriptor.Type, redisResName, "REDIS_PASSWORD", "")
+		params.Log.Info(ctx.Context(), "store auth token of elasticache redis %s in secret %s...", redisName, secretName)
+		secret, err := createSecret(ctx, secretName, "REDIS_PASSWORD", redisCfg.AuthToken, tags, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create secret with auth token for elasticache redis %q", redisName)
+		}
+		ctx.Export(toElasticacheRedisAuthTokenSecretExport(redisName), secret.Secret.Arn)
+		ctx.Export(toElasticacheRedisPasswordExport(redisName), sdk.ToSecret(sdk.String(redisCfg.AuthToken)))
+	}
+
+	return &api.ResourceOutput{Ref: replicationGroup}, nil
+}
+
+// stackUsesStaticEgressIP returns true if the client stack runs in its own VPC with static egress IP
+// instead of the default VPC elasticache redis clusters are placed in
+func stackUsesStaticEgressIP(stack api.Stack, env string) bool {
+	switch cfg := stack.Client.Stacks[env].Config.Config.(type) {
+	case *api.StackConfigCompose:
+		return lo.FromPtr(cfg.StaticEgressIP)
+	case *api.StackConfigSingleImage:
+		return lo.FromPtr(cfg.StaticEgressIP)
+	}
+	return false
+}
+
+func toElasticacheParameters(parameters map[string]string) elasticache.ParameterGroupParameterArray {
+	names := lo.Keys(parameters)
+	// sorted to keep the order stable between updates
+	sort.Strings(names)
+	return lo.Map(names, func(name string, _ int) elasticache.ParameterGroupParameterInput {
+		return elasticache.ParameterGroupParameterArgs{
+			Name:  sdk.String(name),
+			Value: sdk.String(parameters[name]),
+		}
+	})
+}
+
+func toElasticacheRedisName(name string, env string) string {
+	return fmt.Sprintf("%s-%s", name, env)
+}
+
+func toElasticacheRedisHostExport(redisName string) string {
+	return fmt.Sprintf("%s-host", redisName)
+}
+
+func toElasticacheRedisPortExport(redisName string) string {
+	return fmt.Sprintf("%s-port", redisName)
+}
+
+func toElasticacheRedisPasswordExport(redisName string) string {
+	return fmt.Sprintf("%s-password", redisName)
+}
+
+func toElasticacheRedisAuthTokenSecretExport(redisName string) string {
+	return fmt.Sprintf("%s-auth-token-secret", redisName)
+}
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func TestStackUsesStaticEgressIP(t *testing.T) {
+	RegisterTestingT(t)
+
+	stack := api.Stack{Name: "billing", Client: api.ClientDescriptor{Stacks: map[string]api.StackClientDescriptor{
+		"prod":    {Config: api.Config{Config: &api.StackConfigCompose{StaticEgressIP: lo.ToPtr(true)}}},
+		"staging": {Config: api.Config{Config: &api.StackConfigCompose{}}},
+		"lambda":  {Config: api.Config{Config: &api.StackConfigSingleImage{StaticEgressIP: lo.ToPtr(true)}}},
+		"static":  {Config: api.Config{Config: &api.StackConfigStatic{}}},
+	}}}
+
+	Expect(stackUsesStaticEgressIP(stack, "prod")).To(BeTrue())
+	Expect(stackUsesStaticEgressIP(stack, "lambda")).To(BeTrue())
+	Expect(stackUsesStaticEgressIP(stack, "staging")).To(BeFalse())
+	Expect(stackUsesStaticEgressIP(stack, "static")).To(BeFalse())
+	Expect(stackUsesStaticEgressIP(stack, "missing")).To(BeFalse())
+}
@@ -20,12 +20,16 @@ func init() {
 		aws.TemplateTypeStaticWebsite:            StaticWebsite,
 		aws.ResourceTypeRdsPostgres:              RdsPostgres,
 		aws.ResourceTypeRdsMysql:                 RdsMysql,
+		aws.ResourceTypeElasticacheRedis:         ElasticacheRedis,
+		aws.ResourceTypeSqsSns:                   SqsSns,
 		aws.ResourceTypeEcrRepository:            EcrRepository,
 		aws.ResourceTypeCloudTrailSecurityAlerts: CloudTrailSecurityAlerts,
 	})
 	api.RegisterComputeProcessor(map[string]api.ComputeProcessorFunc{
-		aws.ResourceTypeS3Bucket:    S3BucketComputeProcessor,
-		aws.ResourceTypeRdsPostgres: RdsPostgresComputeProcessor,
-		aws.ResourceTypeRdsMysql:    RdsMysqlComputeProcessor,
+		aws.ResourceTypeS3Bucket:         S3BucketComputeProcessor,
+		aws.ResourceTypeRdsPostgres:      RdsPostgresComputeProcessor,
+		aws.ResourceTypeRdsMysql:         RdsMysqlComputeProcessor,
+		aws.ResourceTypeElasticacheRedis: ElasticacheRedisComputeProcessor,
+		aws.ResourceTypeSqsSns:           SqsSnsComputeProcessor,
 	})
 }
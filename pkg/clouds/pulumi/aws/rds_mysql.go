@@ -30,6 +30,10 @@ func RdsMysql(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params
 		return nil, errors.Errorf("failed to convert mysql config for %q", input.Descriptor.Type)
 	}
 
+	if mysqlCfg.Adopt {
+		return AdoptRdsMysql(ctx, stack, input, params)
+	}
+
 	accountConfig := &aws.AccountConfig{}
 	err := api.ConvertAuth(&mysqlCfg.AccountConfig, accountConfig)
 	if err != nil {
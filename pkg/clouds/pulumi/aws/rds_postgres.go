@@ -30,6 +30,10 @@ func RdsPostgres(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, par
 		return nil, errors.Errorf("failed to convert postgres config for %q", input.Descriptor.Type)
 	}
 
+	if postgresCfg.Adopt {
+		return AdoptRdsPostgres(ctx, stack, input, params)
+	}
+
 	accountConfig := &aws.AccountConfig{}
 	err := api.ConvertAuth(&postgresCfg.AccountConfig, accountConfig)
 	if err != nil {
@@ -0,0 +1,359 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sns"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sqs"
+	"github.com/pulumi/pulumi-docker/sdk/v4/go/docker"
+	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/aws"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+	"github.com/simple-container-com/api/pkg/util"
+)
+
+const sqsDefaultMaxReceiveCount = 5
+
+type SqsSnsOutput struct {
+	Topics map[string]*sns.Topic
+	Queues map[string]*sqs.Queue
+	Dlqs   map[string]*sqs.Queue
+}
+
+func SqsSns(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, params pApi.ProvisionParams) (*api.ResourceOutput, error) {
+	if input.Descriptor.Type != aws.ResourceTypeSqsSns {
+		return nil, errors.Errorf("unsupported resource type %q", input.Descriptor.Type)
+	}
+
+	sqsSnsCfg, ok := input.Descriptor.Config.Config.(*aws.SqsSnsConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert sqs/sns config for %q", input.Descriptor.Type)
+	}
+
+	accountConfig := &aws.AccountConfig{}
+	err := api.ConvertAuth(&sqsSnsCfg.AccountConfig, accountConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert aws account config")
+	}
+	sqsSnsCfg.AccountConfig = *accountConfig
+
+	if err := sqsSnsCfg.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid sqs/sns config for %q", input.Descriptor.Name)
+	}
+
+	resName := input.ToResName(input.Descriptor.Name)
+	opts := []sdk.ResourceOption{sdk.Provider(params.Provider)}
+	tags := pApi.BuildTagsFromStackParams(*input.StackParams).ToAWSTags()
+
+	out := &SqsSnsOutput{
+		Topics: make(map[string]*sns.Topic),
+		Queues: make(map[string]*sqs.Queue),
+		Dlqs:   make(map[string]*sqs.Queue),
+	}
+
+	for _, topic := range sqsSnsCfg.Topics {
+		topicName := toSqsSnsPhysicalName(input.ToResName(topic.Name), topic.Fifo)
+		params.Log.Info(ctx.Context(), "configure sns topic %q for %q in %q...", topicName, input.StackParams.StackName, input.StackParams.Environment)
+		snsTopic, err := sns.NewTopic(ctx, fmt.Sprintf("%s-topic", topicName), &sns.TopicArgs{
+			Name:                      sdk.String(topicName),
+			FifoTopic:                 sdk.Bool(topic.Fifo),
+			ContentBasedDeduplication: sdk.Bool(topic.ContentBasedDeduplication),
+			Tags:                      tags,
+		}, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create sns topic %q", topicName)
+		}
+		ctx.Export(toSnsTopicArnExport(resName, topic.Name), snsTopic.Arn)
+		out.Topics[topic.Name] = snsTopic
+	}
+
+	for _, queue := range sqsSnsCfg.Queues {
+		queueName := toSqsSnsPhysicalName(input.ToResName(queue.Name), queue.Fifo)
+		queueArgs := &sqs.QueueArgs{
+			Name:                      sdk.String(queueName),
+			FifoQueue:                 sdk.Bool(queue.Fifo),
+			ContentBasedDeduplication: sdk.Bool(queue.ContentBasedDeduplication),
+			VisibilityTimeoutSeconds:  sdk.IntPtrFromPtr(queue.VisibilityTimeoutSec),
+			MessageRetentionSeconds:   sdk.IntPtrFromPtr(queue.MessageRetentionSec),
+			DelaySeconds:              sdk.IntPtrFromPtr(queue.DelaySec),
+			Tags:                      tags,
+		}
+
+		if queue.DeadLetterQueue != nil {
+			// dead-letter queue of a FIFO queue must be a FIFO queue too
+			dlqName := toSqsSnsPhysicalName(input.ToResName(queue.Name+"-dlq"), queue.Fifo)
+			params.Log.Info(ctx.Context(), "configure sqs dead-letter queue %q for %q in %q...", dlqName, input.StackParams.StackName, input.StackParams.Environment)
+			dlq, err := sqs.NewQueue(ctx, fmt.Sprintf("%s-queue", dlqName), &sqs.QueueArgs{
+				Name:                    sdk.String(dlqName),
+				FifoQueue:               sdk.Bool(queue.Fifo),
+				MessageRetentionSeconds: sdk.IntPtrFromPtr(queue.DeadLetterQueue.MessageRetentionSec),
+				Tags:                    tags,
+			}, opts...)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to create sqs dead-letter queue %q", dlqName)
+			}
+			ctx.Export(toSqsDlqUrlExport(resName, queue.Name), dlq.Url)
+			ctx.Export(toSqsDlqArnExport(resName, queue.Name), dlq.Arn)
+			out.Dlqs[queue.Name] = dlq
+
+			maxReceiveCount := lo.If(queue.DeadLetterQueue.MaxReceiveCount == 0, sqsDefaultMaxReceiveCount).Else(queue.DeadLetterQueue.MaxReceiveCount)
+			queueArgs.RedrivePolicy = dlq.Arn.ApplyT(func(dlqArn string) (string, error) {
+				redrivePolicy, err := json.Marshal(map[string]any{
+					"deadLetterTargetArn": dlqArn,
+					"maxReceiveCount":     maxReceiveCount,
+				})
+				return string(redrivePolicy), err
+			}).(sdk.StringOutput)
+		}
+
+		params.Log.Info(ctx.Context(), "configure sqs queue %q for %q in %q...", queueName, input.StackParams.StackName, input.StackParams.Environment)
+		sqsQueue, err := sqs.NewQueue(ctx, fmt.Sprintf("%s-queue", queueName), queueArgs, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create sqs queue %q", queueName)
+		}
+		ctx.Export(toSqsQueueUrlExport(resName, queue.Name), sqsQueue.Url)
+		ctx.Export(toSqsQueueArnExport(resName, queue.Name), sqsQueue.Arn)
+		out.Queues[queue.Name] = sqsQueue
+	}
+
+	if err := createSnsSubscriptions(ctx, sqsSnsCfg, resName, out, params, opts...); err != nil {
+		return nil, errors.Wrapf(err, "failed to create subscriptions for %q", input.Descriptor.Name)
+	}
+
+	if len(out.Dlqs) > 0 {
+		if err := createSqsDlqAlarms(ctx, stack, input, sqsSnsCfg, params, tags, opts...); err != nil {
+			return nil, errors.Wrapf(err, "failed to create dead-letter queue alarms for %q", input.Descriptor.Name)
+		}
+	}
+
+	return &api.ResourceOutput{Ref: out}, nil
+}
+
+func createSnsSubscriptions(ctx *sdk.Context, cfg *aws.SqsSnsConfig, resName string, out *SqsSnsOutput, params pApi.ProvisionParams, opts ...sdk.ResourceOption) error {
+	topicArnsByQueue := make(map[string][]any)
+	var subscribedQueues []string
+	for _, sub := range cfg.Subscriptions {
+		topic, queue := out.Topics[sub.Topic], out.Queues[sub.Queue]
+		subName := fmt.Sprintf("%s--%s--%s-sub", resName, sub.Topic, sub.Queue)
+
+		var filterPolicy sdk.StringPtrInput
+		if len(sub.FilterPolicy) > 0 {
+			filterPolicyJson, err := json.Marshal(sub.FilterPolicy)
+			if err != nil {
+				return errors.Wrapf(err, "failed to marshal filter policy of subscription %q", subName)
+			}
+			filterPolicy = sdk.String(string(filterPolicyJson))
+		}
+
+		params.Log.Info(ctx.Context(), "configure subscription of sqs queue %q to sns topic %q...", sub.Queue, sub.Topic)
+		_, err := sns.NewTopicSubscription(ctx, subName, &sns.TopicSubscriptionArgs{
+			Topic:              topic.Arn,
+			Protocol:           sdk.String("sqs"),
+			Endpoint:           queue.Arn,
+			RawMessageDelivery: sdk.Bool(sub.RawMessageDelivery),
+			FilterPolicy:       filterPolicy,
+		}, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create subscription %q", subName)
+		}
+
+		if _, ok := topicArnsByQueue[sub.Queue]; !ok {
+			subscribedQueues = append(subscribedQueues, sub.Queue)
+		}
+		topicArnsByQueue[sub.Queue] = append(topicArnsByQueue[sub.Queue], topic.Arn)
+	}
+
+	// queue can only have a single policy, so it allows delivery from all topics it is subscribed to
+	for _, queueName := range subscribedQueues {
+		queue := out.Queues[queueName]
+		policyName := fmt.Sprintf("%s--%s-queue-policy", resName, queueName)
+		_, err := sqs.NewQueuePolicy(ctx, policyName, &sqs.QueuePolicyArgs{
+			QueueUrl: queue.Url,
+			Policy: sdk.All(append([]any{queue.Arn}, topicArnsByQueue[queueName]...)...).ApplyT(func(args []any) (string, error) {
+				topicArns := lo.Map(args[1:], func(arn any, _ int) string {
+					return arn.(string)
+				})
+				policy, err := json.Marshal(map[string]any{
+					"Version": "2012-10-17",
+					"Statement": []map[string]any{
+						{
+							"Effect":    "Allow",
+							"Principal": map[string]any{"Service": "sns.amazonaws.com"},
+							"Action":    "sqs:SendMessage",
+							"Resource":  args[0].(string),
+							"Condition": map[string]any{
+								"ArnEquals": map[string]any{"aws:SourceArn": topicArns},
+							},
+						},
+					},
+				})
+				return string(policy), err
+			}).(sdk.StringOutput),
+		}, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create policy of sqs queue %q", queueName)
+		}
+	}
+	return nil
+}
+
+// createSqsDlqAlarms creates CloudWatch alarms on the number of messages in dead-letter queues,
+// notifications are sent only when they are configured in alerts
+func createSqsDlqAlarms(ctx *sdk.Context, stack api.Stack, input api.ResourceInput, cfg *aws.SqsSnsConfig, params pApi.ProvisionParams, tags sdk.StringMap, opts ...sdk.ResourceOption) error {
+	resName := input.ToResName(input.Descriptor.Name)
+	alerts := lo.FromPtr(cfg.Alerts)
+
+	var snsTopic *sns.Topic
+	if alerts.Email != nil && len(alerts.Email.Addresses) > 0 {
+		var err error
+		snsTopic, err = createSNSTopicForAlerts(ctx, fmt.Sprintf("%s-dlq-alerts", resName), tags, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create SNS topic for dead-letter queue alerts")
+		}
+		if err := createSNSEmailSubscriptions(ctx, snsTopic, alerts.Email.Addresses, fmt.Sprintf("%s-dlq", resName), opts...); err != nil {
+			return errors.Wrapf(err, "failed to create SNS email subscriptions")
+		}
+	}
+
+	hasWebhooks := alerts.Slack != nil || alerts.Discord != nil || alerts.Telegram != nil
+	var helpersImage *docker.Image
+	if hasWebhooks {
+		img, err := pushHelpersImageToECR(ctx, helperCfg{
+			imageName:       fmt.Sprintf("%s-sqs-helpers", resName),
+			ecrRepoName:     fmt.Sprintf("%s-sqs-helpers", resName),
+			opts:            opts,
+			provisionParams: params,
+			stack:           stack,
+			deployParams:    *input.StackParams,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to push cloud-helpers image for dead-letter queue alerts")
+		}
+		helpersImage = img
+		opts = append(opts, sdk.DependsOn([]sdk.Resource{helpersImage}))
+	}
+
+	for _, queue := range cfg.Queues {
+		if queue.DeadLetterQueue == nil {
+			continue
+		}
+		dlqName := toSqsSnsPhysicalName(input.ToResName(queue.Name+"-dlq"), queue.Fifo)
+		description := fmt.Sprintf("Messages are accumulating in dead-letter queue %s of %s", dlqName, input.StackParams.StackName)
+		// createAlert derives names of IAM roles from it, which must not exceed 64 characters
+		alertName := util.TrimStringWithHash(fmt.Sprintf("%s-dlq-%s", queue.Name, resName), 38, "-")
+		alarmArgs := cloudwatch.MetricAlarmArgs{
+			AlarmDescription:   sdk.String(description),
+			MetricName:         sdk.String("ApproximateNumberOfMessagesVisible"),
+			Namespace:          sdk.String("AWS/SQS"),
+			Statistic:          sdk.String("Maximum"),
+			Period:             sdk.Int(lo.If(alerts.PeriodSec == 0, 300).Else(alerts.PeriodSec)),
+			EvaluationPeriods:  sdk.Int(1),
+			Threshold:          sdk.Float64(lo.If(alerts.Threshold == 0, float64(1)).Else(alerts.Threshold)),
+			ComparisonOperator: sdk.String("GreaterThanOrEqualToThreshold"),
+			TreatMissingData:   sdk.String("notBreaching"),
+			Dimensions: sdk.StringMap{
+				"QueueName": sdk.String(dlqName),
+			},
+		}
+
+		params.Log.Info(ctx.Context(), "configure alarm on depth of dead-letter queue %q...", dlqName)
+		if hasWebhooks {
+			if err := createAlert(ctx, alertCfg{
+				name:            alertName,
+				description:     description,
+				slackConfig:     alerts.Slack,
+				discordConfig:   alerts.Discord,
+				telegramConfig:  alerts.Telegram,
+				deployParams:    *input.StackParams,
+				secretSuffix:    resName,
+				helpersImage:    helpersImage,
+				snsTopic:        snsTopic,
+				opts:            opts,
+				tags:            tags,
+				metricAlarmArgs: alarmArgs,
+			}); err != nil {
+				return errors.Wrapf(err, "failed to create alert for dead-letter queue %q", dlqName)
+			}
+			continue
+		}
+		if snsTopic != nil {
+			actions := sdk.Array{snsTopic.Arn}
+			alarmArgs.AlarmActions = actions
+			alarmArgs.OkActions = actions
+		}
+		alarmArgs.Tags = tags
+		if _, err := cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("%s-alarm", alertName), &alarmArgs, opts...); err != nil {
+			return errors.Wrapf(err, "failed to create alarm for dead-letter queue %q", dlqName)
+		}
+	}
+	return nil
+}
+
+// sqsSnsAccessPolicy builds policy allowing to use the given queues and publish to the given topics only
+func sqsSnsAccessPolicy(queueArns, topicArns []string) (string, error) {
+	var statements []map[string]any
+	if len(queueArns) > 0 {
+		statements = append(statements, map[string]any{
+			"Effect": "Allow",
+			"Action": []string{
+				"sqs:SendMessage",
+				"sqs:ReceiveMessage",
+				"sqs:DeleteMessage",
+				"sqs:ChangeMessageVisibility",
+				"sqs:GetQueueAttributes",
+				"sqs:GetQueueUrl",
+			},
+			"Resource": queueArns,
+		})
+	}
+	if len(topicArns) > 0 {
+		statements = append(statements, map[string]any{
+			"Effect": "Allow",
+			"Action": []string{
+				"sns:Publish",
+				"sns:GetTopicAttributes",
+			},
+			"Resource": topicArns,
+		})
+	}
+	policy, err := json.Marshal(map[string]any{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	})
+	return string(policy), err
+}
+
+// toSqsSnsPhysicalName appends suffix required by AWS for names of FIFO queues and topics
+func toSqsSnsPhysicalName(name string, fifo bool) string {
+	return name + lo.If(fifo, ".fifo").Else("")
+}
+
+func toSnsTopicArnExport(resName, topicName string) string {
+	return fmt.Sprintf("%s--%s-topic-arn", resName, topicName)
+}
+
+func toSqsQueueUrlExport(resName, queueName string) string {
+	return fmt.Sprintf("%s--%s-queue-url", resName, queueName)
+}
+
+func toSqsQueueArnExport(resName, queueName string) string {
+	return fmt.Sprintf("%s--%s-queue-arn", resName, queueName)
+}
+
+func toSqsDlqUrlExport(resName, queueName string) string {
+	return fmt.Sprintf("%s--%s-dlq-url", resName, queueName)
+}
+
+func toSqsDlqArnExport(resName, queueName string) string {
+	return fmt.Sprintf("%s--%s-dlq-arn", resName, queueName)
+}
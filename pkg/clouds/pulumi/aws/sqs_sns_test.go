@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSqsSnsAccessPolicy(t *testing.T) {
+	RegisterTestingT(t)
+
+	queueArns := []string{"arn:aws:sqs:us-east-1:123456789012:jobs--prod", "arn:aws:sqs:us-east-1:123456789012:jobs-dlq--prod"}
+	topicArns := []string{"arn:aws:sns:us-east-1:123456789012:events--prod"}
+
+	policyJson, err := sqsSnsAccessPolicy(queueArns, topicArns)
+	Expect(err).ToNot(HaveOccurred())
+
+	var policy struct {
+		Statement []struct {
+			Effect   string   `json:"Effect"`
+			Action   []string `json:"Action"`
+			Resource []string `json:"Resource"`
+		} `json:"Statement"`
+	}
+	Expect(json.Unmarshal([]byte(policyJson), &policy)).To(Succeed())
+	Expect(policy.Statement).To(HaveLen(2))
+	// access must be scoped to declared queues and topics, never granted on "*"
+	Expect(policy.Statement[0].Resource).To(Equal(queueArns))
+	Expect(policy.Statement[0].Action).To(ContainElements("sqs:SendMessage", "sqs:ReceiveMessage", "sqs:DeleteMessage"))
+	Expect(policy.Statement[1].Resource).To(Equal(topicArns))
+	Expect(policy.Statement[1].Action).To(ContainElement("sns:Publish"))
+
+	queuesOnly, err := sqsSnsAccessPolicy(queueArns, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(queuesOnly).ToNot(ContainSubstring("sns:"))
+}
+
+func TestSqsSnsNames(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(toSqsSnsPhysicalName("jobs--prod", false)).To(Equal("jobs--prod"))
+	Expect(toSqsSnsPhysicalName("jobs--prod", true)).To(Equal("jobs--prod.fifo"))
+	Expect(toSqsQueueUrlExport("messaging--prod", "jobs")).To(Equal("messaging--prod--jobs-queue-url"))
+	Expect(toSqsDlqArnExport("messaging--prod", "jobs")).To(Equal("messaging--prod--jobs-dlq-arn"))
+	Expect(toSnsTopicArnExport("messaging--prod", "events")).To(Equal("messaging--prod--events-topic-arn"))
+}
@@ -20,7 +20,16 @@ func (p *pulumi) CancelStack(ctx context.Context, cfg *api.ConfigFile, stack api
 	if err != nil {
 		return err
 	}
-	return p.cancelStack(ctx, cfg)
+	emitOperationStart(ctx, ActionContextCancel, params.StackName)
+	err = p.cancelStack(ctx, cfg)
+	// lock is released even if cancel failed, since canceled run may not be able to release it on its own
+	if lock, unlockErr := p.unlockStack(ctx, p.stackRef); unlockErr != nil {
+		p.logger.Warn(ctx, "failed to release lock of stack %q: %v", p.stackRef.FullyQualifiedName(), unlockErr)
+	} else if lock != nil {
+		p.logger.Info(ctx, "%s", color.YellowFmt("Released lock held by %s", lock))
+	}
+	emitOperationSummary(ctx, ActionContextCancel, params.StackName, api.OperationEvent{}, err)
+	return err
 }
 
 func (p *pulumi) cancelStack(ctx context.Context, cfg *api.ConfigFile) error {
@@ -108,6 +108,11 @@ func stackCheckpointNotFound(err error) bool {
 	if !strings.Contains(msg, "failed to load checkpoint") {
 		return false
 	}
+	return blobNotFoundMessage(msg)
+}
+
+// blobNotFoundMessage returns true when error message of the blob operation indicates missing object
+func blobNotFoundMessage(msg string) bool {
 	// Provider-specific 404 markers that gcerrors.Code may miss after a
 	// transitive bump. Match case-insensitively to defend against
 	// formatting drift across client versions ("NotFound" vs "notFound",
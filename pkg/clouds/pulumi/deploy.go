@@ -24,34 +24,54 @@ import (
 	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
 )
 
-func (p *pulumi) deployStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.DeployParams) error {
+// deployRevisions holds revisions of the stack captured by the deploy program
+type deployRevisions struct {
+	nextUpdate int                  // version of the upcoming stack update
+	previous   []api.DeployRevision // revisions of previous updates (newest first)
+	replay     *api.DeployRevision  // when set, stack descriptor of this revision is deployed
+}
+
+func (p *pulumi) deployStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.DeployParams, replay *api.DeployRevision) (updateResult *api.UpdateResult, err error) {
+	emitOperationStart(ctx, ActionContextDeploy, params.StackName)
+	defer func() {
+		emitOperationSummary(ctx, ActionContextDeploy, params.StackName, api.OperationEvent{Update: updateResult}, err)
+	}()
 	s, err := p.validateStateAndGetStack(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	release, err := p.lockStack(ctx, s.Ref(), ActionContextDeploy, params.Timeouts.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	p.logger.Info(ctx, "%s", color.GreenFmt("Deploying stack %q...", s.Ref().FullyQualifiedName()))
 	parentStack := stack.Client.Stacks[params.Environment].ParentStack
 	fullStackName := s.Ref().FullyQualifiedName().String()
 
-	stackSource, err := p.prepareStackForOperations(ctx, s.Ref(), cfg, p.deployStackProgram(stack, params.StackParams, parentStack, fullStackName))
+	revisions := &deployRevisions{replay: replay}
+	stackSource, err := p.prepareStackForOperations(ctx, s.Ref(), cfg, p.deployStackProgram(stack, params.StackParams, parentStack, fullStackName, revisions))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !params.SkipRefresh {
 		p.logger.Info(ctx, "%s", color.GreenFmt("Refreshing stack %q...", stackSource.Name()))
 		refreshResult, err := stackSource.Refresh(ctx, optrefresh.EventStreams(p.watchEvents(WithContextAction(ctx, ActionContextRefresh))))
 		if err != nil {
-			return err
+			return nil, err
 		}
 		p.logger.Info(ctx, "%s", color.GreenFmt("Refresh summary: \n%s", p.toRefreshResult(refreshResult)))
 	}
-	if !params.SkipPreview {
+	if err := p.loadDeployRevisions(ctx, stackSource, revisions); err != nil {
+		return nil, err
+	}
+	// preview cannot be skipped when policies have to be evaluated against the plan
+	checkPolicies := api.HasPoliciesForEnv(stack.Server.Policies, params.Environment)
+	if !params.SkipPreview || checkPolicies {
 		p.logger.Info(ctx, "%s", color.GreenFmt("Preview stack %q...", stackSource.Name()))
 
-		previewOpts := []optpreview.Option{
-			optpreview.EventStreams(p.watchEvents(WithContextAction(ctx, ActionContextPreview))),
-		}
+		var previewOpts []optpreview.Option
 
 		// Add detailed diff option if requested
 		if params.DetailedDiff {
@@ -59,11 +79,17 @@ func (p *pulumi) deployStack(ctx context.Context, cfg *api.ConfigFile, stack api
 			p.logger.Info(ctx, "🔍 Diff enabled - showing granular changes for nested properties")
 		}
 
-		previewResult, err := stackSource.Preview(ctx, previewOpts...)
+		previewResult, err := p.previewWithSteps(ctx, stackSource, stackSource.Name(), previewOpts...)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		p.logger.Info(ctx, "%s", color.GreenFmt("Preview summary: \n%s", previewResult))
+		emitOperationSummary(ctx, ActionContextPreview, params.StackName, api.OperationEvent{Preview: previewResult}, nil)
+		if checkPolicies {
+			if err := p.checkPolicies(ctx, stack.Server.Policies, stackSource.Name(), params.Environment, previewResult, params.OverridePolicies); err != nil {
+				return nil, err
+			}
 		}
-		p.logger.Info(ctx, "%s", color.GreenFmt("Preview summary: \n%s", p.toPreviewResult(stackSource.Name(), previewResult)))
 	}
 	p.logger.Info(ctx, "%s", color.GreenFmt("Updating stack %q...", stackSource.Name()))
 	if timeoutDuration, err := time.ParseDuration(params.Timeouts.ExecutionTimeout); err == nil {
@@ -85,13 +111,14 @@ func (p *pulumi) deployStack(ctx context.Context, cfg *api.ConfigFile, stack api
 
 	upRes, err := stackSource.Up(ctx, upOpts...)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	p.logger.Info(ctx, "%s", color.GreenFmt("Update summary: \n%s", p.toUpdateResult(stackSource.Name(), upRes)))
-	return nil
+	updateResult = p.toUpdateResult(stackSource.Name(), upRes)
+	p.logger.Info(ctx, "%s", color.GreenFmt("Update summary: \n%s", updateResult))
+	return updateResult, nil
 }
 
-func (p *pulumi) deployStackProgram(stack api.Stack, params api.StackParams, parentStack string, fullStackName string) func(ctx *sdk.Context) error {
+func (p *pulumi) deployStackProgram(stack api.Stack, params api.StackParams, parentStack string, fullStackName string, revisions *deployRevisions) func(ctx *sdk.Context) error {
 	return func(ctx *sdk.Context) error {
 		stackClientDesc := stack.Client.Stacks[params.Environment]
 		parentEnv := params.Environment
@@ -111,34 +138,21 @@ func (p *pulumi) deployStackProgram(stack api.Stack, params api.StackParams, par
 		parentFullReference := pApi.ExpandStackReference(parentStack, p.provisionerCfg.Organization, p.configFile.ProjectName)
 		parentNameOnly := pApi.CollapseStackReference(parentFullReference)
 
-		// get template from parent
-		templateRef := stackDescriptorTemplateName(parentFullReference, templateName)
-		var stackDesc api.StackDescriptor
-		stackDescYaml, err := pApi.GetValueFromStack[string](ctx, fmt.Sprintf("%s-template", parentFullReference), parentFullReference, templateRef, true)
-		if err != nil {
-			return errors.Wrapf(err, "failed to get template descriptpor for stack %q in %q", parentStack, params.Environment)
-		}
-		if stackDescYaml == "" {
-			return errors.Errorf("no template descriptor for stack %q in %q, consider re-provisioning of parent stack", parentStack, params.Environment)
+		var clientStackDesc *api.StackDescriptor
+		var err error
+		if revisions != nil && revisions.replay != nil {
+			p.logger.Info(ctx.Context(), "replaying stack descriptor of update %d (version %q)", revisions.replay.UpdateVersion, revisions.replay.Version)
+			clientStackDesc, err = revisions.replay.StackDescriptor()
+		} else {
+			clientStackDesc, err = p.prepareClientStackDescriptor(ctx, params, stackClientDesc, templateName, parentStack, parentFullReference, fullStackName)
 		}
-		err = yaml.Unmarshal([]byte(stackDescYaml), &stackDesc)
 		if err != nil {
-			return errors.Wrapf(err, "failed to serialize template's %q descriptor", templateName)
+			return err
 		}
-
-		stackDir := params.StackDir
-
-		if stackDir == "" {
-			// assuming stack's directory is related to stacks
-			if params.StacksDir == "" {
-				return errors.Errorf("either single stack's or all stacks directory must be specified")
+		if revisions != nil {
+			if err := p.exportDeployRevisions(ctx, params, stackClientDesc.Type, *clientStackDesc, revisions); err != nil {
+				return errors.Wrapf(err, "failed to capture revision of stack %q in env %q", fullStackName, params.Environment)
 			}
-			stackDir = filepath.Join(params.StacksDir, params.StackName)
-		}
-
-		clientStackDesc, err := api.PrepareClientConfigForDeploy(ctx.Context(), stackDir, fullStackName, stackDesc, stackClientDesc)
-		if err != nil {
-			return errors.Wrapf(err, "failed to prepare client descriptor for deploy for stack %q in env %q", fullStackName, params.Environment)
 		}
 
 		dnsPreference := &pApi.DnsPreference{}
@@ -242,10 +256,50 @@ func (p *pulumi) deployStackProgram(stack api.Stack, params api.StackParams, par
 			return "success", nil
 		})
 		ctx.Export(fmt.Sprintf("%s-%s-outcome", params.StackName, params.Environment), deployOut)
+		ctx.Export(api.DeployVersionOutput, sdk.String(params.Version))
+		if params.PromotedFrom != "" {
+			ctx.Export(api.PromotedFromOutput, sdk.String(params.PromotedFrom))
+		}
 		return nil
 	}
 }
 
+// prepareClientStackDescriptor resolves client stack descriptor for deploy from parent's template and client configuration
+func (p *pulumi) prepareClientStackDescriptor(ctx *sdk.Context, params api.StackParams, stackClientDesc api.StackClientDescriptor,
+	templateName, parentStack, parentFullReference, fullStackName string,
+) (*api.StackDescriptor, error) {
+	// get template from parent
+	templateRef := stackDescriptorTemplateName(parentFullReference, templateName)
+	var stackDesc api.StackDescriptor
+	stackDescYaml, err := pApi.GetValueFromStack[string](ctx, fmt.Sprintf("%s-template", parentFullReference), parentFullReference, templateRef, true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get template descriptpor for stack %q in %q", parentStack, params.Environment)
+	}
+	if stackDescYaml == "" {
+		return nil, errors.Errorf("no template descriptor for stack %q in %q, consider re-provisioning of parent stack", parentStack, params.Environment)
+	}
+	err = yaml.Unmarshal([]byte(stackDescYaml), &stackDesc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to serialize template's %q descriptor", templateName)
+	}
+
+	stackDir := params.StackDir
+
+	if stackDir == "" {
+		// assuming stack's directory is related to stacks
+		if params.StacksDir == "" {
+			return nil, errors.Errorf("either single stack's or all stacks directory must be specified")
+		}
+		stackDir = filepath.Join(params.StacksDir, params.StackName)
+	}
+
+	clientStackDesc, err := api.PrepareClientConfigForDeploy(ctx.Context(), stackDir, fullStackName, stackDesc, stackClientDesc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to prepare client descriptor for deploy for stack %q in env %q", fullStackName, params.Environment)
+	}
+	return clientStackDesc, nil
+}
+
 type dependencyResourceParams struct {
 	resName           string // name of the resource in parent stack
 	resEnv            string // this is where the resource should be declared in parent stack
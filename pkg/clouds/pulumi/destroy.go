@@ -18,7 +18,19 @@ import (
 	"github.com/simple-container-com/api/pkg/api/logger/color"
 )
 
-func (p *pulumi) destroyStack(ctx context.Context, cfg *api.ConfigFile, s backend.Stack, params api.DestroyParams, program func(ctx *sdk.Context) error, preview bool, preDestroyHooks ...func(auto.Stack)) error {
+func (p *pulumi) destroyStack(ctx context.Context, cfg *api.ConfigFile, s backend.Stack, params api.DestroyParams, program func(ctx *sdk.Context) error, preview bool, preDestroyHooks ...func(auto.Stack)) (err error) {
+	var summary api.OperationEvent
+	emitOperationStart(ctx, ActionContextDestroy, params.StackName)
+	defer func() {
+		emitOperationSummary(ctx, ActionContextDestroy, params.StackName, summary, err)
+	}()
+	if !preview {
+		release, err := p.lockStack(ctx, s.Ref(), ActionContextDestroy, params.Timeouts.LockTimeout)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
 	stackSource, err := p.prepareStackForOperations(ctx, s.Ref(), cfg, program)
 	if err != nil {
 		return err
@@ -45,7 +57,8 @@ func (p *pulumi) destroyStack(ctx context.Context, cfg *api.ConfigFile, s backen
 		if err != nil {
 			return err
 		}
-		p.logger.Info(ctx, "%s", color.RedFmt("Preview destroy summary: \n%s", p.toPreviewResult(params.StackName, previewResult)))
+		summary.Preview = p.toPreviewResult(params.StackName, previewResult)
+		p.logger.Info(ctx, "%s", color.RedFmt("Preview destroy summary: \n%s", summary.Preview))
 		return nil
 	}
 	p.logger.Info(ctx, "%s", color.RedFmt("Destroying stack %q...", s.Ref().FullyQualifiedName()))
@@ -53,7 +66,8 @@ func (p *pulumi) destroyStack(ctx context.Context, cfg *api.ConfigFile, s backen
 	if err != nil {
 		return err
 	}
-	p.logger.Info(ctx, "%s", color.RedFmt("Destroy summary: \n%s", p.toDestroyResult(destroyResult)))
+	summary.Destroy = p.toDestroyResult(destroyResult)
+	p.logger.Info(ctx, "%s", color.RedFmt("Destroy summary: \n%s", summary.Destroy))
 	s, err = p.validateStateAndGetStack(ctx)
 	if err != nil {
 		return err
@@ -48,8 +48,11 @@ type Image struct {
 
 // ImageOut is the result of BuildAndPushImage.
 type ImageOut struct {
-	Image   *docker.Image
-	AddOpts []sdk.ResourceOption
+	// Image is nil when a pinned image is deployed instead of building a new one
+	Image *docker.Image
+	// ImageName is the image reference the service should be deployed with
+	ImageName sdk.StringOutput
+	AddOpts   []sdk.ResourceOption
 }
 
 // BuildAndPushImage builds a Docker image, pushes it, and runs security
@@ -58,7 +61,19 @@ type ImageOut struct {
 // The service update (ECS task definition / K8s deployment) depends on
 // ImageOut.AddOpts, which gates on sign+verify — not on scan. Scan runs
 // parallel and reports findings without blocking the deploy.
+//
+// When deployParams pin images (e.g. on promotion or rollback), nothing is built
+// and the pinned immutable reference is returned instead.
 func BuildAndPushImage(ctx *sdk.Context, stack api.Stack, params pApi.ProvisionParams, deployParams api.StackParams, image Image) (*ImageOut, error) {
+	if pinnedRef, pinned := deployParams.PinnedImages[image.Name]; pinned {
+		params.Log.Info(ctx.Context(), "using pinned image %q for %q in stack %q env %q, skipping build",
+			pinnedRef, image.Name, stack.Name, deployParams.Environment)
+		ctx.Export(api.ImageDigestOutputName(image.Name), sdk.String(pinnedRef))
+		return &ImageOut{ImageName: sdk.String(pinnedRef).ToStringOutput()}, nil
+	} else if deployParams.PinnedImages != nil {
+		return nil, errors.Errorf("image %q is not pinned for stack %q env %q, it cannot be deployed without rebuild", image.Name, stack.Name, deployParams.Environment)
+	}
+
 	imageFullUrl := image.RepositoryUrl.ApplyT(func(repoUri string) string {
 		if image.RepositoryUrlWithImage {
 			return fmt.Sprintf("%s:%s", repoUri, image.Version)
@@ -110,8 +125,10 @@ func BuildAndPushImage(ctx *sdk.Context, stack api.Stack, params pApi.ProvisionP
 	if len(addOpts) == 0 {
 		addOpts = append(addOpts, sdk.DependsOn([]sdk.Resource{res}))
 	}
+	// immutable reference of the pushed image is used to promote it to other environments
+	ctx.Export(api.ImageDigestOutputName(image.Name), res.RepoDigest)
 
-	return &ImageOut{Image: res, AddOpts: addOpts}, nil
+	return &ImageOut{Image: res, ImageName: res.ImageName, AddOpts: addOpts}, nil
 }
 
 // executeSecurityOperations creates Pulumi resources for post-push security ops.
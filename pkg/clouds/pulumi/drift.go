@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optrefresh"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+func (p *pulumi) DriftStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) (*api.DriftReport, error) {
+	if params.Environment != "" && params.StackName != "" {
+		stack = toChildStack(stack, params)
+	}
+	s, err := p.selectStack(ctx, cfg, stack)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, errors.Errorf("stack %q does not exist", stack.Name)
+	}
+	stackSource, err := p.prepareStackForOperations(ctx, s.Ref(), cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.Info(ctx, "%s", color.GreenFmt("Detecting drift of stack %q...", stackSource.Name()))
+	var lock sync.Mutex
+	byURN := make(map[string]api.DriftResource)
+	collector := collectEvents(func(evt events.EngineEvent) {
+		if evt.ResOutputsEvent == nil {
+			return
+		}
+		if res, ok := toDriftResource(evt.ResOutputsEvent.Metadata); ok {
+			lock.Lock()
+			defer lock.Unlock()
+			byURN[res.URN] = res
+		}
+	})
+	_, err = stackSource.PreviewRefresh(ctx, optrefresh.EventStreams(
+		p.watchEvents(WithContextAction(ctx, ActionContextRefresh)),
+		collector.events,
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to refresh stack %q", stackSource.Name())
+	}
+	if !collector.wait(collectEventsTimeout) {
+		p.logger.Warn(ctx, "timed out waiting for refresh events of stack %q, report may be incomplete", stackSource.Name())
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	report := &api.DriftReport{
+		StackName:   params.StackName,
+		Environment: params.Environment,
+		Resources:   lo.Values(byURN),
+	}
+	report.Sort()
+	return report, nil
+}
+
+// toDriftResource converts refresh step of the resource into its drift status
+func toDriftResource(meta apitype.StepEventMetadata) (api.DriftResource, bool) {
+	state := lo.Ternary(meta.New != nil, meta.New, meta.Old)
+	if state == nil || !state.Custom || meta.Type == "pulumi:pulumi:Stack" || strings.HasPrefix(meta.Type, "pulumi:providers:") {
+		return api.DriftResource{}, false
+	}
+	res := api.DriftResource{
+		URN:    meta.URN,
+		Type:   meta.Type,
+		Status: api.DriftStatusInSync,
+	}
+	switch {
+	case meta.New == nil || meta.Op == apitype.OpDelete:
+		res.Status = api.DriftStatusDeleted
+	case len(meta.DetailedDiff) > 0:
+		res.Status = api.DriftStatusDrifted
+		for path, diff := range meta.DetailedDiff {
+			res.Properties = append(res.Properties, api.DriftedProperty{Path: path, Kind: string(diff.Kind)})
+		}
+	case len(meta.Diffs) > 0:
+		res.Status = api.DriftStatusDrifted
+		for _, path := range meta.Diffs {
+			res.Properties = append(res.Properties, api.DriftedProperty{Path: path, Kind: string(apitype.DiffUpdate)})
+		}
+	case meta.Old != nil:
+		res.Properties = outputsDiff(meta.Old.Outputs, meta.New.Outputs)
+		if len(res.Properties) > 0 || meta.Op == apitype.OpUpdate || meta.Op == apitype.OpReplace {
+			res.Status = api.DriftStatusDrifted
+		}
+	}
+	sort.Slice(res.Properties, func(i, j int) bool {
+		return res.Properties[i].Path < res.Properties[j].Path
+	})
+	return res, true
+}
+
+// outputsDiff compares top-level outputs of the resource before and after refresh
+func outputsDiff(old, new map[string]any) []api.DriftedProperty {
+	var res []api.DriftedProperty
+	for key, oldValue := range old {
+		if newValue, found := new[key]; !found {
+			res = append(res, api.DriftedProperty{Path: key, Kind: string(apitype.DiffDelete)})
+		} else if !reflect.DeepEqual(oldValue, newValue) {
+			res = append(res, api.DriftedProperty{Path: key, Kind: string(apitype.DiffUpdate)})
+		}
+	}
+	for key := range new {
+		if _, found := old[key]; !found {
+			res = append(res, api.DriftedProperty{Path: key, Kind: string(apitype.DiffAdd)})
+		}
+	}
+	return res
+}
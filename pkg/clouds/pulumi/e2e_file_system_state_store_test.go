@@ -6,8 +6,10 @@
 package pulumi
 
 import (
+	"context"
 	"testing"
 
+	"github.com/simple-container-com/api/pkg/clouds/fs"
 	"github.com/simple-container-com/api/pkg/clouds/pulumi/testutil"
 
 	. "github.com/onsi/gomega"
@@ -41,3 +43,63 @@ func Test_CreateFileSystemStateParentStack(t *testing.T) {
 	runProvisionTest(stack, cfg)
 	runDestroyParentTest(stack, cfg)
 }
+
+func Test_MigrateFileSystemStateParentStack(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	cfg := testutil.PrepareE2Etest()
+
+	stack := api.Stack{
+		Name: tmpResName(e2eFileSystemStateParentStackName),
+		Server: e2eServerDescriptorForFileSystem(e2eConfig{
+			templates: map[string]api.StackDescriptor{},
+			resources: map[string]api.PerEnvResourcesDescriptor{},
+			registrar: api.RegistrarDescriptor{},
+		}),
+		Client: api.ClientDescriptor{
+			Stacks: map[string]api.StackClientDescriptor{},
+		},
+	}
+	runProvisionTest(stack, cfg)
+	defer runDestroyParentTest(stack, cfg)
+
+	// same stack in another file system state storage with another passphrase
+	target := stack
+	target.Server.Provisioner.Config = api.Config{Config: &ProvisionerConfig{
+		Organization: "organization",
+		StateStorage: StateStorageConfig{
+			Type:   StateStorageTypeFileSystem,
+			Config: api.Config{Config: &fs.FileSystemStateStorage{Path: "file://" + t.TempDir()}},
+		},
+		SecretsProvider: SecretsProviderConfig{
+			Type:   SecretsProviderTypePassPhrase,
+			Config: api.Config{Config: &fs.PassphraseSecretsProvider{PassPhrase: "another-test-pass-phrase"}},
+		},
+	}}
+
+	sourceProv, err := InitPulumiProvisioner(stack.Server.Provisioner.Config)
+	Expect(err).To(BeNil())
+	sourceProv.SetPublicKey(cfg.Cryptor.PublicKey())
+	targetProv, err := InitPulumiProvisioner(target.Server.Provisioner.Config)
+	Expect(err).To(BeNil())
+	targetProv.SetPublicKey(cfg.Cryptor.PublicKey())
+
+	state, err := sourceProv.ExportStack(ctx, cfg.ConfigFile, stack, api.StackParams{})
+	Expect(err).To(BeNil())
+	Expect(state).NotTo(BeNil())
+	sourceCount, err := state.ResourceCount()
+	Expect(err).To(BeNil())
+	Expect(sourceCount).To(BeNumerically(">", 0))
+
+	missing, err := targetProv.ExportStack(ctx, cfg.ConfigFile, target, api.StackParams{})
+	Expect(err).To(BeNil())
+	Expect(missing).To(BeNil())
+
+	Expect(targetProv.ImportStack(ctx, cfg.ConfigFile, target, api.StackParams{}, *state)).To(Succeed())
+
+	imported, err := targetProv.ExportStack(ctx, cfg.ConfigFile, target, api.StackParams{})
+	Expect(err).To(BeNil())
+	Expect(imported).NotTo(BeNil())
+	Expect(imported.ResourceCount()).To(Equal(sourceCount))
+}
@@ -172,7 +172,7 @@ func runProvisionAndDeployTest(stack api.Stack, cfg secretTestutil.E2ETestConfig
 
 	deployProv.SetPublicKey(cfg.Cryptor.PublicKey())
 
-	err = deployProv.DeployStack(ctx, cfg.ConfigFile, stack, api.DeployParams{
+	_, err = deployProv.DeployStack(ctx, cfg.ConfigFile, stack, api.DeployParams{
 		StackParams: api.StackParams{
 			StackDir:    cfg.StacksDir,
 			StackName:   deployStackName,
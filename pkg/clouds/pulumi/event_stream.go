@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"context"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+// stepRecorder converts engine events of a single operation into api.OperationEvent step records
+type stepRecorder struct {
+	operation   string
+	started     map[string]time.Time
+	diagnostics map[string]string
+}
+
+func newStepRecorder(operation string) *stepRecorder {
+	return &stepRecorder{
+		operation:   operation,
+		started:     make(map[string]time.Time),
+		diagnostics: make(map[string]string),
+	}
+}
+
+func (r *stepRecorder) record(ctx context.Context, evt events.EngineEvent) {
+	switch {
+	case evt.ResourcePreEvent != nil:
+		r.started[evt.ResourcePreEvent.Metadata.URN] = time.Now()
+	case evt.DiagnosticEvent != nil && evt.DiagnosticEvent.URN != "":
+		if evt.DiagnosticEvent.Severity == "error" || evt.DiagnosticEvent.Severity == "warning" {
+			r.diagnostics[evt.DiagnosticEvent.URN] = evt.DiagnosticEvent.Message
+		}
+	case evt.ResOutputsEvent != nil:
+		api.EmitOperationEvent(ctx, r.stepEvent(evt.ResOutputsEvent.Metadata, api.OperationStatusSucceeded))
+	case evt.ResOpFailedEvent != nil:
+		api.EmitOperationEvent(ctx, r.stepEvent(evt.ResOpFailedEvent.Metadata, api.OperationStatusFailed))
+	}
+}
+
+func (r *stepRecorder) stepEvent(meta apitype.StepEventMetadata, status string) api.OperationEvent {
+	evt := api.OperationEvent{
+		Type:         api.OperationEventStep,
+		Operation:    r.operation,
+		URN:          meta.URN,
+		ResourceType: meta.Type,
+		Op:           string(meta.Op),
+		Status:       status,
+		Diagnostic:   r.diagnostics[meta.URN],
+	}
+	if started, ok := r.started[meta.URN]; ok {
+		evt.DurationMs = time.Since(started).Milliseconds()
+		delete(r.started, meta.URN)
+	}
+	delete(r.diagnostics, meta.URN)
+	return evt
+}
+
+func emitOperationStart(ctx context.Context, action contextActionValue, stackName string) {
+	api.EmitOperationEvent(ctx, api.OperationEvent{
+		Type:      api.OperationEventStart,
+		Operation: string(action),
+		Stack:     stackName,
+	})
+}
+
+// emitOperationSummary emits summary record of the operation, evt may carry result of the operation
+func emitOperationSummary(ctx context.Context, action contextActionValue, stackName string, evt api.OperationEvent, err error) {
+	evt.Type = api.OperationEventSummary
+	evt.Operation = string(action)
+	evt.Stack = stackName
+	evt.Status = api.OperationStatusSucceeded
+	if err != nil {
+		evt.Status = api.OperationStatusFailed
+		evt.Error = err.Error()
+	}
+	api.EmitOperationEvent(ctx, evt)
+}
@@ -7,11 +7,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/samber/lo"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+
+	"github.com/simple-container-com/api/pkg/api"
 )
 
 type contextValueType struct{}
@@ -36,17 +39,58 @@ func WithContextAction(ctx context.Context, action contextActionValue) context.C
 
 func (p *pulumi) watchEvents(ctx context.Context) chan events.EngineEvent {
 	eventChan := make(chan events.EngineEvent)
+	recordSteps := api.HasOperationEvents(ctx)
+	steps := newStepRecorder(p.contextActionName(ctx))
 	go func() {
 		for {
 			if ctx.Err() != nil {
 				return
 			}
-			p.processEvent(ctx, <-eventChan)
+			evt, ok := <-eventChan
+			if !ok {
+				return
+			}
+			p.processEvent(ctx, evt)
+			if recordSteps {
+				steps.record(ctx, evt)
+			}
 		}
 	}()
 	return eventChan
 }
 
+const collectEventsTimeout = 30 * time.Second
+
+// eventsCollector passes engine events of an operation to the handler in a separate goroutine
+type eventsCollector struct {
+	events chan events.EngineEvent
+	done   chan struct{}
+}
+
+func collectEvents(handler func(evt events.EngineEvent)) *eventsCollector {
+	c := &eventsCollector{
+		events: make(chan events.EngineEvent),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(c.done)
+		for evt := range c.events {
+			handler(evt)
+		}
+	}()
+	return c
+}
+
+// wait waits until all events are handled, returns false on timeout
+func (c *eventsCollector) wait(timeout time.Duration) bool {
+	select {
+	case <-c.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
 func (p *pulumi) processEvent(ctx context.Context, evt events.EngineEvent) {
 	switch {
 	case evt.ResOutputsEvent != nil:
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/gcsblob"
+	"gocloud.dev/gcerrors"
+
+	"github.com/pulumi/pulumi/pkg/v3/authhelpers"
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+const (
+	stackLocksDir         = ".sc/locks"
+	stackLockPollInterval = 10 * time.Second
+)
+
+// stackLocks stores locks of stacks in the bucket of the state storage, next to the state itself
+type stackLocks struct {
+	bucket *blob.Bucket
+}
+
+func stackLockKey(ref backend.StackReference) string {
+	return path.Join(stackLocksDir, ref.FullyQualifiedName().String()+".json")
+}
+
+// acquire writes lock unless it already exists, in which case the existing lock is returned
+func (l *stackLocks) acquire(ctx context.Context, key string, lock api.StackLock) (*api.StackLock, error) {
+	content, err := json.Marshal(lock)
+	if err != nil {
+		return nil, err
+	}
+	writeErr := l.bucket.WriteAll(ctx, key, content, &blob.WriterOptions{
+		ContentType: "application/json",
+		IfNotExist:  true,
+	})
+	if writeErr == nil {
+		return nil, nil
+	}
+	// write either failed because of the existing lock or for another reason
+	existing, err := l.get(ctx, key)
+	if err != nil || existing == nil {
+		return nil, errors.Wrapf(writeErr, "failed to write lock %q", key)
+	}
+	return existing, nil
+}
+
+// get returns current lock (nil if stack is not locked)
+func (l *stackLocks) get(ctx context.Context, key string) (*api.StackLock, error) {
+	content, err := l.bucket.ReadAll(ctx, key)
+	if blobNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read lock %q", key)
+	}
+	var res api.StackLock
+	if err := json.Unmarshal(content, &res); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal lock %q", key)
+	}
+	return &res, nil
+}
+
+// release removes lock if it is held by lockID (any lock if lockID is empty) and returns removed lock
+func (l *stackLocks) release(ctx context.Context, key string, lockID string) (*api.StackLock, error) {
+	existing, err := l.get(ctx, key)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if lockID != "" && existing.ID != lockID {
+		return nil, errors.Errorf("lock %q is held by another operation: %s", key, existing)
+	}
+	if err := l.bucket.Delete(ctx, key); err != nil && !blobNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to delete lock %q", key)
+	}
+	return existing, nil
+}
+
+// blobNotFound returns true if err indicates missing object, even if provider client hides it from gcerrors
+// (see stackCheckpointNotFound)
+func blobNotFound(err error) bool {
+	return err != nil && (gcerrors.Code(err) == gcerrors.NotFound || blobNotFoundMessage(err.Error()))
+}
+
+func (l *stackLocks) close() {
+	_ = l.bucket.Close()
+}
+
+// openStackLocks opens bucket of the state storage the same way DIY backend does.
+// Returns nil for Pulumi Cloud, which detects concurrent updates on its own.
+func (p *pulumi) openStackLocks(ctx context.Context) (*stackLocks, error) {
+	if p.provisionerCfg == nil {
+		return nil, errors.Errorf("provisioner is not initialized")
+	}
+	if p.provisionerCfg.StateStorage.Type == BackendTypePulumiCloud {
+		return nil, nil
+	}
+	stateStorageCfg, ok := p.provisionerCfg.StateStorage.Config.Config.(api.StateStorageConfig)
+	if !ok {
+		return nil, errors.Errorf("state storage config is not of type api.StateStorageConfig for %q", p.provisionerCfg.StateStorage.Type)
+	}
+	storageUrl := stateStorageCfg.StorageUrl()
+	var bucket *blob.Bucket
+	var err error
+	if strings.HasPrefix(storageUrl, gcsblob.Scheme+"://") {
+		mux, muxErr := authhelpers.GoogleCredentialsMux(ctx)
+		if muxErr != nil {
+			return nil, errors.Wrapf(muxErr, "failed to init google credentials")
+		}
+		bucket, err = mux.OpenBucket(ctx, storageUrl)
+	} else {
+		bucket, err = blob.OpenBucket(ctx, storageUrl)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open state storage %q", storageUrl)
+	}
+	return &stackLocks{bucket: bucket}, nil
+}
+
+// lockStack acquires lock of the stack for the operation, waiting for the lock held by another operation
+// up to timeout (Go's duration format, empty means not waiting). Returned function releases the lock.
+func (p *pulumi) lockStack(ctx context.Context, ref backend.StackReference, operation contextActionValue, timeout string) (func(), error) {
+	var deadline time.Time
+	if timeout != "" {
+		waitFor, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid lock timeout %q", timeout)
+		}
+		deadline = time.Now().Add(waitFor)
+	}
+	locks, err := p.openStackLocks(ctx)
+	if err != nil {
+		return nil, err
+	} else if locks == nil {
+		return func() {}, nil
+	}
+
+	key := stackLockKey(ref)
+	lock := api.NewStackLock(ref.FullyQualifiedName().String(), string(operation))
+	for waiting := false; ; waiting = true {
+		holder, err := locks.acquire(ctx, key, lock)
+		if err != nil {
+			locks.close()
+			return nil, err
+		} else if holder == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			locks.close()
+			return nil, errors.Errorf("stack %q is locked by %s\n"+
+				"use --lock-timeout to wait for the lock or run `sc stack unlock` if the operation is no longer running", ref.FullyQualifiedName(), holder)
+		}
+		if !waiting {
+			p.logger.Info(ctx, "%s", color.YellowFmt("Stack %q is locked by %s, waiting...", ref.FullyQualifiedName(), holder))
+		}
+		select {
+		case <-ctx.Done():
+			locks.close()
+			return nil, ctx.Err()
+		case <-time.After(stackLockPollInterval):
+		}
+	}
+	p.logger.Debug(ctx, "acquired lock %q of stack %q", lock.ID, ref.FullyQualifiedName())
+
+	return func() {
+		defer locks.close()
+		// operation context may already be canceled at this point
+		if _, err := locks.release(context.Background(), key, lock.ID); err != nil {
+			p.logger.Warn(ctx, "failed to release lock of stack %q: %v", ref.FullyQualifiedName(), err)
+		}
+	}, nil
+}
+
+// unlockStack removes lock of the stack regardless of its holder and returns removed lock (nil if stack was not locked)
+func (p *pulumi) unlockStack(ctx context.Context, ref backend.StackReference) (*api.StackLock, error) {
+	locks, err := p.openStackLocks(ctx)
+	if err != nil || locks == nil {
+		return nil, err
+	}
+	defer locks.close()
+	return locks.release(ctx, stackLockKey(ref), "")
+}
+
+func (p *pulumi) UnlockStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) (*api.StackLock, error) {
+	if params.Environment != "" && params.StackName != "" {
+		stack = toChildStack(stack, params)
+	}
+	if _, err := p.selectStack(ctx, cfg, stack); err != nil {
+		return nil, err
+	}
+	return p.unlockStack(ctx, p.stackRef)
+}
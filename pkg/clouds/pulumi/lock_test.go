@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"gocloud.dev/blob/fileblob"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func TestStackLocks(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	bucket, err := fileblob.OpenBucket(t.TempDir(), nil)
+	Expect(err).To(BeNil())
+	locks := &stackLocks{bucket: bucket}
+	defer locks.close()
+
+	key := ".sc/locks/organization/project/billing--production.json"
+	first := api.NewStackLock("organization/project/billing--production", "deploy")
+	second := api.NewStackLock("organization/project/billing--production", "destroy")
+
+	holder, err := locks.acquire(ctx, key, first)
+	Expect(err).To(BeNil())
+	Expect(holder).To(BeNil())
+
+	holder, err = locks.acquire(ctx, key, second)
+	Expect(err).To(BeNil())
+	Expect(holder).NotTo(BeNil())
+	Expect(holder.ID).To(Equal(first.ID))
+	Expect(holder.Operation).To(Equal("deploy"))
+
+	_, err = locks.release(ctx, key, second.ID)
+	Expect(err).To(MatchError(ContainSubstring("held by another operation")))
+
+	released, err := locks.release(ctx, key, first.ID)
+	Expect(err).To(BeNil())
+	Expect(released.ID).To(Equal(first.ID))
+
+	current, err := locks.get(ctx, key)
+	Expect(err).To(BeNil())
+	Expect(current).To(BeNil())
+
+	// forced release of the lock held by anyone
+	holder, err = locks.acquire(ctx, key, second)
+	Expect(err).To(BeNil())
+	Expect(holder).To(BeNil())
+	released, err = locks.release(ctx, key, "")
+	Expect(err).To(BeNil())
+	Expect(released.ID).To(Equal(second.ID))
+
+	released, err = locks.release(ctx, key, "")
+	Expect(err).To(BeNil())
+	Expect(released).To(BeNil())
+}
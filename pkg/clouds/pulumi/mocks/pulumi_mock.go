@@ -34,21 +34,33 @@ func (_m *PulumiMock) CancelStack(ctx context.Context, cfg *api.ConfigFile, stac
 }
 
 // DeployStack provides a mock function with given fields: ctx, cfg, stack, params
-func (_m *PulumiMock) DeployStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.DeployParams) error {
+func (_m *PulumiMock) DeployStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.DeployParams) (*api.UpdateResult, error) {
 	ret := _m.Called(ctx, cfg, stack, params)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeployStack")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.DeployParams) error); ok {
+	var r0 *api.UpdateResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.DeployParams) (*api.UpdateResult, error)); ok {
+		return rf(ctx, cfg, stack, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.DeployParams) *api.UpdateResult); ok {
 		r0 = rf(ctx, cfg, stack, params)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*api.UpdateResult)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, *api.ConfigFile, api.Stack, api.DeployParams) error); ok {
+		r1 = rf(ctx, cfg, stack, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // DestroyChildStack provides a mock function with given fields: ctx, cfg, stack, params, preview
@@ -87,6 +99,84 @@ func (_m *PulumiMock) DestroyParentStack(ctx context.Context, cfg *api.ConfigFil
 	return r0
 }
 
+// DriftStack provides a mock function with given fields: ctx, cfg, stack, params
+func (_m *PulumiMock) DriftStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) (*api.DriftReport, error) {
+	ret := _m.Called(ctx, cfg, stack, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DriftStack")
+	}
+
+	var r0 *api.DriftReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) (*api.DriftReport, error)); ok {
+		return rf(ctx, cfg, stack, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) *api.DriftReport); ok {
+		r0 = rf(ctx, cfg, stack, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*api.DriftReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) error); ok {
+		r1 = rf(ctx, cfg, stack, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportStack provides a mock function with given fields: ctx, cfg, stack, params
+func (_m *PulumiMock) ExportStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) (*api.StackState, error) {
+	ret := _m.Called(ctx, cfg, stack, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportStack")
+	}
+
+	var r0 *api.StackState
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) (*api.StackState, error)); ok {
+		return rf(ctx, cfg, stack, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) *api.StackState); ok {
+		r0 = rf(ctx, cfg, stack, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*api.StackState)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) error); ok {
+		r1 = rf(ctx, cfg, stack, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportStack provides a mock function with given fields: ctx, cfg, stack, params, state
+func (_m *PulumiMock) ImportStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams, state api.StackState) error {
+	ret := _m.Called(ctx, cfg, stack, params, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportStack")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams, api.StackState) error); ok {
+		r0 = rf(ctx, cfg, stack, params, state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // OutputsStack provides a mock function with given fields: ctx, cfg, stack, params
 func (_m *PulumiMock) OutputsStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) (*api.OutputsResult, error) {
 	ret := _m.Called(ctx, cfg, stack, params)
@@ -195,6 +285,36 @@ func (_m *PulumiMock) ProvisionStack(ctx context.Context, cfg *api.ConfigFile, s
 	return r0
 }
 
+// RollbackStack provides a mock function with given fields: ctx, cfg, stack, params, revision
+func (_m *PulumiMock) RollbackStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.DeployParams, revision api.DeployRevision) (*api.UpdateResult, error) {
+	ret := _m.Called(ctx, cfg, stack, params, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RollbackStack")
+	}
+
+	var r0 *api.UpdateResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.DeployParams, api.DeployRevision) (*api.UpdateResult, error)); ok {
+		return rf(ctx, cfg, stack, params, revision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.DeployParams, api.DeployRevision) *api.UpdateResult); ok {
+		r0 = rf(ctx, cfg, stack, params, revision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*api.UpdateResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *api.ConfigFile, api.Stack, api.DeployParams, api.DeployRevision) error); ok {
+		r1 = rf(ctx, cfg, stack, params, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetConfigReader provides a mock function with given fields: _a0
 func (_m *PulumiMock) SetConfigReader(_a0 api.ProvisionerFieldConfigReaderFunc) {
 	_m.Called(_a0)
@@ -205,6 +325,66 @@ func (_m *PulumiMock) SetPublicKey(pubKey string) {
 	_m.Called(pubKey)
 }
 
+// StackHistory provides a mock function with given fields: ctx, cfg, stack, params
+func (_m *PulumiMock) StackHistory(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) ([]api.StackHistoryEntry, error) {
+	ret := _m.Called(ctx, cfg, stack, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StackHistory")
+	}
+
+	var r0 []api.StackHistoryEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) ([]api.StackHistoryEntry, error)); ok {
+		return rf(ctx, cfg, stack, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) []api.StackHistoryEntry); ok {
+		r0 = rf(ctx, cfg, stack, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]api.StackHistoryEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) error); ok {
+		r1 = rf(ctx, cfg, stack, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnlockStack provides a mock function with given fields: ctx, cfg, stack, params
+func (_m *PulumiMock) UnlockStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) (*api.StackLock, error) {
+	ret := _m.Called(ctx, cfg, stack, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlockStack")
+	}
+
+	var r0 *api.StackLock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) (*api.StackLock, error)); ok {
+		return rf(ctx, cfg, stack, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) *api.StackLock); ok {
+		r0 = rf(ctx, cfg, stack, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*api.StackLock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *api.ConfigFile, api.Stack, api.StackParams) error); ok {
+		r1 = rf(ctx, cfg, stack, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewPulumiMock creates a new instance of PulumiMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPulumiMock(t interface {
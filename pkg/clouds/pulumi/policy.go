@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"context"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+// checkPolicies evaluates policies against the preview plan and fails if any of them is violated
+func (p *pulumi) checkPolicies(ctx context.Context, policies []api.PolicyDescriptor, stackName, env string, preview *api.PreviewResult, overrides []string) error {
+	violations, err := api.EvaluatePolicies(policies, env, preview.Steps, overrides)
+	if err != nil {
+		return err
+	}
+	for _, override := range overrides {
+		p.logger.Warn(ctx, "%s", color.YellowFmt("Policy %q is overridden for stack %q", override, stackName))
+	}
+	if len(violations) > 0 {
+		for _, violation := range violations {
+			p.logger.Error(ctx, "policy violation: %s", violation)
+		}
+		return api.PolicyViolationsError(stackName, violations)
+	}
+	p.logger.Info(ctx, "%s", color.GreenFmt("Preview of stack %q complies with %d policies", stackName, len(policies)))
+	return nil
+}
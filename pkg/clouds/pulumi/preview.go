@@ -6,6 +6,7 @@ package pulumi
 import (
 	"context"
 	"encoding/json"
+	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
@@ -18,7 +19,11 @@ import (
 	"github.com/simple-container-com/api/pkg/api/logger/color"
 )
 
-func (p *pulumi) previewStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.ProvisionParams) (*api.PreviewResult, error) {
+func (p *pulumi) previewStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.ProvisionParams) (result *api.PreviewResult, err error) {
+	emitOperationStart(ctx, ActionContextPreview, stack.Name)
+	defer func() {
+		emitOperationSummary(ctx, ActionContextPreview, stack.Name, api.OperationEvent{Preview: result}, err)
+	}()
 	s, err := p.validateStateAndGetStack(ctx)
 	if err != nil {
 		return nil, err
@@ -38,19 +43,22 @@ func (p *pulumi) previewStack(ctx context.Context, cfg *api.ConfigFile, stack ap
 	}
 
 	p.logger.Info(ctx, "Preview parent stack %q...", stackSource.Name())
-	previewResult, err := stackSource.Preview(
-		ctx,
-		optpreview.EventStreams(p.watchEvents(WithContextAction(ctx, ActionContextPreview))),
+	result, err = p.previewWithSteps(
+		ctx, stackSource, stackSource.Name(),
 		optpreview.Diff(), // Enable detailed diff output for better visibility into changes
 	)
 	if err != nil {
 		return nil, err
 	}
-	p.logger.Info(ctx, "%s", color.GreenFmt("Preview parent summary: %q", p.toPreviewResult(stackSource.Name(), previewResult)))
-	return p.toPreviewResult(stackSource.Name(), previewResult), nil
+	p.logger.Info(ctx, "%s", color.GreenFmt("Preview parent summary: %q", result))
+	return result, nil
 }
 
-func (p *pulumi) previewChildStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.DeployParams) (*api.PreviewResult, error) {
+func (p *pulumi) previewChildStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.DeployParams) (result *api.PreviewResult, err error) {
+	emitOperationStart(ctx, ActionContextPreview, params.StackName)
+	defer func() {
+		emitOperationSummary(ctx, ActionContextPreview, params.StackName, api.OperationEvent{Preview: result}, err)
+	}()
 	s, err := p.validateStateAndGetStack(ctx)
 	if err != nil {
 		return nil, err
@@ -60,22 +68,21 @@ func (p *pulumi) previewChildStack(ctx context.Context, cfg *api.ConfigFile, sta
 	parentStack := stack.Client.Stacks[params.Environment].ParentStack
 	fullStackName := s.Ref().FullyQualifiedName().String()
 
-	program := p.deployStackProgram(stack, params.StackParams, parentStack, fullStackName)
+	program := p.deployStackProgram(stack, params.StackParams, parentStack, fullStackName, nil)
 	stackSource, err := p.prepareStackForOperations(ctx, s.Ref(), cfg, program)
 	if err != nil {
 		return nil, err
 	}
 	p.logger.Info(ctx, "%s", color.GreenFmt("Preview child stack %q...", stackSource.Name()))
-	previewResult, err := stackSource.Preview(
-		ctx,
-		optpreview.EventStreams(p.watchEvents(WithContextAction(ctx, ActionContextPreview))),
+	result, err = p.previewWithSteps(
+		ctx, stackSource, stackSource.Name(),
 		optpreview.Diff(), // Enable detailed diff output for better visibility into changes
 	)
 	if err != nil {
 		return nil, err
 	}
-	p.logger.Info(ctx, "%s", color.GreenFmt("Preview child summary: %q", p.toPreviewResult(stackSource.Name(), previewResult)))
-	return p.toPreviewResult(stackSource.Name(), previewResult), nil
+	p.logger.Info(ctx, "%s", color.GreenFmt("Preview child summary: %q", result))
+	return result, nil
 }
 
 func (p *pulumi) OutputsStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) (*api.OutputsResult, error) {
@@ -86,6 +93,9 @@ func (p *pulumi) OutputsStack(ctx context.Context, cfg *api.ConfigFile, stack ap
 	if err != nil {
 		return nil, err
 	}
+	if s == nil {
+		return nil, errors.Errorf("stack %q does not exist", stack.Name)
+	}
 
 	stackSource, err := p.prepareStackForOperations(ctx, s.Ref(), cfg, nil)
 	if err != nil {
@@ -100,17 +110,19 @@ func (p *pulumi) OutputsStack(ctx context.Context, cfg *api.ConfigFile, stack ap
 }
 
 func (p *pulumi) toOutputsResult(stackName string, result auto.OutputMap) *api.OutputsResult {
+	secrets := lo.Keys(lo.PickBy(result, func(key string, value auto.OutputValue) bool {
+		return value.Secret
+	}))
+	sort.Strings(secrets)
 	return &api.OutputsResult{
 		StackName: stackName,
+		Secrets:   secrets,
 		Outputs: lo.MapValues(result, func(value auto.OutputValue, key string) any {
 			var res string
 			if s, ok := value.Value.(string); ok {
 				res = s
-			} else if m, ok := value.Value.(map[string]any); ok {
-				j, _ := json.Marshal(m)
-				res = string(j)
-			} else if value.Secret {
-				j, _ := json.Marshal(value)
+			} else if value.Value != nil {
+				j, _ := json.Marshal(value.Value)
 				res = string(j)
 			}
 			return res
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+
+	"github.com/simple-container-com/api/pkg/api"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+)
+
+// previewWithSteps runs preview of the stack and fills result with the change planned for each resource
+func (p *pulumi) previewWithSteps(ctx context.Context, stackSource auto.Stack, stackName string, opts ...optpreview.Option) (*api.PreviewResult, error) {
+	var lock sync.Mutex
+	var steps []api.PreviewStep
+	collector := collectEvents(func(evt events.EngineEvent) {
+		if evt.ResourcePreEvent == nil {
+			return
+		}
+		if step, ok := toPreviewStep(evt.ResourcePreEvent.Metadata); ok {
+			lock.Lock()
+			defer lock.Unlock()
+			steps = append(steps, step)
+		}
+	})
+	opts = append(opts, optpreview.EventStreams(
+		p.watchEvents(WithContextAction(ctx, ActionContextPreview)),
+		collector.events,
+	))
+	previewResult, err := stackSource.Preview(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !collector.wait(collectEventsTimeout) {
+		p.logger.Warn(ctx, "timed out waiting for preview events of stack %q, planned steps may be incomplete", stackName)
+	}
+	res := p.toPreviewResult(stackName, previewResult)
+	lock.Lock()
+	defer lock.Unlock()
+	res.Steps = steps
+	return res, nil
+}
+
+// toPreviewStep converts planned step of the engine into api.PreviewStep, skipping resources without changes
+func toPreviewStep(meta apitype.StepEventMetadata) (api.PreviewStep, bool) {
+	switch meta.Op {
+	case apitype.OpSame, apitype.OpRead, apitype.OpRefresh, apitype.OpCreateReplacement, apitype.OpDeleteReplaced, apitype.OpDiscardReplaced:
+		// replacement is reported as a single "replace" step
+		return api.PreviewStep{}, false
+	}
+	step := api.PreviewStep{
+		URN:         meta.URN,
+		Type:        meta.Type,
+		Op:          string(meta.Op),
+		Environment: stepEnvironment(meta),
+	}
+	if len(meta.DetailedDiff) > 0 {
+		for path, diff := range meta.DetailedDiff {
+			step.Properties = append(step.Properties, path)
+			if strings.HasSuffix(string(diff.Kind), "-replace") {
+				step.ReplaceKeys = append(step.ReplaceKeys, path)
+			}
+		}
+	} else {
+		step.Properties = append(step.Properties, meta.Diffs...)
+	}
+	if meta.Op == apitype.OpReplace {
+		step.ReplaceKeys = lo.Uniq(append(step.ReplaceKeys, meta.Keys...))
+		step.Properties = lo.Uniq(append(step.Properties, step.ReplaceKeys...))
+		step.ForcedReplace = len(step.ReplaceKeys) > 0
+	}
+	sort.Strings(step.Properties)
+	sort.Strings(step.ReplaceKeys)
+	return step, true
+}
+
+// stepEnvironment detects environment of the resource from its tags (AWS) or labels (GCP)
+func stepEnvironment(meta apitype.StepEventMetadata) string {
+	for _, state := range []*apitype.StepEventStateMetadata{meta.New, meta.Old} {
+		if state == nil {
+			continue
+		}
+		for _, props := range []map[string]any{state.Inputs, state.Outputs} {
+			if tags, ok := props["tags"].(map[string]any); ok {
+				if env, ok := tags[pApi.EnvironmentTag].(string); ok && env != "" {
+					return env
+				}
+			}
+			if labels, ok := props["labels"].(map[string]any); ok {
+				if env, ok := labels[pApi.GCPEnvironmentTag].(string); ok && env != "" {
+					return env
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+
+	"github.com/simple-container-com/api/pkg/api"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+)
+
+func TestToPreviewStep(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		meta     apitype.StepEventMetadata
+		wantSkip bool
+		want     api.PreviewStep
+	}{
+		{
+			name:     "unchanged resource is skipped",
+			meta:     apitype.StepEventMetadata{Op: apitype.OpSame, URN: "urn:bucket"},
+			wantSkip: true,
+		},
+		{
+			name:     "intermediate replacement step is skipped",
+			meta:     apitype.StepEventMetadata{Op: apitype.OpCreateReplacement, URN: "urn:db"},
+			wantSkip: true,
+		},
+		{
+			name: "update uses detailed diff",
+			meta: apitype.StepEventMetadata{
+				Op:   apitype.OpUpdate,
+				URN:  "urn:svc",
+				Type: "aws:ecs/service:Service",
+				DetailedDiff: map[string]apitype.PropertyDiff{
+					"taskDefinition": {Kind: apitype.DiffUpdate},
+					"desiredCount":   {Kind: apitype.DiffUpdate},
+				},
+				Diffs: []string{"ignored"},
+			},
+			want: api.PreviewStep{URN: "urn:svc", Type: "aws:ecs/service:Service", Op: api.PreviewOpUpdate, Properties: []string{"desiredCount", "taskDefinition"}},
+		},
+		{
+			name: "update falls back to diffs",
+			meta: apitype.StepEventMetadata{Op: apitype.OpUpdate, URN: "urn:svc", Diffs: []string{"tags", "image"}},
+			want: api.PreviewStep{URN: "urn:svc", Op: api.PreviewOpUpdate, Properties: []string{"image", "tags"}},
+		},
+		{
+			name: "replace reports keys forcing replacement",
+			meta: apitype.StepEventMetadata{
+				Op:   apitype.OpReplace,
+				URN:  "urn:db",
+				Type: "aws:rds/instance:Instance",
+				DetailedDiff: map[string]apitype.PropertyDiff{
+					"engineVersion":    {Kind: apitype.DiffUpdate},
+					"storageEncrypted": {Kind: apitype.DiffUpdateReplace},
+				},
+				Keys: []string{"storageEncrypted", "dbName"},
+			},
+			want: api.PreviewStep{
+				URN:           "urn:db",
+				Type:          "aws:rds/instance:Instance",
+				Op:            api.PreviewOpReplace,
+				Properties:    []string{"dbName", "engineVersion", "storageEncrypted"},
+				ReplaceKeys:   []string{"dbName", "storageEncrypted"},
+				ForcedReplace: true,
+			},
+		},
+		{
+			name: "delete",
+			meta: apitype.StepEventMetadata{Op: apitype.OpDelete, URN: "urn:bucket", Type: "aws:s3/bucket:Bucket"},
+			want: api.PreviewStep{URN: "urn:bucket", Type: "aws:s3/bucket:Bucket", Op: api.PreviewOpDelete},
+		},
+		{
+			name: "delete keeps environment of the old state",
+			meta: apitype.StepEventMetadata{
+				Op:  apitype.OpDelete,
+				URN: "urn:bucket",
+				Old: &apitype.StepEventStateMetadata{Outputs: map[string]any{"tags": map[string]any{pApi.EnvironmentTag: "staging"}}},
+			},
+			want: api.PreviewStep{URN: "urn:bucket", Op: api.PreviewOpDelete, Environment: "staging"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+			step, ok := toPreviewStep(tc.meta)
+			Expect(ok).To(Equal(!tc.wantSkip))
+			if !tc.wantSkip {
+				Expect(step).To(Equal(tc.want))
+			}
+		})
+	}
+}
+
+func TestStepEnvironment(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		meta apitype.StepEventMetadata
+		want string
+	}{
+		{
+			name: "no state",
+			meta: apitype.StepEventMetadata{},
+			want: "",
+		},
+		{
+			name: "aws tags of new inputs",
+			meta: apitype.StepEventMetadata{
+				New: &apitype.StepEventStateMetadata{Inputs: map[string]any{"tags": map[string]any{pApi.EnvironmentTag: "production"}}},
+			},
+			want: "production",
+		},
+		{
+			name: "gcp labels of old outputs",
+			meta: apitype.StepEventMetadata{
+				Old: &apitype.StepEventStateMetadata{Outputs: map[string]any{"labels": map[string]any{pApi.GCPEnvironmentTag: "staging"}}},
+			},
+			want: "staging",
+		},
+		{
+			name: "new state takes precedence over old",
+			meta: apitype.StepEventMetadata{
+				New: &apitype.StepEventStateMetadata{Inputs: map[string]any{"tags": map[string]any{pApi.EnvironmentTag: "production"}}},
+				Old: &apitype.StepEventStateMetadata{Inputs: map[string]any{"tags": map[string]any{pApi.EnvironmentTag: "staging"}}},
+			},
+			want: "production",
+		},
+		{
+			name: "unrelated tags",
+			meta: apitype.StepEventMetadata{
+				New: &apitype.StepEventStateMetadata{Inputs: map[string]any{"tags": map[string]any{"team": "billing"}}},
+			},
+			want: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+			Expect(stepEnvironment(tc.meta)).To(Equal(tc.want))
+		})
+	}
+}
@@ -28,13 +28,23 @@ import (
 	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
 )
 
-func (p *pulumi) provisionStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.ProvisionParams) error {
+func (p *pulumi) provisionStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.ProvisionParams) (err error) {
+	var updateResult *api.UpdateResult
+	emitOperationStart(ctx, ActionContextProvision, stack.Name)
+	defer func() {
+		emitOperationSummary(ctx, ActionContextProvision, stack.Name, api.OperationEvent{Update: updateResult}, err)
+	}()
 	s, err := p.validateStateAndGetStack(ctx)
 	if err != nil {
 		return err
 	}
 
 	p.logger.Info(ctx, "Found stack %q", s.Ref().FullyQualifiedName())
+	release, err := p.lockStack(ctx, s.Ref(), ActionContextProvision, params.Timeouts.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer release()
 
 	stackSource, err := p.prepareStackForOperations(ctx, s.Ref(), cfg, p.provisionProgram(stack, cfg))
 	if err != nil {
@@ -56,12 +66,12 @@ func (p *pulumi) provisionStack(ctx context.Context, cfg *api.ConfigFile, stack
 		}
 		p.logger.Info(ctx, "Refresh summary: \n%s", p.toRefreshResult(refreshResult))
 	}
-	if !params.SkipPreview {
+	// preview cannot be skipped when policies have to be evaluated against the plan
+	checkPolicies := len(stack.Server.Policies) > 0
+	if !params.SkipPreview || checkPolicies {
 		p.logger.Info(ctx, "%s", color.GreenFmt("Previewing stack %q...", s.Ref().FullyQualifiedName()))
 
-		previewOpts := []optpreview.Option{
-			optpreview.EventStreams(p.watchEvents(WithContextAction(ctx, ActionContextPreview))),
-		}
+		var previewOpts []optpreview.Option
 
 		// Add detailed diff option if requested (default to true for better visibility)
 		if params.DetailedDiff {
@@ -69,11 +79,18 @@ func (p *pulumi) provisionStack(ctx context.Context, cfg *api.ConfigFile, stack
 			p.logger.Info(ctx, "🔍 Diff enabled - showing granular changes for nested properties")
 		}
 
-		previewResult, err := stackSource.Preview(ctx, previewOpts...)
+		previewResult, err := p.previewWithSteps(ctx, stackSource, stackSource.Name(), previewOpts...)
 		if err != nil {
 			return err
 		}
-		p.logger.Info(ctx, "%s", color.GreenFmt("Preview summary: \n%s", p.toPreviewResult(stackSource.Name(), previewResult)))
+		p.logger.Info(ctx, "%s", color.GreenFmt("Preview summary: \n%s", previewResult))
+		emitOperationSummary(ctx, ActionContextPreview, stack.Name, api.OperationEvent{Preview: previewResult}, nil)
+		if checkPolicies {
+			// parent stack contains resources of all environments, which are detected from resource tags
+			if err := p.checkPolicies(ctx, stack.Server.Policies, stackSource.Name(), "", previewResult, params.OverridePolicies); err != nil {
+				return err
+			}
+		}
 	}
 	upOpts := []optup.Option{
 		optup.EventStreams(p.watchEvents(WithContextAction(ctx, ActionContextProvision))),
@@ -89,7 +106,8 @@ func (p *pulumi) provisionStack(ctx context.Context, cfg *api.ConfigFile, stack
 	if err != nil {
 		return err
 	}
-	p.logger.Info(ctx, "%s", color.GreenFmt("Update summary: \n%s", p.toUpdateResult(stackSource.Name(), updateRes)))
+	updateResult = p.toUpdateResult(stackSource.Name(), updateRes)
+	p.logger.Info(ctx, "%s", color.GreenFmt("Update summary: \n%s", updateResult))
 	return nil
 }
 
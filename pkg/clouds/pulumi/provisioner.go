@@ -106,7 +106,7 @@ func (p *pulumi) DestroyChildStack(ctx context.Context, cfg *api.ConfigFile, par
 	if err != nil {
 		return errors.Wrapf(err, "failed to get child stack %q", childStack.Name)
 	}
-	program := p.deployStackProgram(childStack, params.StackParams, parentStack.Name, s.Ref().FullyQualifiedName().String())
+	program := p.deployStackProgram(childStack, params.StackParams, parentStack.Name, s.Ref().FullyQualifiedName().String(), nil)
 	return p.destroyStack(ctx, cfg, s, params, program, preview, func(stackSource auto.Stack) {
 		for _, hook := range pApi.PreDestroyHookFuncs {
 			hook(ctx, childStack, params, stackSource, p.logger)
@@ -146,10 +146,10 @@ func toChildStack(parentStack api.Stack, params api.StackParams) api.Stack {
 	return parentStack.ChildStack(pApi.StackNameInEnv(params.StackName, params.Environment))
 }
 
-func (p *pulumi) DeployStack(ctx context.Context, cfg *api.ConfigFile, parentStack api.Stack, params api.DeployParams) error {
+func (p *pulumi) DeployStack(ctx context.Context, cfg *api.ConfigFile, parentStack api.Stack, params api.DeployParams) (*api.UpdateResult, error) {
 	childStack, err := p.initChildStackForDeploy(ctx, cfg, parentStack, params)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return p.deployStack(ctx, cfg, *childStack, params)
+	return p.deployStack(ctx, cfg, *childStack, params, nil)
 }
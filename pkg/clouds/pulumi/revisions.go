@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto"
+	sdk "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+const updateResultSucceeded = "succeeded"
+
+func (p *pulumi) StackHistory(ctx context.Context, cfg *api.ConfigFile, parentStack api.Stack, params api.StackParams) ([]api.StackHistoryEntry, error) {
+	stack := toChildStack(parentStack, params)
+	s, err := p.selectStack(ctx, cfg, stack)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, errors.Errorf("stack %q does not exist", stack.Name)
+	}
+	stackSource, err := p.prepareStackForOperations(ctx, s.Ref(), cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := stackSource.Outputs(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get outputs")
+	}
+	revisions, err := api.DeployRevisionsFromOutputs(p.toOutputsResult(stackSource.Name(), outputs).Outputs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read revisions of stack %q", stackSource.Name())
+	}
+	byUpdate := lo.KeyBy(revisions, func(r api.DeployRevision) int {
+		return r.UpdateVersion
+	})
+
+	history, err := stackSource.History(ctx, 0, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get history of stack %q", stackSource.Name())
+	}
+	return lo.Map(history, func(update auto.UpdateSummary, _ int) api.StackHistoryEntry {
+		entry := api.StackHistoryEntry{
+			UpdateVersion: update.Version,
+			Kind:          update.Kind,
+			Result:        update.Result,
+			StartTime:     update.StartTime,
+		}
+		if revision, found := byUpdate[update.Version]; found && update.Result == updateResultSucceeded {
+			entry.Revision = &revision
+		}
+		return entry
+	}), nil
+}
+
+func (p *pulumi) RollbackStack(ctx context.Context, cfg *api.ConfigFile, parentStack api.Stack, params api.DeployParams, revision api.DeployRevision) (*api.UpdateResult, error) {
+	childStack, err := p.initChildStackForDeploy(ctx, cfg, parentStack, params)
+	if err != nil {
+		return nil, err
+	}
+	p.logger.Info(ctx, "%s", color.YellowFmt("Rolling back stack %q to update %d (version %q)...", childStack.Name, revision.UpdateVersion, revision.Version))
+	params.Version = revision.Version
+	// images must never be rebuilt on rollback, so only captured ones are allowed
+	params.PinnedImages = lo.Assign(revision.Images)
+	return p.deployStack(ctx, cfg, *childStack, params, &revision)
+}
+
+// loadDeployRevisions reads revisions captured by previous deploys to carry them over to the upcoming update
+func (p *pulumi) loadDeployRevisions(ctx context.Context, stackSource auto.Stack, revisions *deployRevisions) error {
+	outputs, err := stackSource.Outputs(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get outputs")
+	}
+	// deploy must not proceed with unreadable revisions, otherwise it would overwrite the whole rollback history
+	previous, err := api.DeployRevisionsFromOutputs(p.toOutputsResult(stackSource.Name(), outputs).Outputs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read previous revisions of stack %q", stackSource.Name())
+	}
+	revisions.previous = previous
+
+	history, err := stackSource.History(ctx, 1, 1)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get history of stack %q", stackSource.Name())
+	}
+	revisions.nextUpdate = 1
+	if len(history) > 0 {
+		revisions.nextUpdate = history[0].Version + 1
+	}
+	return nil
+}
+
+// exportDeployRevisions exports snapshot of the resolved stack descriptor along with previous revisions,
+// so that stack can be rolled back to any of them later
+func (p *pulumi) exportDeployRevisions(ctx *sdk.Context, params api.StackParams, clientType string, desc api.StackDescriptor, revisions *deployRevisions) error {
+	revision, err := api.NewDeployRevision(revisions.nextUpdate, params, clientType, desc)
+	if err != nil {
+		return err
+	}
+	revisionJson, err := json.Marshal(revision)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal revision")
+	}
+	previousJson, err := json.Marshal(lo.Slice(revisions.previous, 0, api.MaxDeployRevisions))
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal previous revisions")
+	}
+	// descriptor contains resolved secrets
+	ctx.Export(api.DeploySnapshotOutput, sdk.ToSecret(sdk.String(string(revisionJson))))
+	ctx.Export(api.DeployRevisionsOutput, sdk.ToSecret(sdk.String(string(previousJson))))
+	return nil
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package pulumi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+
+	"github.com/simple-container-com/api/pkg/api"
+	pApi "github.com/simple-container-com/api/pkg/clouds/pulumi/api"
+)
+
+// deploymentSecretsProvidersKey is the key of the deployment describing secrets manager its secrets are encrypted with
+const deploymentSecretsProvidersKey = "secrets_providers"
+
+func (p *pulumi) ExportStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams) (*api.StackState, error) {
+	if params.Environment != "" && params.StackName != "" {
+		stack = toChildStack(stack, params)
+	}
+	s, err := p.selectStack(ctx, cfg, stack)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, nil
+	}
+	stackSource, err := p.prepareStackForStateOperations(ctx, s.Ref(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	// secrets are exported decrypted, so that they can be imported into stack with another secrets provider
+	deployment, err := stackSource.Export(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to export stack %q", stackSource.Name())
+	}
+	return &api.StackState{
+		Stack:      stackSource.Name(),
+		Version:    deployment.Version,
+		Deployment: deployment.Deployment,
+	}, nil
+}
+
+func (p *pulumi) ImportStack(ctx context.Context, cfg *api.ConfigFile, stack api.Stack, params api.StackParams, state api.StackState) error {
+	if params.Environment != "" && params.StackName != "" {
+		stack = toChildStack(stack, params)
+	}
+	if err := p.createStackIfNotExists(ctx, cfg, stack); err != nil {
+		return err
+	}
+	stackSource, err := p.prepareStackForStateOperations(ctx, p.stackRef, cfg)
+	if err != nil {
+		return err
+	}
+	if p.secretsProviderUrl != "" {
+		if err := stackSource.ChangeSecretsProvider(ctx, p.secretsProviderUrl, nil); err != nil {
+			return errors.Wrapf(err, "failed to set secrets provider of stack %q", stackSource.Name())
+		}
+	}
+	deployment, err := withoutSecretsProviders(state.Deployment)
+	if err != nil {
+		return errors.Wrapf(err, "failed to prepare deployment of stack %q", state.Stack)
+	}
+	if err := stackSource.Import(ctx, apitype.UntypedDeployment{
+		Version:    state.Version,
+		Deployment: deployment,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to import stack %q", stackSource.Name())
+	}
+	p.logger.Info(ctx, "imported %q into stack %q", state.Stack, stackSource.Name())
+	return nil
+}
+
+// prepareStackForStateOperations selects existing stack making sure workspace uses passphrase of this state storage
+// even if passphrase of another state storage was set in the environment by the preceding operation
+func (p *pulumi) prepareStackForStateOperations(ctx context.Context, ref backend.StackReference, cfg *api.ConfigFile) (auto.Stack, error) {
+	stackSource, err := p.prepareStackForOperations(ctx, ref, cfg, nil)
+	if err != nil {
+		return stackSource, err
+	}
+	if p.secretsProviderPassphrase != "" && stackSource.Workspace() != nil {
+		if err := stackSource.Workspace().SetEnvVars(map[string]string{
+			pApi.ConfigPassphraseEnvVar: p.secretsProviderPassphrase,
+		}); err != nil {
+			return stackSource, errors.Wrapf(err, "failed to set %s for stack %q", pApi.ConfigPassphraseEnvVar, stackSource.Name())
+		}
+	}
+	return stackSource, nil
+}
+
+// withoutSecretsProviders removes secrets manager of the source stack from the exported deployment,
+// so that its decrypted secrets get encrypted by the secrets manager of the stack it is imported into
+func withoutSecretsProviders(deployment json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(deployment, &fields); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal deployment")
+	}
+	if _, found := fields[deploymentSecretsProvidersKey]; !found {
+		return deployment, nil
+	}
+	delete(fields, deploymentSecretsProvidersKey)
+	return json.Marshal(fields)
+}
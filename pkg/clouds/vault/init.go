@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package vault
+
+import (
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+const ProviderType = "vault"
+
+func init() {
+	api.RegisterProviderConfig(api.ConfigRegisterMap{
+		SecretsTypeVault: ReadSecretsConfig,
+	})
+
+	api.RegisterSecretSources(api.SecretSourcesRegisterMap{
+		SecretsTypeVault: NewSecretSource,
+	})
+}
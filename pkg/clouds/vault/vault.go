@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+const SecretsTypeVault = "vault"
+
+// SecretsConfig configures access to HashiCorp Vault, address and token default to VAULT_ADDR and VAULT_TOKEN
+type SecretsConfig struct {
+	Address   string `json:"address,omitempty" yaml:"address,omitempty"`
+	Token     string `json:"token,omitempty" yaml:"token,omitempty"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+func ReadSecretsConfig(config *api.Config) (api.Config, error) {
+	return api.ConvertConfig(config, &SecretsConfig{})
+}
+
+type secretSource struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// NewSecretSource returns reader of Vault secrets, paths are API paths without /v1 prefix, e.g. kv/data/payments
+func NewSecretSource(config api.Config) (api.SecretSource, error) {
+	cfg, ok := config.Config.(*SecretsConfig)
+	if !ok {
+		return nil, errors.Errorf("failed to convert vault config %T", config.Config)
+	}
+	res := &secretSource{
+		address:   cfg.Address,
+		token:     cfg.Token,
+		namespace: cfg.Namespace,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+	if res.address == "" {
+		res.address = os.Getenv("VAULT_ADDR")
+	}
+	if res.token == "" {
+		res.token = os.Getenv("VAULT_TOKEN")
+	}
+	if res.address == "" {
+		return nil, errors.New("vault address is not configured, set address or VAULT_ADDR")
+	}
+	return res, nil
+}
+
+type secretResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []string       `json:"errors"`
+}
+
+func (s *secretSource) ReadSecret(ctx context.Context, path string) (string, error) {
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(s.address, "/"), strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create request to vault")
+	}
+	if s.token != "" {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read secret %q from vault", path)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read response of vault")
+	}
+	var secret secretResponse
+	_ = json.Unmarshal(body, &secret)
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errors.Errorf("secret %q not found in vault", path)
+	} else if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("vault responded with status %d for secret %q: %s", resp.StatusCode, path, strings.Join(secret.Errors, ", "))
+	}
+	if secret.Data == nil {
+		return "", errors.Errorf("secret %q in vault has no data", path)
+	}
+
+	data := secret.Data
+	// KV v2 engine wraps secret into data.data next to data.metadata
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = inner
+		}
+	}
+	res, err := json.Marshal(data)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal secret %q", path)
+	}
+	return string(res), nil
+}
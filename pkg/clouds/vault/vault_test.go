@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func TestSecretSource(t *testing.T) {
+	RegisterTestingT(t)
+
+	// mimics responses of a dev server of vault
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/payments":
+			Expect(r.Header.Get("X-Vault-Namespace")).To(Equal("team"))
+			_, _ = w.Write([]byte(`{"data":{"data":{"stripe_key":"sk_test"},"metadata":{"version":3}}}`))
+		case "/v1/kv1/payments":
+			_, _ = w.Write([]byte(`{"data":{"stripe_key":"sk_v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	newSource := func(token string) api.SecretSource {
+		config, err := ReadSecretsConfig(&api.Config{Config: map[string]any{
+			"address": server.URL, "token": token, "namespace": "team",
+		}})
+		Expect(err).To(BeNil())
+		source, err := api.NewSecretSource(SecretsTypeVault, config)
+		Expect(err).To(BeNil())
+		return source
+	}
+
+	t.Run("kv v2", func(t *testing.T) {
+		RegisterTestingT(t)
+		secret, err := newSource("root").ReadSecret(context.Background(), "secret/data/payments")
+		Expect(err).To(BeNil())
+		Expect(secret).To(MatchJSON(`{"stripe_key":"sk_test"}`))
+
+		value, err := api.SecretRef{Source: "vault", Path: "secret/data/payments", Key: "stripe_key"}.Value(secret)
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal("sk_test"))
+	})
+
+	t.Run("kv v1", func(t *testing.T) {
+		RegisterTestingT(t)
+		secret, err := newSource("root").ReadSecret(context.Background(), "kv1/payments")
+		Expect(err).To(BeNil())
+		Expect(secret).To(MatchJSON(`{"stripe_key":"sk_v1"}`))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := newSource("root").ReadSecret(context.Background(), "secret/data/missing")
+		Expect(err).To(MatchError(`secret "secret/data/missing" not found in vault`))
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := newSource("wrong").ReadSecret(context.Background(), "secret/data/payments")
+		Expect(err).To(MatchError(ContainSubstring("status 403")))
+		Expect(err).To(MatchError(ContainSubstring("permission denied")))
+	})
+}
@@ -13,6 +13,7 @@ import (
 type cancelCmd struct {
 	Root   *root_cmd.RootCmd
 	Params api.DeployParams
+	Output string
 }
 
 func NewCancelCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
@@ -23,14 +24,19 @@ func NewCancelCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 		Use:   "cancel",
 		Short: "Cancels deployment for a stack",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := pCmd.Root.OutputContext(cmd.Context(), pCmd.Output)
+			if err != nil {
+				return err
+			}
 			if pCmd.Params.Parent {
-				return pCmd.Root.Provisioner.CancelParent(cmd.Context(), pCmd.Params.StackParams)
+				return pCmd.Root.Provisioner.CancelParent(ctx, pCmd.Params.StackParams)
 			}
-			return pCmd.Root.Provisioner.Cancel(cmd.Context(), pCmd.Params.StackParams)
+			return pCmd.Root.Provisioner.Cancel(ctx, pCmd.Params.StackParams)
 		},
 	}
 
 	cmd.Flags().BoolVar(&pCmd.Params.Parent, "parent", pCmd.Params.Parent, "Cancel parent stack")
+	root_cmd.RegisterOutputFlag(cmd, &pCmd.Output)
 
 	root_cmd.RegisterStackFlags(cmd, &pCmd.Params.StackParams, false)
 	return cmd
@@ -6,7 +6,13 @@ package cmd_deploy
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/simple-container-com/api/pkg/api"
@@ -15,9 +21,13 @@ import (
 )
 
 type deployCmd struct {
-	Root    *root_cmd.RootCmd
-	Params  api.DeployParams
-	Preview bool
+	Root        *root_cmd.RootCmd
+	Params      api.DeployParams
+	Preview     bool
+	PreviewJson string
+	All         bool
+	Concurrency int
+	Output      string
 }
 
 func NewDeployCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
@@ -28,25 +38,42 @@ func NewDeployCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 				DetailedDiff: true, // Enable detailed diff by default for better visibility
 			},
 		},
+		Concurrency: 1,
 	}
 	cmd := &cobra.Command{
 		Use:   "deploy",
 		Short: "Deploys stacks defined in stacks directory",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := pCmd.Root.OutputContext(cmd.Context(), pCmd.Output)
+			if err != nil {
+				return err
+			}
+			if pCmd.All {
+				return pCmd.deployAll(ctx)
+			}
+			if pCmd.Params.StackName == "" {
+				return errors.Errorf("either --stack or --all must be specified")
+			}
 			if pCmd.Preview {
-				res, err := pCmd.Root.Provisioner.Preview(cmd.Context(), pCmd.Params)
+				res, err := pCmd.Root.Provisioner.Preview(ctx, pCmd.Params)
 				if err != nil {
 					return err
 				}
-				fmt.Println("Summary:")
-				cmd_provision.PrintPreview(res)
+				if err := cmd_provision.WritePreviewJson(pCmd.PreviewJson, res); err != nil {
+					return err
+				}
+				if pCmd.Output != root_cmd.OutputFormatJson {
+					fmt.Println("Summary:")
+					cmd_provision.PrintPreview(res)
+				}
 				return nil
 			}
-			err := pCmd.Root.Provisioner.Deploy(cmd.Context(), pCmd.Params)
+			err = pCmd.Root.Provisioner.Deploy(ctx, pCmd.Params)
 			if err != nil && !rootCmd.IsCanceled.Load() {
 				return err
 			} else if rootCmd.IsCanceled.Load() {
-				err = pCmd.Root.Provisioner.Cancel(context.Background(), pCmd.Params.StackParams)
+				cancelCtx, _ := pCmd.Root.OutputContext(context.Background(), pCmd.Output)
+				err = pCmd.Root.Provisioner.Cancel(cancelCtx, pCmd.Params.StackParams)
 			} else {
 				return nil
 			}
@@ -56,5 +83,58 @@ func NewDeployCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 
 	root_cmd.RegisterDeployFlags(cmd, &pCmd.Params)
 	cmd.Flags().BoolVarP(&pCmd.Preview, "preview", "P", pCmd.Preview, "Preview instead of provision (dry-run)")
+	cmd_provision.RegisterPreviewJsonFlag(cmd, &pCmd.PreviewJson)
+	cmd.Flags().BoolVar(&pCmd.All, "all", pCmd.All, "Deploy all client stacks configured for the environment in dependency order")
+	cmd.Flags().IntVar(&pCmd.Concurrency, "concurrency", pCmd.Concurrency, "Max number of stacks deployed in parallel (only with --all)")
+	root_cmd.RegisterOutputFlag(cmd, &pCmd.Output)
+	cmd.MarkFlagsMutuallyExclusive("all", "stack")
+	cmd.MarkFlagsMutuallyExclusive("all", "preview")
 	return cmd
 }
+
+func (c *deployCmd) deployAll(ctx context.Context) error {
+	results, err := c.Root.Provisioner.DeployAll(ctx, api.DeployAllParams{
+		DeployParams: c.Params,
+		Concurrency:  c.Concurrency,
+	})
+	if len(results) > 0 && c.Output != root_cmd.OutputFormatJson {
+		fmt.Println("Summary:")
+		PrintDeployResults(results)
+	}
+	if err != nil && c.Root.IsCanceled.Load() {
+		for _, res := range results {
+			if res.Status != api.StackDeployStatusFailed {
+				continue
+			}
+			params := c.Params.StackParams
+			params.StackName = res.StackName
+			cancelCtx, _ := c.Root.OutputContext(context.Background(), c.Output)
+			if cErr := c.Root.Provisioner.Cancel(cancelCtx, params); cErr != nil {
+				fmt.Fprintf(os.Stderr, "failed to cancel stack %q: %v\n", res.StackName, cErr)
+			}
+		}
+	}
+	return err
+}
+
+// PrintDeployResults prints per-stack outcome of multi-stack deploy as a table
+func PrintDeployResults(results []api.StackDeployResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STACK\tSTATUS\tDURATION\tCHANGES\tDETAILS")
+	for _, res := range results {
+		var changes []string
+		if res.Update != nil {
+			for op, cnt := range res.Update.Operations {
+				changes = append(changes, fmt.Sprintf("%s=%d", op, cnt))
+			}
+			sort.Strings(changes)
+		}
+		details := res.Error
+		if len(res.BlockedBy) > 0 {
+			details = fmt.Sprintf("blocked by: %s", strings.Join(res.BlockedBy, ", "))
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", res.StackName, res.Status,
+			res.Duration.Round(time.Second), strings.Join(changes, " "), details)
+	}
+	_ = w.Flush()
+}
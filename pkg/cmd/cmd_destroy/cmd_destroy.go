@@ -19,6 +19,7 @@ import (
 type destroyCmd struct {
 	Root        *root_cmd.RootCmd
 	ParentStack bool
+	Output      string
 	Params      api.DestroyParams
 }
 
@@ -33,6 +34,14 @@ func NewDestroyCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 		Use:   "destroy",
 		Short: "Destroys stacks defined in stacks directory",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := pCmd.Root.OutputContext(cmd.Context(), pCmd.Output)
+			if err != nil {
+				return err
+			}
+			if pCmd.Output == root_cmd.OutputFormatJson {
+				// stdout is reserved for events
+				consoleWriter = util.StderrConsoleWriter{}
+			}
 			consoleWriter.Println("================================")
 			var readString string
 			var attempts int
@@ -53,19 +62,21 @@ func NewDestroyCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 			}
 
 			if pCmd.ParentStack {
-				err := pCmd.Root.Provisioner.DestroyParent(cmd.Context(), pCmd.Params, preview)
+				err := pCmd.Root.Provisioner.DestroyParent(ctx, pCmd.Params, preview)
 				if err != nil && !rootCmd.IsCanceled.Load() {
 					return err
 				} else if rootCmd.IsCanceled.Load() {
-					err = pCmd.Root.Provisioner.Cancel(context.Background(), pCmd.Params.StackParams)
+					cancelCtx, _ := pCmd.Root.OutputContext(context.Background(), pCmd.Output)
+					err = pCmd.Root.Provisioner.Cancel(cancelCtx, pCmd.Params.StackParams)
 				}
 				return err
 			}
-			err := pCmd.Root.Provisioner.Destroy(cmd.Context(), pCmd.Params, preview)
+			err = pCmd.Root.Provisioner.Destroy(ctx, pCmd.Params, preview)
 			if err != nil && !rootCmd.IsCanceled.Load() {
 				return err
 			} else if rootCmd.IsCanceled.Load() {
-				err = pCmd.Root.Provisioner.Cancel(context.Background(), pCmd.Params.StackParams)
+				cancelCtx, _ := pCmd.Root.OutputContext(context.Background(), pCmd.Output)
+				err = pCmd.Root.Provisioner.Cancel(cancelCtx, pCmd.Params.StackParams)
 			}
 			return err
 		},
@@ -74,6 +85,8 @@ func NewDestroyCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 	root_cmd.RegisterStackFlags(cmd, &pCmd.Params.StackParams, false)
 	cmd.Flags().BoolVar(&pCmd.ParentStack, "parent", pCmd.ParentStack, "Destroy parent stack")
 	cmd.Flags().BoolVarP(&preview, "preview", "P", preview, "Preview destroy")
+	root_cmd.RegisterOutputFlag(cmd, &pCmd.Output)
 	cmd.Flags().BoolVar(&pCmd.Params.DestroySecretsStack, "with-secrets", pCmd.Params.DestroySecretsStack, "Destroy secrets stack as well (e.g. when no envs remained)")
+	cmd.Flags().StringVar(&pCmd.Params.Timeouts.LockTimeout, "lock-timeout", pCmd.Params.Timeouts.LockTimeout, "Time to wait for the stack locked by another operation (in Go's duration format, e.g. `10m`, default: fail immediately)")
 	return cmd
 }
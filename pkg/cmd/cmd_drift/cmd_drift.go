@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/discord"
+	"github.com/simple-container-com/api/pkg/clouds/slack"
+	"github.com/simple-container-com/api/pkg/clouds/telegram"
+	"github.com/simple-container-com/api/pkg/cmd/root_cmd"
+)
+
+type driftCmd struct {
+	Root         *root_cmd.RootCmd
+	Params       api.StackParams
+	JsonFile     string
+	MarkdownFile string
+
+	SlackWebhook   string
+	DiscordWebhook string
+	TelegramChatID string
+	TelegramToken  string
+}
+
+func NewDriftCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
+	dCmd := driftCmd{
+		Root: rootCmd,
+	}
+	cmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Detects resources of a stack changed or deleted outside of Simple Container",
+		Long:  "Refreshes stack state without applying it and reports resources which are drifted or deleted out of band.\nExits with non-zero code when drift is detected.",
+		Example: `  sc drift -s infrastructure
+  sc drift -s billing -e production --json drift.json --markdown drift.md
+  sc drift -s billing -e production --slack-webhook "$SLACK_WEBHOOK_URL"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := dCmd.Root.Provisioner.Drift(cmd.Context(), dCmd.Params)
+			if err != nil {
+				return err
+			}
+			if err := dCmd.writeReports(report); err != nil {
+				return err
+			}
+			PrintReport(report)
+			if report.HasDrift() {
+				if err := dCmd.sendAlerts(report); err != nil {
+					return err
+				}
+				return errors.Errorf("drift detected in stack %q: %d drifted, %d deleted",
+					dCmd.Params.StackName, report.Count(api.DriftStatusDrifted), report.Count(api.DriftStatusDeleted))
+			}
+			return nil
+		},
+	}
+	root_cmd.RegisterStackFlags(cmd, &dCmd.Params, false)
+	cmd.Flags().StringVar(&dCmd.JsonFile, "json", dCmd.JsonFile, "Write JSON report to the file")
+	cmd.Flags().StringVar(&dCmd.MarkdownFile, "markdown", dCmd.MarkdownFile, "Write Markdown report to the file")
+	cmd.Flags().StringVar(&dCmd.SlackWebhook, "slack-webhook", os.Getenv("SLACK_WEBHOOK_URL"), "Send alert to Slack webhook when drift is detected (default $SLACK_WEBHOOK_URL)")
+	cmd.Flags().StringVar(&dCmd.DiscordWebhook, "discord-webhook", os.Getenv("DISCORD_WEBHOOK_URL"), "Send alert to Discord webhook when drift is detected (default $DISCORD_WEBHOOK_URL)")
+	cmd.Flags().StringVar(&dCmd.TelegramChatID, "telegram-chat-id", os.Getenv("TELEGRAM_CHAT_ID"), "Send alert to Telegram chat when drift is detected (default $TELEGRAM_CHAT_ID)")
+	cmd.Flags().StringVar(&dCmd.TelegramToken, "telegram-token", os.Getenv("TELEGRAM_BOT_TOKEN"), "Telegram bot token used with --telegram-chat-id (default $TELEGRAM_BOT_TOKEN)")
+	return cmd
+}
+
+// alertSenders returns senders of all configured notification channels
+func (c *driftCmd) alertSenders() ([]api.AlertSender, error) {
+	var senders []api.AlertSender
+	if c.SlackWebhook != "" {
+		sender, err := slack.New(c.SlackWebhook)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to init slack alert sender")
+		}
+		senders = append(senders, sender)
+	}
+	if c.DiscordWebhook != "" {
+		sender, err := discord.New(c.DiscordWebhook)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to init discord alert sender")
+		}
+		senders = append(senders, sender)
+	}
+	if c.TelegramChatID != "" || c.TelegramToken != "" {
+		if c.TelegramChatID == "" || c.TelegramToken == "" {
+			return nil, errors.Errorf("both --telegram-chat-id and --telegram-token must be set to send telegram alerts")
+		}
+		senders = append(senders, telegram.New(c.TelegramChatID, c.TelegramToken))
+	}
+	return senders, nil
+}
+
+// sendAlerts sends drift report to every configured notification channel
+func (c *driftCmd) sendAlerts(report *api.DriftReport) error {
+	senders, err := c.alertSenders()
+	if err != nil {
+		return err
+	}
+	alert := report.ToAlert()
+	var sendErrs []error
+	for _, sender := range senders {
+		if err := sender.Send(alert); err != nil {
+			sendErrs = append(sendErrs, err)
+		}
+	}
+	if len(sendErrs) > 0 {
+		return errors.Errorf("failed to send drift alert: %v", sendErrs)
+	}
+	return nil
+}
+
+func (c *driftCmd) writeReports(report *api.DriftReport) error {
+	if c.JsonFile != "" {
+		j, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal drift report")
+		}
+		if err := os.WriteFile(c.JsonFile, j, 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write drift report to %q", c.JsonFile)
+		}
+	}
+	if c.MarkdownFile != "" {
+		if err := os.WriteFile(c.MarkdownFile, []byte(report.Markdown()), 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write drift report to %q", c.MarkdownFile)
+		}
+	}
+	return nil
+}
+
+// PrintReport prints resources which are not in sync as a table
+func PrintReport(report *api.DriftReport) {
+	if !report.HasDrift() {
+		fmt.Printf("No drift detected in %d resources\n", len(report.Resources))
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STATUS\tTYPE\tURN\tPROPERTIES")
+	for _, res := range report.Resources {
+		if res.Status == api.DriftStatusInSync {
+			continue
+		}
+		props := lo.Map(res.Properties, func(p api.DriftedProperty, _ int) string {
+			return fmt.Sprintf("%s(%s)", p.Path, p.Kind)
+		})
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", res.Status, res.Type, res.URN, lo.Ternary(len(props) > 0, strings.Join(props, " "), "-"))
+	}
+	_ = w.Flush()
+}
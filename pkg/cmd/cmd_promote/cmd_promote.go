@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_promote
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/cmd/root_cmd"
+)
+
+type promoteCmd struct {
+	Root   *root_cmd.RootCmd
+	Params api.PromoteParams
+}
+
+func NewPromoteCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
+	pCmd := promoteCmd{
+		Root: rootCmd,
+		Params: api.PromoteParams{
+			DeployParams: api.DeployParams{
+				StackParams: api.StackParams{
+					DetailedDiff: true,
+				},
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "promote",
+		Short:   "Deploys images and version running in one environment to another one without rebuilding",
+		Example: "sc promote -s billing --from staging --to production",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := pCmd.Root.Provisioner.Promote(cmd.Context(), pCmd.Params)
+			if err != nil && !rootCmd.IsCanceled.Load() {
+				return err
+			} else if rootCmd.IsCanceled.Load() {
+				return pCmd.Root.Provisioner.Cancel(context.Background(), pCmd.Params.StackParams)
+			}
+			fmt.Printf("Stack %q is promoted from %q to %q: %s\n", pCmd.Params.StackName, pCmd.Params.FromEnvironment, pCmd.Params.Environment, res)
+			return nil
+		},
+	}
+
+	root_cmd.RegisterStackFlags(cmd, &pCmd.Params.StackParams, false)
+	// target environment is specified with --to
+	_ = cmd.Flags().MarkHidden("env")
+	cmd.Flags().StringVar(&pCmd.Params.FromEnvironment, "from", pCmd.Params.FromEnvironment, "Environment to promote images and version from (required)")
+	cmd.Flags().StringVar(&pCmd.Params.Environment, "to", pCmd.Params.Environment, "Environment to promote to (required)")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	cmd.Flags().StringVarP(&pCmd.Params.Timeouts.ExecutionTimeout, "execution-timeout", "O", pCmd.Params.Timeouts.ExecutionTimeout, "Timeout on whole command execution (in Go's duration format, e.g. `20m`)")
+	cmd.Flags().StringVarP(&pCmd.Params.Timeouts.DeployTimeout, "timeout", "T", pCmd.Params.Timeouts.DeployTimeout, "Timeout on deploy/provision operations (in Go's duration format, e.g. `20m`)")
+	cmd.Flags().StringVar(&pCmd.Params.Timeouts.LockTimeout, "lock-timeout", pCmd.Params.Timeouts.LockTimeout, "Time to wait for the stack locked by another operation (in Go's duration format, e.g. `10m`, default: fail immediately)")
+	return cmd
+}
@@ -4,18 +4,26 @@
 package cmd_provision
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
 	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
 	"github.com/simple-container-com/api/pkg/cmd/root_cmd"
 )
 
 type provisionCmd struct {
-	Root    *root_cmd.RootCmd
-	Preview bool
-	Params  api.ProvisionParams
+	Root        *root_cmd.RootCmd
+	Preview     bool
+	PreviewJson string
+	Output      string
+	Params      api.ProvisionParams
 }
 
 func NewProvisionCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
@@ -29,21 +37,33 @@ func NewProvisionCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 		Use:   "provision",
 		Short: "Provisions stacks defined in stacks directory",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := pCmd.Root.OutputContext(cmd.Context(), pCmd.Output)
+			if err != nil {
+				return err
+			}
 			if pCmd.Preview {
-				res, err := pCmd.Root.Provisioner.PreviewProvision(cmd.Context(), pCmd.Params)
+				res, err := pCmd.Root.Provisioner.PreviewProvision(ctx, pCmd.Params)
 				if err != nil {
 					return err
 				}
+				if err := WritePreviewJson(pCmd.PreviewJson, res); err != nil {
+					return err
+				}
+				if pCmd.Output == root_cmd.OutputFormatJson {
+					return nil
+				}
 				fmt.Println("Summary:")
 				for _, pRes := range res {
 					PrintPreview(pRes)
 				}
 				return nil
 			}
-			return pCmd.Root.Provisioner.Provision(cmd.Context(), pCmd.Params)
+			return pCmd.Root.Provisioner.Provision(ctx, pCmd.Params)
 		},
 	}
 	cmd.Flags().BoolVarP(&pCmd.Preview, "preview", "P", pCmd.Preview, "Preview instead of provision (dry-run)")
+	RegisterPreviewJsonFlag(cmd, &pCmd.PreviewJson)
+	root_cmd.RegisterOutputFlag(cmd, &pCmd.Output)
 	RegisterProvisionFlags(cmd, &pCmd.Params)
 	return cmd
 }
@@ -54,6 +74,7 @@ func RegisterProvisionFlags(cmd *cobra.Command, p *api.ProvisionParams) {
 	cmd.Flags().StringVarP(&p.StacksDir, "dir", "d", p.StacksDir, "Root directory for stack configurations (default: .sc/stacks)")
 	cmd.Flags().BoolVarP(&p.SkipRefresh, "skip-refresh", "R", p.SkipRefresh, "Skip refresh before provision")
 	cmd.Flags().BoolVarP(&p.SkipPreview, "skip-preview", "S", p.SkipPreview, "Skip preview before provision")
+	cmd.Flags().StringSliceVar(&p.OverridePolicies, "override-policy", p.OverridePolicies, "Proceed despite violations of the named policy (can be repeated)")
 	cmd.Flags().BoolVarP(&p.DetailedDiff, "diff", "D", p.DetailedDiff, "Show detailed diff with granular changes for nested properties (e.g., redisConfigs)")
 	cmd.Flags().BoolVar(&p.DetailedDiff, "detailed-diff", p.DetailedDiff, "Alias for --diff")
 	_ = cmd.Flags().MarkHidden("detailed-diff") // Hide the alias from help output
@@ -61,6 +82,7 @@ func RegisterProvisionFlags(cmd *cobra.Command, p *api.ProvisionParams) {
 	cmd.Flags().StringVarP(&p.Timeouts.PreviewTimeout, "preview-timeout", "M", p.Timeouts.PreviewTimeout, "Timeout on preview operations (in Go's duration format, e.g. `20m`)")
 	cmd.Flags().StringVarP(&p.Timeouts.ExecutionTimeout, "execution-timeout", "O", p.Timeouts.ExecutionTimeout, "Timeout on whole command execution (in Go's duration format, e.g. `20m`)")
 	cmd.Flags().StringVarP(&p.Timeouts.DeployTimeout, "timeout", "T", p.Timeouts.DeployTimeout, "Timeout on deploy/provision operations (in Go's duration format, e.g. `20m`)")
+	cmd.Flags().StringVar(&p.Timeouts.LockTimeout, "lock-timeout", p.Timeouts.LockTimeout, "Time to wait for the stack locked by another operation (in Go's duration format, e.g. `10m`, default: fail immediately)")
 }
 
 func PrintPreview(pRes *api.PreviewResult) {
@@ -69,4 +91,30 @@ func PrintPreview(pRes *api.PreviewResult) {
 		fmt.Printf("    %s: %d\n", op, cnt)
 	}
 	fmt.Println(pRes.Summary)
+	if destructive := pRes.DestructiveSteps(); len(destructive) > 0 {
+		fmt.Println(color.RedFmt("WARNING: %d resource(s) will be replaced or deleted:", len(destructive)))
+		for _, step := range destructive {
+			fmt.Println(color.RedFmt("    %s %s %s", step.Op, step.URN, lo.Ternary(step.ForcedReplace, fmt.Sprintf("(forced by %s)", strings.Join(step.ReplaceKeys, ", ")), "")))
+		}
+	}
+}
+
+// RegisterPreviewJsonFlag registers flag to write planned changes of preview as JSON
+func RegisterPreviewJsonFlag(cmd *cobra.Command, file *string) {
+	cmd.Flags().StringVar(file, "preview-json", *file, "Write preview results with per-resource change plan as JSON to the file (with --preview)")
+}
+
+// WritePreviewJson writes preview results to the file (if any)
+func WritePreviewJson(file string, res any) error {
+	if file == "" {
+		return nil
+	}
+	j, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal preview results")
+	}
+	if err := os.WriteFile(file, j, 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write preview results to %q", file)
+	}
+	return nil
 }
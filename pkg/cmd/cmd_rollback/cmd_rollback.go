@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_rollback
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/cmd/root_cmd"
+)
+
+type rollbackCmd struct {
+	Root   *root_cmd.RootCmd
+	Params api.RollbackParams
+	List   bool
+}
+
+func NewRollbackCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
+	pCmd := rollbackCmd{
+		Root: rootCmd,
+		Params: api.RollbackParams{
+			DeployParams: api.DeployParams{
+				StackParams: api.StackParams{
+					DetailedDiff: true,
+				},
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Rolls stack back to one of its previous deployments",
+		Example: `  sc rollback -s billing -e production --list
+  sc rollback -s billing -e production
+  sc rollback -s billing -e production --to 42`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pCmd.List {
+				history, err := pCmd.Root.Provisioner.History(cmd.Context(), pCmd.Params.StackParams)
+				if err != nil {
+					return err
+				}
+				PrintHistory(history)
+				return nil
+			}
+			res, err := pCmd.Root.Provisioner.Rollback(cmd.Context(), pCmd.Params)
+			if err != nil && !rootCmd.IsCanceled.Load() {
+				return err
+			} else if rootCmd.IsCanceled.Load() {
+				return pCmd.Root.Provisioner.Cancel(context.Background(), pCmd.Params.StackParams)
+			}
+			fmt.Printf("Stack %q in %q is rolled back: %s\n", pCmd.Params.StackName, pCmd.Params.Environment, res)
+			return nil
+		},
+	}
+
+	root_cmd.RegisterStackFlags(cmd, &pCmd.Params.StackParams, false)
+	_ = cmd.MarkFlagRequired("env")
+	cmd.Flags().IntVar(&pCmd.Params.ToUpdate, "to", pCmd.Params.ToUpdate, "Update number (see --list) to roll back to (default: previous deployment)")
+	cmd.Flags().BoolVar(&pCmd.List, "list", pCmd.List, "List previous updates of the stack instead of rolling back")
+	cmd.Flags().StringVarP(&pCmd.Params.Timeouts.ExecutionTimeout, "execution-timeout", "O", pCmd.Params.Timeouts.ExecutionTimeout, "Timeout on whole command execution (in Go's duration format, e.g. `20m`)")
+	cmd.Flags().StringVarP(&pCmd.Params.Timeouts.DeployTimeout, "timeout", "T", pCmd.Params.Timeouts.DeployTimeout, "Timeout on deploy/provision operations (in Go's duration format, e.g. `20m`)")
+	cmd.Flags().StringVar(&pCmd.Params.Timeouts.LockTimeout, "lock-timeout", pCmd.Params.Timeouts.LockTimeout, "Time to wait for the stack locked by another operation (in Go's duration format, e.g. `10m`, default: fail immediately)")
+	cmd.MarkFlagsMutuallyExclusive("to", "list")
+	return cmd
+}
+
+// PrintHistory prints updates of the stack as a table
+func PrintHistory(history []api.StackHistoryEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "UPDATE\tKIND\tRESULT\tSTARTED\tVERSION\tIMAGES")
+	for _, entry := range history {
+		version, images := "-", "-"
+		if entry.Revision != nil {
+			version = entry.Revision.Version
+			refs := lo.Values(entry.Revision.Images)
+			sort.Strings(refs)
+			images = strings.Join(refs, " ")
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", entry.UpdateVersion, entry.Kind, entry.Result, entry.StartTime, version, images)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_secrets
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+func NewAgentKeysCmd(sCmd *secretsCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent-keys [FINGERPRINT]",
+		Short: "List ssh-agent keys or print public key derived from one of them",
+		Long: "Without arguments lists keys of ssh-agent (SSH_AUTH_SOCK) which secrets key pair can be derived from.\n" +
+			"With the fingerprint of a key prints the derived public key: allow it with `sc secrets allow` and set\n" +
+			"`privateKeyAgent: <fingerprint>` in .sc/cfg.<profile>.yaml instead of privateKey/privateKeyPath,\n" +
+			"so the private key never leaves the agent. Only ssh-ed25519 and ssh-rsa keys are supported.",
+		Example: `  sc secrets agent-keys
+  sc secrets allow "$(sc secrets agent-keys SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8)"`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				signer, err := secrets.AgentKeySigner(args[0])
+				if err != nil {
+					return err
+				}
+				_, pubKey, err := secrets.DeriveSignerKeyPair(signer)
+				if err != nil {
+					return err
+				}
+				fmt.Println(pubKey)
+				return nil
+			}
+			keys, err := secrets.ListAgentKeys()
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "FINGERPRINT\tTYPE\tCOMMENT")
+			for _, key := range keys {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", key.Fingerprint, key.Type, key.Comment)
+			}
+			return w.Flush()
+		},
+	}
+	return cmd
+}
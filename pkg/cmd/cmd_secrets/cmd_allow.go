@@ -5,20 +5,36 @@ package cmd_secrets
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api/secrets"
 )
 
 func NewAllowCmd(sCmd *secretsCmd) *cobra.Command {
+	var group string
+
 	cmd := &cobra.Command{
 		Use:   "allow",
 		Short: "Allow public key to read secrets",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			cryptor := sCmd.Root.Provisioner.Cryptor()
 			// Reveal secrets first to ensure we're working with the latest state
-			if err := sCmd.Root.Provisioner.Cryptor().DecryptAll(false); err != nil {
+			if err := cryptor.DecryptAll(false); err != nil {
 				return err
 			}
 			pubKey := args[0]
-			return sCmd.Root.Provisioner.Cryptor().AddPublicKey(pubKey)
+			if err := cryptor.AddPublicKey(pubKey); err != nil {
+				return err
+			}
+			if group == "" {
+				return nil
+			}
+			recipientGroup := cryptor.GetSecretFiles().Registry.Groups[group]
+			if !recipientGroup.HasPublicKey(pubKey) {
+				recipientGroup.PublicKeys = append(recipientGroup.PublicKeys, secrets.TrimPubKey(pubKey))
+			}
+			return cryptor.SetRecipientGroup(group, recipientGroup)
 		},
 	}
+	cmd.Flags().StringVarP(&group, "group", "g", group, "Also add public key to recipient group (see `sc secrets scope`)")
 	return cmd
 }
@@ -5,7 +5,12 @@ package cmd_secrets
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
@@ -13,12 +18,40 @@ func NewAllowedKeysCmd(sCmd *secretsCmd) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "allowed-keys",
 		Short: "List public keys allowed to decrypt secrets",
+		Long: "Lists public keys and the matrix of secret files they can decrypt:\n" +
+			"  x - file is encrypted for the key\n" +
+			"  ! - key is allowed to decrypt the file, but it is not encrypted for it yet (run `sc secrets hide`)\n" +
+			"  - - file is restricted to recipient groups the key is not a member of",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			for pubKey := range sCmd.Root.Provisioner.Cryptor().GetSecretFiles().Secrets {
-				fmt.Println(pubKey)
+			secretFiles := sCmd.Root.Provisioner.Cryptor().GetSecretFiles()
+			pubKeys := lo.Keys(secretFiles.Secrets)
+			sort.Strings(pubKeys)
+			for i, pubKey := range pubKeys {
+				fmt.Printf("[%d] %s\n", i+1, pubKey)
+				if groups := secretFiles.Registry.GroupsOfKey(pubKey); len(groups) > 0 {
+					fmt.Printf("    groups: %s\n", strings.Join(groups, ", "))
+				}
 				fmt.Println()
 			}
-			return nil
+			if len(secretFiles.Registry.Files) == 0 {
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "FILE\tGROUPS\t%s\n", strings.Join(lo.Times(len(pubKeys), func(i int) string {
+				return fmt.Sprintf("[%d]", i+1)
+			}), "\t"))
+			for _, file := range secretFiles.Registry.Files {
+				marks := lo.Map(pubKeys, func(pubKey string, _ int) string {
+					keySecrets := secretFiles.Secrets[pubKey]
+					encrypted := len(keySecrets.GetEncryptedContent(file)) > 0
+					allowed := secretFiles.Registry.Allows(file, pubKey)
+					return lo.Ternary(encrypted, "x", lo.Ternary(allowed, "!", "-"))
+				})
+				groups := lo.Ternary(len(secretFiles.Registry.GroupsOf(file)) > 0, strings.Join(secretFiles.Registry.GroupsOf(file), ","), "*")
+				fmt.Fprintf(w, "%s\t%s\t%s\n", file, groups, strings.Join(marks, "\t"))
+			}
+			return w.Flush()
 		},
 	}
 	return cmd
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_secrets
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+func NewCipherCmd(sCmd *secretsCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cipher [sc|age]",
+		Short: "Show or select cipher of the secrets store",
+		Long: "Without arguments prints the cipher secrets are encrypted with. Otherwise selects the cipher and re-encrypts\n" +
+			"all secrets with it: `sc` (default) is readable by sc only, `age` writes armored age files for the same\n" +
+			"ssh-rsa and ssh-ed25519 keys, which can be decrypted with the age CLI, e.g. `age -d -i ~/.ssh/id_ed25519`.",
+		Example: `  sc secrets cipher
+  sc secrets cipher age`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{secrets.CipherSc, secrets.CipherAge},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cryptor := sCmd.Root.Provisioner.Cryptor()
+			if len(args) == 0 {
+				fmt.Println(cryptor.GetSecretFiles().Registry.CipherName())
+				return nil
+			}
+			// Reveal secrets first to ensure we're working with the latest state
+			if err := cryptor.DecryptAll(false); err != nil {
+				return err
+			}
+			return cryptor.SetCipher(args[0])
+		},
+	}
+	return cmd
+}
@@ -4,6 +4,7 @@
 package cmd_secrets
 
 import (
+	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
@@ -12,6 +13,8 @@ import (
 )
 
 func NewDisallowCmd(sCmd *secretsCmd) *cobra.Command {
+	var group string
+
 	cmd := &cobra.Command{
 		Use:   "disallow",
 		Short: "Disallow public key to read secrets",
@@ -21,7 +24,17 @@ func NewDisallowCmd(sCmd *secretsCmd) *cobra.Command {
 				return err
 			}
 			pubKey := args[0]
-			return sCmd.Root.Provisioner.Cryptor().RemovePublicKey(pubKey)
+			if group == "" {
+				return sCmd.Root.Provisioner.Cryptor().RemovePublicKey(pubKey)
+			}
+			recipientGroup, found := sCmd.Root.Provisioner.Cryptor().GetSecretFiles().Registry.Groups[group]
+			if !found || !recipientGroup.HasPublicKey(pubKey) {
+				return errors.Errorf("public key %q is not in recipient group %q", secrets.TrimPubKey(pubKey), group)
+			}
+			recipientGroup.PublicKeys = lo.Filter(recipientGroup.PublicKeys, func(key string, _ int) bool {
+				return secrets.TrimPubKey(key) != secrets.TrimPubKey(pubKey)
+			})
+			return sCmd.Root.Provisioner.Cryptor().SetRecipientGroup(group, recipientGroup)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) != 0 {
@@ -35,5 +48,6 @@ func NewDisallowCmd(sCmd *secretsCmd) *cobra.Command {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		},
 	}
+	cmd.Flags().StringVarP(&group, "group", "g", group, "Only remove public key from recipient group, keeping its access to other secrets")
 	return cmd
 }
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+const (
+	guardHookName   = "pre-commit"
+	guardHookMarker = "# installed by `sc secrets guard --install`"
+)
+
+var guardHookScript = "#!/bin/sh\n" + guardHookMarker + "\nexec sc secrets guard\n"
+
+type guardParams struct {
+	all     bool
+	install bool
+	force   bool
+}
+
+func NewGuardCmd(sCmd *secretsCmd) *cobra.Command {
+	params := guardParams{}
+
+	cmd := &cobra.Command{
+		Use:   "guard",
+		Short: "Check files staged for commit for revealed secrets",
+		Long: "Fails if files staged for commit include plaintext secret files registered in .sc/secrets.yaml or values of\n" +
+			"decrypted secrets (compared by hash), or if revealed secret files differ from .sc/secrets.yaml, i.e. have local\n" +
+			"changes to encrypt with `sc secrets hide` or are stale copies to replace with `sc secrets reveal`.\n" +
+			"With --all every file in the git index is checked, which is meant for CI.\n" +
+			"With --install the check is installed as git pre-commit hook.",
+		Example: `  sc secrets guard --install
+  sc secrets guard
+  sc secrets guard --all`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cryptor := sCmd.Root.Provisioner.Cryptor()
+			if params.install {
+				return installGuardHook(cryptor, params.force)
+			}
+			files, err := cryptor.GitRepo().IndexFiles(!params.all)
+			if err != nil {
+				return err
+			}
+			report, err := cryptor.Guard(files)
+			if err != nil {
+				return err
+			}
+			if report.Passed() {
+				fmt.Println(color.GreenFmt("no revealed secrets found in %d file(s)", len(files)))
+				return nil
+			}
+			for _, finding := range report.Findings {
+				fmt.Printf("%s %s: %s\n", color.RedFmt("[%s]", finding.Kind), finding.Path, finding.Detail)
+			}
+			return errors.Errorf("found %d problem(s) which would leak secrets with the commit", len(report.Findings))
+		},
+	}
+	cmd.Flags().BoolVar(&params.all, "all", params.all, "Check all files in the git index instead of staged changes only")
+	cmd.Flags().BoolVar(&params.install, "install", params.install, "Install the check as git pre-commit hook")
+	cmd.Flags().BoolVar(&params.force, "force", params.force, "Replace existing pre-commit hook on --install")
+	return cmd
+}
+
+func installGuardHook(cryptor secrets.Cryptor, force bool) error {
+	existing, err := cryptor.GitRepo().Hook(guardHookName)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && !strings.Contains(string(existing), guardHookMarker) && !force {
+		return errors.Errorf("git %s hook already exists, add `sc secrets guard` to it or replace it with --force", guardHookName)
+	}
+	if err := cryptor.GitRepo().InstallHook(guardHookName, []byte(guardHookScript)); err != nil {
+		return err
+	}
+	fmt.Printf("installed git %s hook running `sc secrets guard`\n", guardHookName)
+	return nil
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+type historyParams struct {
+	json bool
+}
+
+func NewHistoryCmd(sCmd *secretsCmd) *cobra.Command {
+	params := historyParams{}
+
+	cmd := &cobra.Command{
+		Use:   "history [FILE]",
+		Short: "Show who changed secrets and their recipients and when",
+		Long: "Walks commits of .sc/secrets.yaml, decrypts every revision in memory with the current key and lists\n" +
+			"added and removed public keys, registered secret files and keys of their values which were added, changed\n" +
+			"or removed. Values are never printed, new values are identified by fingerprints keyed with the first\n" +
+			"commit of .sc/secrets.yaml, equal values have equal fingerprints. With FILE only changes of\n" +
+			"the file are listed and recipients are public keys the file is encrypted for.",
+		Example: `  sc secrets history
+  sc secrets history stacks/prod/secrets.yaml
+  sc secrets history --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			relFilePath := ""
+			if len(args) == 1 {
+				relFilePath = args[0]
+			}
+			history, err := sCmd.Root.Provisioner.Cryptor().History(relFilePath)
+			if err != nil {
+				return err
+			}
+			if params.json {
+				out, err := json.MarshalIndent(history, "", "  ")
+				if err != nil {
+					return errors.Wrapf(err, "failed to marshal history")
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			for _, entry := range history {
+				printHistoryEntry(entry)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&params.json, "json", params.json, "Print history as JSON")
+	return cmd
+}
+
+func printHistoryEntry(entry secrets.HistoryEntry) {
+	fmt.Println(color.YellowFmt("commit %s", entry.Commit))
+	fmt.Printf("Author: %s\n", entry.Author)
+	fmt.Printf("Date:   %s\n", entry.When.Format(time.RFC1123Z))
+	fmt.Printf("\n    %s\n\n", entry.Message)
+	for _, change := range entry.Changes {
+		switch change.Kind {
+		case secrets.HistoryRecipientAdded, secrets.HistoryRecipientRemoved:
+			if change.File != "" {
+				fmt.Printf("    %s %s (%s)\n", historyChangeMark(change.Kind), change.Key, change.File)
+			} else {
+				fmt.Printf("    %s %s\n", historyChangeMark(change.Kind), change.Key)
+			}
+		case secrets.HistoryFileAdded, secrets.HistoryFileRemoved:
+			fmt.Printf("    %s %s\n", historyChangeMark(change.Kind), color.MagentaFmt("%s", change.File))
+		case secrets.HistoryFileUndecryptable:
+			fmt.Printf("    %s %s (current key cannot decrypt it)\n", historyChangeMark(change.Kind), color.MagentaFmt("%s", change.File))
+		default:
+			if change.Fingerprint != "" {
+				fmt.Printf("    %s %s %s (fingerprint %s)\n", historyChangeMark(change.Kind), color.MagentaFmt("%s", change.File), change.Key, change.Fingerprint)
+			} else {
+				fmt.Printf("    %s %s %s\n", historyChangeMark(change.Kind), color.MagentaFmt("%s", change.File), change.Key)
+			}
+		}
+	}
+	fmt.Println()
+}
+
+func historyChangeMark(kind secrets.HistoryChangeKind) string {
+	switch kind {
+	case secrets.HistoryRecipientAdded, secrets.HistoryFileAdded, secrets.HistoryValueAdded:
+		return color.GreenFmt("%-17s", kind)
+	case secrets.HistoryRecipientRemoved, secrets.HistoryFileRemoved, secrets.HistoryValueRemoved:
+		return color.RedFmt("%-17s", kind)
+	default:
+		return color.YellowFmt("%-17s", kind)
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+func NewRotateCmd(sCmd *secretsCmd) *cobra.Command {
+	params := secrets.RotateParams{}
+
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Replace key pair of the profile and re-encrypt secrets without revoked keys",
+		Long: "Generates a new key pair for the current profile, re-encrypts all secrets for the remaining public keys\n" +
+			"and increments key epoch of the secrets registry. Previous key of the profile, keys passed with --revoke\n" +
+			"and keys removed with `sc secrets disallow` since the last rotation cannot decrypt the new ciphertexts,\n" +
+			"but the old ones stay in git history: values they could read are listed to be rotated upstream.",
+		Example: `  sc secrets rotate --dry-run
+  sc secrets rotate --revoke "$(cat former-teammate.pub)"
+  sc secrets rotate --profile github --key-type ed25519`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := sCmd.Root.Provisioner.Cryptor().Rotate(params)
+			if err != nil {
+				return err
+			}
+			printRotationReport(report, params.DryRun)
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&params.RevokeKeys, "revoke", params.RevokeKeys, "Public key to remove from recipients (can be repeated)")
+	cmd.Flags().StringVar(&params.KeyType, "key-type", params.KeyType, "Type of the new key pair: rsa or ed25519 (default: type of the current key)")
+	cmd.Flags().BoolVar(&params.DryRun, "dry-run", params.DryRun, "Only list affected files without changing anything")
+	return cmd
+}
+
+func printRotationReport(report *secrets.RotationReport, dryRun bool) {
+	if dryRun {
+		fmt.Printf("Rotation to key epoch %d would re-encrypt:\n", report.Epoch)
+	} else {
+		fmt.Printf("Rotated to key epoch %d, re-encrypted:\n", report.Epoch)
+	}
+	for _, file := range report.Files {
+		if file.ReEncrypted {
+			fmt.Printf("  %s\n", file.Path)
+		} else {
+			fmt.Printf("  %s (skipped: current key cannot decrypt it)\n", file.Path)
+		}
+	}
+	if len(report.RevokedKeys) > 0 {
+		fmt.Println("Revoked keys:")
+		for _, key := range report.RevokedKeys {
+			fmt.Printf("  %s\n", key)
+		}
+	}
+	if exposed := report.Exposed(); len(exposed) > 0 {
+		fmt.Println("Secrets readable by revoked keys in git history, rotate them upstream:")
+		for _, file := range exposed {
+			values := lo.Ternary(len(file.ExposedValues) > 0, strings.Join(file.ExposedValues, ", "), "whole file")
+			fmt.Printf("  %s: %s\n", file.Path, values)
+		}
+	}
+	if report.NewPublicKey != "" {
+		fmt.Printf("New public key of profile %q:\n%s\n", report.Profile, report.NewPublicKey)
+	}
+}
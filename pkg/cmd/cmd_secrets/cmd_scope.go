@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_secrets
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+func NewScopeCmd(sCmd *secretsCmd) *cobra.Command {
+	var remove bool
+
+	cmd := &cobra.Command{
+		Use:   "scope GROUP [GLOB...]",
+		Short: "Restrict secret files matching globs to public keys of recipient group",
+		Long: "Sets file globs of the recipient group: files matching them are encrypted only for public keys of the groups\n" +
+			"matching the file, other files are encrypted for all allowed public keys. Keys are added to the group with\n" +
+			"`sc secrets allow --group GROUP`.",
+		Example: `  sc secrets allow --group prod "$(cat ci-prod.pub)"
+  sc secrets scope prod '.sc/stacks/*-prod/secrets.yaml' .sc/stacks/common/secrets.yaml
+  sc secrets scope prod --remove`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cryptor := sCmd.Root.Provisioner.Cryptor()
+			// Reveal secrets first to ensure we're working with the latest state
+			if err := cryptor.DecryptAll(false); err != nil {
+				return err
+			}
+			group := cryptor.GetSecretFiles().Registry.Groups[args[0]]
+			group.Files = args[1:]
+			if remove {
+				group = secrets.RecipientGroup{}
+			}
+			return cryptor.SetRecipientGroup(args[0], group)
+		},
+	}
+	cmd.Flags().BoolVar(&remove, "remove", remove, "Remove recipient group, files are encrypted for all allowed public keys again")
+	return cmd
+}
@@ -30,9 +30,19 @@ func NewSecretsCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 		NewRevealCmd(sCmd),
 		NewAllowCmd(sCmd),
 		NewDisallowCmd(sCmd),
+		NewScopeCmd(sCmd),
+		NewRotateCmd(sCmd),
+		NewCipherCmd(sCmd),
+		NewGuardCmd(sCmd),
+		NewAgentKeysCmd(sCmd),
+		NewHistoryCmd(sCmd),
 		NewAddCmd(sCmd),
 		NewDeleteCmd(sCmd),
 		NewInitCmd(sCmd),
+		NewSetCmd(sCmd),
+		NewGetCmd(sCmd),
+		NewUnsetCmd(sCmd),
+		NewListKeysCmd(sCmd),
 	)
 	return cmd
 }
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_secrets
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/simple-container-com/api/pkg/api/secrets"
+	"github.com/simple-container-com/api/pkg/provisioner"
+	"github.com/simple-container-com/api/pkg/util"
+)
+
+type valuesParams struct {
+	StackName string
+	StacksDir string
+}
+
+func (p *valuesParams) register(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&p.StackName, "stack", "s", p.StackName, "Stack name whose secrets.yaml to edit")
+	cmd.Flags().StringVarP(&p.StacksDir, "dir", "d", p.StacksDir, "Root directory for stack configurations")
+	_ = cmd.MarkFlagRequired("stack")
+}
+
+func (p *valuesParams) secretsFile() string {
+	return secrets.StackSecretsFile(p.StacksDir, p.StackName)
+}
+
+func newValuesParams() *valuesParams {
+	return &valuesParams{StacksDir: provisioner.DefaultStacksRootDir}
+}
+
+// readValues decrypts secrets.yaml of the stack in memory, returns empty content if the file is not added yet
+func readValues(sCmd *secretsCmd, p *valuesParams) ([]byte, error) {
+	cryptor := sCmd.Root.Provisioner.Cryptor()
+	relPath := p.secretsFile()
+	for _, file := range cryptor.GetSecretFiles().Registry.Files {
+		if file == relPath {
+			return cryptor.GetAndDecryptFileContent(relPath)
+		}
+	}
+	return nil, nil
+}
+
+func NewSetCmd(sCmd *secretsCmd) *cobra.Command {
+	p := newValuesParams()
+	cmd := &cobra.Command{
+		Use:   "set KEY",
+		Short: "Set secret value in stack's secrets.yaml",
+		Long: "Sets the key in `values` of stack's secrets.yaml and re-encrypts the file without writing it to disk.\n" +
+			"The value is read from stdin when it is not a terminal, otherwise it is prompted for.",
+		Example: `  sc secrets set -s billing DB_PASSWORD
+  echo -n "$TOKEN" | sc secrets set -s billing API_TOKEN`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, err := readSecretValue(args[0])
+			if err != nil {
+				return err
+			}
+			if err := secrets.UpdateSecretFile(sCmd.Root.Provisioner.Cryptor(), p.secretsFile(), func(content []byte) ([]byte, error) {
+				return secrets.SetSecretValue(content, args[0], value)
+			}); err != nil {
+				return err
+			}
+			fmt.Printf("set %q in %s\n", args[0], p.secretsFile())
+			return nil
+		},
+	}
+	p.register(cmd)
+	return cmd
+}
+
+func NewGetCmd(sCmd *secretsCmd) *cobra.Command {
+	p := newValuesParams()
+	cmd := &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print secret value from stack's secrets.yaml",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			content, err := readValues(sCmd, p)
+			if err != nil {
+				return err
+			}
+			value, found, err := secrets.GetSecretValue(content, args[0])
+			if err != nil {
+				return err
+			}
+			if !found {
+				return errors.Errorf("secret %q is not found in %s", args[0], p.secretsFile())
+			}
+			fmt.Println(value)
+			return nil
+		},
+	}
+	p.register(cmd)
+	return cmd
+}
+
+func NewUnsetCmd(sCmd *secretsCmd) *cobra.Command {
+	p := newValuesParams()
+	cmd := &cobra.Command{
+		Use:   "unset KEY",
+		Short: "Remove secret value from stack's secrets.yaml",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			found := false
+			if err := secrets.UpdateSecretFile(sCmd.Root.Provisioner.Cryptor(), p.secretsFile(), func(content []byte) ([]byte, error) {
+				var updated []byte
+				var err error
+				updated, found, err = secrets.UnsetSecretValue(content, args[0])
+				return updated, err
+			}); err != nil {
+				return err
+			}
+			if !found {
+				return errors.Errorf("secret %q is not found in %s", args[0], p.secretsFile())
+			}
+			fmt.Printf("unset %q in %s\n", args[0], p.secretsFile())
+			return nil
+		},
+	}
+	p.register(cmd)
+	return cmd
+}
+
+func NewListKeysCmd(sCmd *secretsCmd) *cobra.Command {
+	p := newValuesParams()
+	cmd := &cobra.Command{
+		Use:   "list-keys",
+		Short: "List keys of secret values in stack's secrets.yaml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			content, err := readValues(sCmd, p)
+			if err != nil {
+				return err
+			}
+			keys, err := secrets.SecretValueKeys(content)
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				fmt.Println(key)
+			}
+			return nil
+		},
+	}
+	p.register(cmd)
+	return cmd
+}
+
+// readSecretValue reads the value from piped stdin (trailing newline is trimmed) or prompts for it
+func readSecretValue(key string) (string, error) {
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read value of %q from stdin", key)
+		}
+		return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+	}
+	fmt.Printf("Value of %s: ", key)
+	value, err := util.DefaultConsoleReader.ReadPassword()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read value of %q", key)
+	}
+	if value == "" {
+		return "", errors.Errorf("value of %q is empty", key)
+	}
+	return value, nil
+}
@@ -4,27 +4,76 @@
 package cmd_stack
 
 import (
-	"encoding/json"
 	"fmt"
 
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
 )
 
+type outputsCmd struct {
+	Format string
+	Key    string
+	Reveal bool
+	All    bool
+}
+
 func NewOutputsCmd(sCmd *stackCmd) *cobra.Command {
+	oCmd := outputsCmd{}
 	cmd := &cobra.Command{
 		Use:   "outputs",
 		Short: "Displays outputs of a stack defined in stacks directory",
+		Example: `  sc stack outputs -s billing -e production --format dotenv
+  sc stack outputs -s billing -e production --key db-url --reveal
+  sc stack outputs --all -e production --format yaml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SetContext(sCmd.Root.Logger.Silent(cmd.Context()))
-			if res, err := sCmd.Root.Provisioner.Outputs(cmd.Context(), sCmd.Params); err != nil {
-				return err
-			} else if j, err := json.Marshal(*res); err != nil {
+			var results []*api.OutputsResult
+			if oCmd.All {
+				if oCmd.Key != "" {
+					return errors.Errorf("--key cannot be used with --all")
+				}
+				res, err := sCmd.Root.Provisioner.OutputsAll(cmd.Context(), sCmd.Params)
+				if err != nil {
+					return err
+				}
+				results = res
+			} else if sCmd.Params.StackName == "" {
+				return errors.Errorf("stack must be specified with --stack (or use --all)")
+			} else if res, err := sCmd.Root.Provisioner.Outputs(cmd.Context(), sCmd.Params); err != nil {
 				return err
 			} else {
-				fmt.Println(string(j))
+				results = []*api.OutputsResult{res}
+			}
+			if !oCmd.Reveal {
+				results = lo.Map(results, func(res *api.OutputsResult, _ int) *api.OutputsResult {
+					return res.Masked()
+				})
+			}
+
+			var out string
+			var err error
+			if oCmd.Key != "" {
+				var value any
+				if value, err = results[0].Lookup(oCmd.Key); err != nil {
+					return err
+				}
+				out, err = api.FormatOutputValue(api.EnvVarName("", oCmd.Key), value, oCmd.Format)
+			} else {
+				out, err = api.FormatOutputs(results, oCmd.Format, oCmd.All)
+			}
+			if err != nil {
+				return err
 			}
+			fmt.Print(out)
 			return nil
 		},
 	}
+	cmd.Flags().StringVar(&oCmd.Format, "format", api.OutputsFormatJson, "Output format: `json`, `yaml`, `dotenv` or `shell`")
+	cmd.Flags().StringVar(&oCmd.Key, "key", oCmd.Key, "Print only the output (or nested key of the output) by path, e.g. `db-config.host`")
+	cmd.Flags().BoolVar(&oCmd.Reveal, "reveal", oCmd.Reveal, "Print values of secret outputs instead of masking them")
+	cmd.Flags().BoolVar(&oCmd.All, "all", oCmd.All, "Print outputs of every client stack configured for the environment")
 	return cmd
 }
@@ -27,6 +27,7 @@ func NewStackCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
 	cmd.AddCommand(
 		NewSecretGetCmd(&sCmd),
 		NewOutputsCmd(&sCmd),
+		NewUnlockCmd(&sCmd),
 	)
 
 	root_cmd.RegisterStackFlags(cmd, &sCmd.Params, true)
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_stack
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewUnlockCmd(sCmd *stackCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Releases lock of a stack left by an operation which is no longer running",
+		Example: `  sc stack unlock -s billing -e production
+  sc stack unlock -s infra`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lock, err := sCmd.Root.Provisioner.Unlock(cmd.Context(), sCmd.Params)
+			if err != nil {
+				return err
+			}
+			if lock == nil {
+				fmt.Printf("Stack %q is not locked\n", sCmd.Params.StackName)
+			} else {
+				fmt.Printf("Released lock of stack %q held by %s\n", sCmd.Params.StackName, lock)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
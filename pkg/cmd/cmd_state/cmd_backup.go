@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_state
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+const defaultKeepSnapshots = 10
+
+func NewBackupCmd(sCmd *stateCmd) *cobra.Command {
+	params := api.BackupStateParams{
+		Keep: defaultKeepSnapshots,
+	}
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Writes encrypted snapshot of states of a parent stack and its child stacks",
+		Long: "Exports states of the parent stack and of every child stack deployed into it and writes them as a\n" +
+			"timestamped snapshot to --location: a local directory or a bucket URL (s3://, gs://, file://).\n" +
+			"States are encrypted for all public keys allowed to decrypt secrets. Snapshots of the stack beyond\n" +
+			"--keep latest ones are deleted.",
+		Example: `  sc state backup -s devops --location ~/sc-backups
+  sc state backup -s devops --location s3://myproject-sc-backups?region=eu-central-1 --keep 30`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshot, err := sCmd.Root.Provisioner.BackupState(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Written snapshot %q of %d stacks\n", snapshot.ID, len(snapshot.Stacks))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.StackName, "stack", "s", params.StackName, "Parent stack to back up along with its child stacks (required)")
+	_ = cmd.MarkFlagRequired("stack")
+	cmd.Flags().StringVarP(&params.StacksDir, "dir", "d", params.StacksDir, "Root directory for stack configurations (default: .sc/stacks)")
+	registerLocationFlag(cmd, &params.Location)
+	cmd.Flags().IntVar(&params.Keep, "keep", params.Keep, "Number of the latest snapshots of the stack to keep (0 keeps all)")
+	return cmd
+}
+
+func registerLocationFlag(cmd *cobra.Command, location *string) {
+	cmd.Flags().StringVar(location, "location", *location, "Local directory or bucket URL (s3://, gs://, file://) of snapshots (required)")
+	_ = cmd.MarkFlagRequired("location")
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_state
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func NewMigrateCmd(sCmd *stateCmd) *cobra.Command {
+	params := api.MigrateStateParams{}
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copies states of parent stacks and their child stacks to the state storage of another profile",
+		Long: "Exports state of every parent stack and of every child stack deployed into it from the state storage\n" +
+			"configured for --from-profile, imports it into the state storage configured for --to-profile re-encrypting\n" +
+			"secrets with its secrets provider (aws-kms, gcp-kms or passphrase) and verifies the number of resources\n" +
+			"of every imported stack. Stacks which already have resources in the target are not overwritten without --force.",
+		Example: `  sc state migrate --from-profile old --to-profile new --dry-run
+  sc state migrate --from-profile old --to-profile new -s infra`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := sCmd.Root.Provisioner.MigrateState(cmd.Context(), params)
+			if report != nil {
+				PrintMigrationReport(report)
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&params.FromProfile, "from-profile", params.FromProfile, "Profile configuring the source state storage (required)")
+	cmd.Flags().StringVar(&params.ToProfile, "to-profile", params.ToProfile, "Profile configuring the target state storage (required)")
+	_ = cmd.MarkFlagRequired("from-profile")
+	_ = cmd.MarkFlagRequired("to-profile")
+	cmd.Flags().StringSliceVarP(&params.Stacks, "stack", "s", params.Stacks, "Parent stack to migrate along with its child stacks (can be repeated, default: all parent stacks)")
+	cmd.Flags().StringVarP(&params.StacksDir, "dir", "d", params.StacksDir, "Root directory for stack configurations (default: .sc/stacks)")
+	cmd.Flags().BoolVar(&params.DryRun, "dry-run", params.DryRun, "Only report stacks which would be migrated")
+	cmd.Flags().BoolVar(&params.Force, "force", params.Force, "Overwrite stacks which already have resources in the target state storage")
+	return cmd
+}
+
+// PrintMigrationReport prints migrated stacks as a table
+func PrintMigrationReport(report *api.StateMigrationReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STACK\tENVIRONMENT\tSOURCE\tTARGET\tSTATUS")
+	for _, stack := range report.Stacks {
+		env, status := stack.Environment, "migrated"
+		if env == "" {
+			env = "-"
+		}
+		switch {
+		case stack.Skipped != "":
+			status = "skipped: " + stack.Skipped
+		case report.DryRun:
+			status = "dry run"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", stack.Stack, env, stack.SourceResources, stack.TargetResources, status)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_state
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+func NewRestoreCmd(sCmd *stateCmd) *cobra.Command {
+	params := api.RestoreStateParams{}
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restores states of stacks from a snapshot written by `sc state backup`",
+		Long: "Compares states of stacks in the snapshot with their current states and shows resources which would be\n" +
+			"added back to, changed in or removed from the states. Unless --preview is set, states of changed stacks\n" +
+			"are replaced with the ones from the snapshot after confirmation; current states are backed up to a new snapshot\n" +
+			"in the same location first. Cloud resources themselves are not changed,\n" +
+			"run `sc provision` or `sc deploy` afterwards to reconcile them with the restored states.",
+		Example: `  sc state restore --location ~/sc-backups --snapshot devops-20261016T103005Z --preview
+  sc state restore --location ~/sc-backups --snapshot devops-20261016T103005Z`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			preview := params
+			preview.Preview = true
+			report, err := sCmd.Root.Provisioner.RestoreState(cmd.Context(), preview)
+			if err != nil {
+				return err
+			}
+			PrintRestoreReport(report)
+			changed := lo.CountBy(report.Stacks, func(s api.StackRestore) bool {
+				return s.HasChanges()
+			})
+			if params.Preview || changed == 0 {
+				return nil
+			}
+			if !yes {
+				fmt.Printf("Restore states of %d stacks from snapshot %q? [y/N]: ", changed, params.Snapshot)
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+					return errors.Errorf("restore is not confirmed")
+				}
+			}
+			restored, err := sCmd.Root.Provisioner.RestoreState(cmd.Context(), params)
+			if restored != nil && restored.Backup != nil {
+				fmt.Printf("Previous states are backed up to snapshot %q\n", restored.Backup.ID)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Restored states of %d stacks from snapshot %q\n", changed, params.Snapshot)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&params.Snapshot, "snapshot", params.Snapshot, "ID of the snapshot to restore (see `sc state snapshots`, required)")
+	_ = cmd.MarkFlagRequired("snapshot")
+	cmd.Flags().StringVarP(&params.StacksDir, "dir", "d", params.StacksDir, "Root directory for stack configurations (default: .sc/stacks)")
+	registerLocationFlag(cmd, &params.Location)
+	cmd.Flags().BoolVar(&params.Preview, "preview", params.Preview, "Only show changes of states without restoring them")
+	cmd.Flags().BoolVar(&yes, "yes", yes, "Restore without confirmation")
+	return cmd
+}
+
+// PrintRestoreReport prints changes of states of stacks restored from the snapshot
+func PrintRestoreReport(report *api.StateRestoreReport) {
+	fmt.Printf("Snapshot %q of %q taken at %s\n", report.Snapshot.ID, report.Snapshot.Stack, report.Snapshot.CreatedAt)
+	for _, stack := range report.Stacks {
+		name := stack.Stack
+		if stack.Environment != "" {
+			name = fmt.Sprintf("%s in %s", stack.Stack, stack.Environment)
+		}
+		switch {
+		case !stack.HasChanges():
+			fmt.Printf("  %s: no changes (%d resources)\n", name, stack.SnapshotResources)
+			continue
+		case stack.CurrentResources < 0:
+			fmt.Printf("  %s: %s\n", name, color.YellowFmt("stack does not exist, will be created with %d resources", stack.SnapshotResources))
+		default:
+			fmt.Printf("  %s: %d -> %d resources\n", name, stack.CurrentResources, stack.SnapshotResources)
+		}
+		for _, urn := range stack.Added {
+			fmt.Printf("    %s %s\n", color.GreenFmt("+"), urn)
+		}
+		for _, urn := range stack.Changed {
+			fmt.Printf("    %s %s\n", color.YellowFmt("~"), urn)
+		}
+		for _, urn := range stack.Removed {
+			fmt.Printf("    %s %s\n", color.RedFmt("-"), urn)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_state
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func NewSnapshotsCmd(sCmd *stateCmd) *cobra.Command {
+	var location, stackName string
+	cmd := &cobra.Command{
+		Use:     "snapshots",
+		Short:   "Lists snapshots of states written by `sc state backup`",
+		Example: `  sc state snapshots --location ~/sc-backups -s devops`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshots, err := sCmd.Root.Provisioner.StateSnapshots(cmd.Context(), location)
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "SNAPSHOT\tSTACK\tCREATED\tSTACKS\tRESOURCES")
+			for _, snapshot := range snapshots {
+				if stackName != "" && snapshot.Stack != stackName {
+					continue
+				}
+				resources := 0
+				for _, stack := range snapshot.Stacks {
+					resources += stack.Resources
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", snapshot.ID, snapshot.Stack, snapshot.CreatedAt.Format(time.RFC3339), len(snapshot.Stacks), resources)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVarP(&stackName, "stack", "s", stackName, "List only snapshots of the parent stack")
+	registerLocationFlag(cmd, &location)
+	return cmd
+}
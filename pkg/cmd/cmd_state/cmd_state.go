@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_state
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/cmd/root_cmd"
+)
+
+type stateCmd struct {
+	Root *root_cmd.RootCmd
+}
+
+func NewStateCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
+	sCmd := stateCmd{
+		Root: rootCmd,
+	}
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Manages states of stacks in state storages",
+	}
+
+	cmd.AddCommand(
+		NewMigrateCmd(&sCmd),
+		NewBackupCmd(&sCmd),
+		NewSnapshotsCmd(&sCmd),
+		NewRestoreCmd(&sCmd),
+	)
+	return cmd
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_up
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/cmd/root_cmd"
+)
+
+type upCmd struct {
+	Root   *root_cmd.RootCmd
+	Params api.UpParams
+}
+
+func NewUpCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
+	uCmd := upCmd{
+		Root: rootCmd,
+	}
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Runs stack locally with local stand-ins of the cloud resources it uses",
+		Long: "Runs services of the docker-compose file of a cloud-compose stack via Docker with env and secrets of the stack.\n" +
+			"Resources listed in `uses` are substituted with local containers (e.g. Postgres, Redis, MongoDB) providing the same env variables as in the cloud.",
+		Example: `  sc up -s billing -e local
+  sc up -s billing -e local --detach`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return uCmd.Root.Provisioner.Up(cmd.Context(), uCmd.Params)
+		},
+	}
+	root_cmd.RegisterStackFlags(cmd, &uCmd.Params.StackParams, false)
+	_ = cmd.MarkFlagRequired("env")
+	cmd.Flags().BoolVar(&uCmd.Params.Detach, "detach", uCmd.Params.Detach, "Leave stack running in background (remove it with `sc down`)")
+	cmd.Flags().BoolVar(&uCmd.Params.Pull, "pull", uCmd.Params.Pull, "Pull images even if they are present locally")
+	return cmd
+}
+
+func NewDownCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
+	dCmd := upCmd{
+		Root: rootCmd,
+	}
+	cmd := &cobra.Command{
+		Use:     "down",
+		Short:   "Removes stack started locally with `sc up --detach`",
+		Example: `  sc down -s billing -e local`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return dCmd.Root.Provisioner.Down(cmd.Context(), dCmd.Params.StackParams)
+		},
+	}
+	root_cmd.RegisterStackFlags(cmd, &dCmd.Params.StackParams, false)
+	_ = cmd.MarkFlagRequired("env")
+	return cmd
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package cmd_validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/cmd/root_cmd"
+)
+
+type validateCmd struct {
+	Root   *root_cmd.RootCmd
+	Params api.ValidateParams
+	Strict bool
+}
+
+func NewValidateCmd(rootCmd *root_cmd.RootCmd) *cobra.Command {
+	vCmd := validateCmd{
+		Root: rootCmd,
+	}
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validates stack configurations without calling the cloud",
+		Long: "Reads stack configurations and resolves their placeholders the same way as deploy does, reporting placeholders\n" +
+			"which cannot be resolved (e.g. missing secrets) or which are ambiguous (neither a known extension nor a value).\n" +
+			"Commands and secrets of external sources are not evaluated, such placeholders are reported as deferred.\n" +
+			"With --strict exits with non-zero code when any unresolved or ambiguous placeholder is found.",
+		Example: `  sc validate --strict
+  sc validate -s billing -e production --strict`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := vCmd.Root.Provisioner.Validate(cmd.Context(), vCmd.Params)
+			if err != nil {
+				return err
+			}
+			if report.Valid() {
+				fmt.Printf("All placeholders are resolved in %d stack(s): %s\n", len(report.Stacks), strings.Join(report.Stacks, ", "))
+				if len(report.References) > 0 {
+					fmt.Printf("%d placeholder(s) are only resolved on deploy:\n%s", len(report.References), report.String())
+				}
+				return nil
+			}
+			if vCmd.Strict {
+				return errors.Errorf("%d placeholder(s) cannot be resolved:\n%s", len(report.Unresolved()), report.String())
+			}
+			fmt.Printf("%d placeholder(s) cannot be resolved:\n%s", len(report.Unresolved()), report.String())
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&vCmd.Params.StackName, "stack", "s", vCmd.Params.StackName, "Stack name to validate (default: all stacks)")
+	cmd.Flags().StringVarP(&vCmd.Params.Environment, "env", "e", vCmd.Params.Environment, "Environment to validate client stacks for (default: all environments)")
+	cmd.Flags().StringVarP(&vCmd.Params.StacksDir, "dir", "d", vCmd.Params.StacksDir, "Root directory for stack configurations (default: .sc/stacks)")
+	cmd.Flags().BoolVar(&vCmd.Strict, "strict", vCmd.Strict, "Fail when any placeholder cannot be resolved")
+	return cmd
+}
@@ -125,28 +125,58 @@ func (c *RootCmd) Init(opts InitOpts) error {
 	return nil
 }
 
+const (
+	OutputFormatText = "text"
+	OutputFormatJson = "json"
+)
+
+// RegisterOutputFlag registers flag switching between human-readable logs and NDJSON stream of operation events
+func RegisterOutputFlag(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVar(output, "output", OutputFormatText, "Output format: `text` or `json` (NDJSON stream of operation events written to stdout)")
+}
+
+// OutputContext returns context for the requested output format: in json mode logs are suppressed
+// and provisioners write operation events to stdout instead
+func (c *RootCmd) OutputContext(ctx context.Context, output string) (context.Context, error) {
+	switch output {
+	case "", OutputFormatText:
+		return ctx, nil
+	case OutputFormatJson:
+		return c.Logger.Silent(api.WithOperationEvents(ctx, api.NewNDJSONEventSink(os.Stdout))), nil
+	default:
+		return ctx, errors.Errorf("unsupported output format %q, expected %q or %q", output, OutputFormatText, OutputFormatJson)
+	}
+}
+
+// RegisterDeployFlags leaves --stack optional, deploy checks that either --stack or --all is set
 func RegisterDeployFlags(cmd *cobra.Command, p *api.DeployParams) {
-	RegisterStackFlags(cmd, &p.StackParams, false)
+	registerStackFlags(cmd, &p.StackParams, false)
 	_ = cmd.MarkFlagRequired("env")
 	cmd.Flags().StringVarP(&p.Version, "deploy-version", "V", os.Getenv("VERSION"), "Deploy version (default: `latest`)")
 
 	cmd.Flags().StringVarP(&p.Timeouts.PreviewTimeout, "preview-timeout", "M", p.Timeouts.PreviewTimeout, "Timeout on preview operations (in Go's duration format, e.g. `20m`)")
 	cmd.Flags().StringVarP(&p.Timeouts.ExecutionTimeout, "execution-timeout", "O", p.Timeouts.ExecutionTimeout, "Timeout on whole command execution (in Go's duration format, e.g. `20m`)")
 	cmd.Flags().StringVarP(&p.Timeouts.DeployTimeout, "timeout", "T", p.Timeouts.DeployTimeout, "Timeout on deploy/provision operations (in Go's duration format, e.g. `20m`)")
+	cmd.Flags().StringVar(&p.Timeouts.LockTimeout, "lock-timeout", p.Timeouts.LockTimeout, "Time to wait for the stack locked by another operation (in Go's duration format, e.g. `10m`, default: fail immediately)")
 }
 
 func RegisterStackFlags(cmd *cobra.Command, p *api.StackParams, persistent bool) {
+	registerStackFlags(cmd, p, persistent)
+	_ = cmd.MarkFlagRequired("stack")
+}
+
+func registerStackFlags(cmd *cobra.Command, p *api.StackParams, persistent bool) {
 	flags := cmd.Flags()
 	if persistent {
 		flags = cmd.PersistentFlags()
 	}
 	flags.StringVarP(&p.Profile, "profile", "p", p.Profile, "Use profile (default: `default`)")
 	flags.StringVarP(&p.StackName, "stack", "s", p.StackName, "Stack name to deploy (required)")
-	_ = cmd.MarkFlagRequired("stack")
 	flags.StringVarP(&p.Environment, "env", "e", p.Environment, "Environment to deploy")
 	flags.StringVarP(&p.StacksDir, "dir", "d", p.StacksDir, "Root directory for stack configurations (default: .sc/stacks)")
 	cmd.Flags().BoolVarP(&p.SkipRefresh, "skip-refresh", "R", p.SkipRefresh, "Skip refresh before deploy")
 	cmd.Flags().BoolVarP(&p.SkipPreview, "skip-preview", "S", p.SkipPreview, "Skip preview before deploy")
+	cmd.Flags().StringSliceVar(&p.OverridePolicies, "override-policy", p.OverridePolicies, "Proceed despite violations of the named policy (can be repeated)")
 	cmd.Flags().BoolVarP(&p.DetailedDiff, "diff", "D", p.DetailedDiff, "Show detailed diff with granular changes for nested properties (e.g., redisConfigs)")
 	cmd.Flags().BoolVar(&p.DetailedDiff, "detailed-diff", p.DetailedDiff, "Alias for --diff")
 	_ = cmd.Flags().MarkHidden("detailed-diff") // Hide the alias from help output
@@ -282,9 +282,11 @@ func (e *Executor) executeDeploy(ctx context.Context, config OperationConfig, pr
 		e.logger.Info(ctx, "Simple Container CLI version: %s", "latest")
 		e.logger.Info(ctx, "Deploy version: %s", config.Version)
 
-		if _, err := e.provisioner.Preview(ctx, deployParams); err != nil {
+		res, err := e.provisioner.Preview(ctx, deployParams)
+		if err != nil {
 			return fmt.Errorf("deployment preview failed: %w", err)
 		}
+		e.commentPreview(ctx, config, res)
 		e.logger.Info(ctx, "✅ Preview completed - no actual deployment performed")
 	} else {
 		e.logger.Info(ctx, "🚀 Executing ACTUAL deployment (changes will be applied)...")
@@ -327,9 +329,11 @@ func (e *Executor) executeProvision(ctx context.Context, config OperationConfig,
 		e.logger.Info(ctx, "🔍 Executing provisioning in PREVIEW MODE (no real changes will be made)...")
 		e.logger.Info(ctx, "Simple Container CLI version: %s", "latest")
 
-		if _, err := e.provisioner.PreviewProvision(ctx, provisionParams); err != nil {
+		res, err := e.provisioner.PreviewProvision(ctx, provisionParams)
+		if err != nil {
 			return fmt.Errorf("provisioning preview failed: %w", err)
 		}
+		e.commentPreview(ctx, config, res...)
 		e.logger.Info(ctx, "✅ Preview completed - no actual provisioning performed")
 	} else {
 		e.logger.Info(ctx, "🚀 Executing ACTUAL provisioning (changes will be applied)...")
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+// maxCommentLength is slightly lower than the limit of GitHub on comment body
+const maxCommentLength = 65000
+
+type issueComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// commentPreview renders preview results as a comment on the pull request which triggered the workflow.
+// Comment is updated on subsequent runs for the same stack, failures are only logged.
+func (e *Executor) commentPreview(ctx context.Context, config OperationConfig, results ...*api.PreviewResult) {
+	if os.Getenv("SC_PREVIEW_COMMENT") == "false" {
+		return
+	}
+	prNumber := e.pullRequestNumber()
+	token := os.Getenv("GITHUB_TOKEN")
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	if prNumber == 0 || token == "" || repository == "" {
+		e.logger.Debug(ctx, "Not a pull request or no GITHUB_TOKEN available, skipping preview comment")
+		return
+	}
+
+	marker := fmt.Sprintf("<!-- simple-container-preview:%s:%s -->", config.StackName, config.Env)
+	body := marker + "\n" + previewCommentBody(config, results)
+
+	if err := e.upsertPullRequestComment(ctx, repository, prNumber, token, marker, body); err != nil {
+		e.logger.Warn(ctx, "⚠️ Failed to comment preview on pull request #%d: %v", prNumber, err)
+		return
+	}
+	e.logger.Info(ctx, "💬 Preview posted to pull request #%d", prNumber)
+}
+
+func previewCommentBody(config OperationConfig, results []*api.PreviewResult) string {
+	res := strings.Builder{}
+	res.WriteString(fmt.Sprintf("## Simple Container %s preview for `%s`", config.Type, config.StackName))
+	if config.Env != "" {
+		res.WriteString(fmt.Sprintf(" in `%s`", config.Env))
+	}
+	res.WriteString("\n\n")
+	for _, result := range results {
+		if result != nil {
+			res.WriteString(result.Markdown())
+			res.WriteString("\n")
+		}
+	}
+	return truncateComment(res.String(), maxCommentLength)
+}
+
+// truncateComment cuts body to at most limit bytes at the last complete line, so that neither a table row
+// nor a multibyte character is split
+func truncateComment(body string, limit int) string {
+	if len(body) <= limit {
+		return body
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	if lastLine := strings.LastIndex(body[:cut], "\n"); lastLine > 0 {
+		cut = lastLine + 1
+	}
+	return body[:cut] + "\n_Preview is truncated, see workflow logs for the full plan._\n"
+}
+
+// pullRequestNumber returns number of the pull request the workflow runs for (0 if none)
+func (e *Executor) pullRequestNumber() int {
+	if eventPath := os.Getenv("GITHUB_EVENT_PATH"); eventPath != "" {
+		if content, err := os.ReadFile(eventPath); err == nil {
+			var event struct {
+				PullRequest *struct {
+					Number int `json:"number"`
+				} `json:"pull_request"`
+			}
+			if json.Unmarshal(content, &event) == nil && event.PullRequest != nil {
+				return event.PullRequest.Number
+			}
+		}
+	}
+	// refs/pull/<number>/merge
+	var number int
+	if _, err := fmt.Sscanf(os.Getenv("GITHUB_REF"), "refs/pull/%d/merge", &number); err == nil {
+		return number
+	}
+	return 0
+}
+
+func (e *Executor) upsertPullRequestComment(ctx context.Context, repository string, prNumber int, token, marker, body string) error {
+	apiURL := strings.TrimSuffix(os.Getenv("GITHUB_API_URL"), "/")
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	commentsURL := fmt.Sprintf("%s/repos/%s/issues/%d/comments", apiURL, repository, prNumber)
+
+	var existing []issueComment
+	if err := githubRequest(ctx, http.MethodGet, commentsURL+"?per_page=100", token, nil, &existing); err != nil {
+		return fmt.Errorf("failed to list comments: %w", err)
+	}
+	payload := map[string]string{"body": body}
+	for _, comment := range existing {
+		if strings.HasPrefix(comment.Body, marker) {
+			return githubRequest(ctx, http.MethodPatch, fmt.Sprintf("%s/repos/%s/issues/comments/%d", apiURL, repository, comment.ID), token, payload, nil)
+		}
+	}
+	return githubRequest(ctx, http.MethodPost, commentsURL, token, payload, nil)
+}
+
+func githubRequest(ctx context.Context, method, url, token string, payload any, result any) error {
+	var reqBody io.Reader
+	if payload != nil {
+		j, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(j)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s returned %d: %s", method, url, resp.StatusCode, string(respBody))
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package actions
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func TestPreviewCommentBody(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		config   OperationConfig
+		results  []*api.PreviewResult
+		contains []string
+		excludes []string
+	}{
+		{
+			name:   "per-resource plan",
+			config: OperationConfig{Type: OperationDeploy, StackName: "billing", Env: "production"},
+			results: []*api.PreviewResult{{
+				StackName:  "billing--production",
+				Operations: map[string]int{"update": 1, "replace": 1},
+				Steps: []api.PreviewStep{
+					{URN: "urn:db", Type: "aws:rds/instance:Instance", Op: api.PreviewOpReplace, Properties: []string{"storageEncrypted"}, ReplaceKeys: []string{"storageEncrypted"}, ForcedReplace: true},
+					{URN: "urn:svc", Type: "aws:ecs/service:Service", Op: api.PreviewOpUpdate, Properties: []string{"desiredCount"}},
+				},
+			}},
+			contains: []string{
+				"## Simple Container deploy preview for `billing` in `production`",
+				"### Preview of `billing--production`",
+				"| **replace** :warning: | `aws:rds/instance:Instance` | `urn:db` | `storageEncrypted` (forces replace) |",
+				"| update | `aws:ecs/service:Service` | `urn:svc` | `desiredCount` |",
+			},
+			excludes: []string{"truncated"},
+		},
+		{
+			name:     "no environment and nil results",
+			config:   OperationConfig{Type: OperationProvision, StackName: "infrastructure"},
+			results:  []*api.PreviewResult{nil, {StackName: "infrastructure"}},
+			contains: []string{"## Simple Container provision preview for `infrastructure`\n\n", "No changes planned."},
+			excludes: []string{" in `"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+			body := previewCommentBody(tc.config, tc.results)
+			for _, s := range tc.contains {
+				Expect(body).To(ContainSubstring(s))
+			}
+			for _, s := range tc.excludes {
+				Expect(body).ToNot(ContainSubstring(s))
+			}
+		})
+	}
+}
+
+func TestPreviewCommentBody_Truncated(t *testing.T) {
+	RegisterTestingT(t)
+
+	result := &api.PreviewResult{StackName: "infra--production", Operations: map[string]int{"update": 5000}}
+	for i := 0; i < 5000; i++ {
+		result.Steps = append(result.Steps, api.PreviewStep{URN: "urn:resource-ключ", Type: "aws:s3/bucket:Bucket", Op: api.PreviewOpUpdate, Properties: []string{"tags"}})
+	}
+	body := previewCommentBody(OperationConfig{Type: OperationDeploy, StackName: "infra"}, []*api.PreviewResult{result})
+
+	Expect(utf8.ValidString(body)).To(BeTrue())
+	Expect(body).To(HaveSuffix("\n_Preview is truncated, see workflow logs for the full plan._\n"))
+	lastRow := strings.TrimSuffix(body, "\n_Preview is truncated, see workflow logs for the full plan._\n")
+	Expect(len(lastRow)).To(BeNumerically("<=", maxCommentLength))
+	Expect(lastRow).To(HaveSuffix("| `tags` |\n"))
+}
+
+func TestTruncateComment(t *testing.T) {
+	const notice = "\n_Preview is truncated, see workflow logs for the full plan._\n"
+	for _, tc := range []struct {
+		name  string
+		body  string
+		limit int
+		want  string
+	}{
+		{
+			name:  "short body is kept",
+			body:  "line\n",
+			limit: 10,
+			want:  "line\n",
+		},
+		{
+			name:  "cut at last complete line",
+			body:  "first\nsecond\nthird\n",
+			limit: 15,
+			want:  "first\nsecond\n" + notice,
+		},
+		{
+			name:  "multibyte character is not split without line break",
+			body:  "ключ",
+			limit: 3,
+			want:  "к" + notice,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+			Expect(truncateComment(tc.body, tc.limit)).To(Equal(tc.want))
+		})
+	}
+}
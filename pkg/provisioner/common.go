@@ -30,10 +30,24 @@ type Provisioner interface {
 	PreviewProvision(ctx context.Context, params api.ProvisionParams) ([]*api.PreviewResult, error)
 	Provision(ctx context.Context, params api.ProvisionParams) error
 	Deploy(ctx context.Context, params api.DeployParams) error
+	DeployAll(ctx context.Context, params api.DeployAllParams) ([]api.StackDeployResult, error)
+	Promote(ctx context.Context, params api.PromoteParams) (*api.UpdateResult, error)
+	Rollback(ctx context.Context, params api.RollbackParams) (*api.UpdateResult, error)
+	History(ctx context.Context, params api.StackParams) ([]api.StackHistoryEntry, error)
 	Preview(ctx context.Context, params api.DeployParams) (*api.PreviewResult, error)
+	Up(ctx context.Context, params api.UpParams) error
+	Down(ctx context.Context, params api.StackParams) error
 
 	Outputs(ctx context.Context, params api.StackParams) (*api.OutputsResult, error)
+	OutputsAll(ctx context.Context, params api.StackParams) ([]*api.OutputsResult, error)
+	Drift(ctx context.Context, params api.StackParams) (*api.DriftReport, error)
+	Validate(ctx context.Context, params api.ValidateParams) (*api.ValidationReport, error)
 	Cancel(ctx context.Context, params api.StackParams) error
+	Unlock(ctx context.Context, params api.StackParams) (*api.StackLock, error)
+	MigrateState(ctx context.Context, params api.MigrateStateParams) (*api.StateMigrationReport, error)
+	BackupState(ctx context.Context, params api.BackupStateParams) (*api.StateSnapshot, error)
+	StateSnapshots(ctx context.Context, location string) ([]api.StateSnapshot, error)
+	RestoreState(ctx context.Context, params api.RestoreStateParams) (*api.StateRestoreReport, error)
 	CancelParent(ctx context.Context, params api.StackParams) error
 	Stacks() api.StacksMap
 
@@ -63,6 +77,7 @@ type provisioner struct {
 	gitRepo             git.Repo
 	cryptor             secrets.Cryptor
 	phResolver          placeholders.Placeholders
+	phOpts              []placeholders.Option // set when stacks are read
 	log                 logger.Logger
 	overrideProvisioner api.Provisioner
 }
@@ -100,6 +115,23 @@ func New(opts ...Option) (Provisioner, error) {
 	return res, nil
 }
 
+// forStack returns a provisioner sharing repo, cryptor and resolvers with p, but with its own
+// stacks state, so that several stacks can be prepared and deployed concurrently
+func (p *provisioner) forStack() *provisioner {
+	return &provisioner{
+		projectName:         p.projectName,
+		rootDir:             p.rootDir,
+		profile:             p.profile,
+		stacks:              make(api.StacksMap),
+		context:             p.context,
+		gitRepo:             p.gitRepo,
+		cryptor:             p.cryptor,
+		phResolver:          p.phResolver,
+		log:                 p.log,
+		overrideProvisioner: p.overrideProvisioner,
+	}
+}
+
 func (p *provisioner) Stacks() api.StacksMap {
 	return p.stacks
 }
@@ -349,7 +349,7 @@ func Test_Deploy(t *testing.T) {
 			pulumiMock := pulumi_mocks.NewPulumiMock(t)
 			if tt.setExpectations {
 				pulumiMock.On("DeployStack", ctx, mock.Anything, mock.Anything, mock.Anything).
-					Return(nil)
+					Return(&api.UpdateResult{}, nil)
 				pulumiMock.On("SetPublicKey", mock.Anything).Return()
 			}
 			p, err = New(
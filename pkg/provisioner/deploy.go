@@ -17,9 +17,14 @@ import (
 func (p *provisioner) Deploy(ctx context.Context, params api.DeployParams) error {
 	p.logWelcome(ctx, &params)
 
+	_, err := p.deploy(ctx, params)
+	return err
+}
+
+func (p *provisioner) deploy(ctx context.Context, params api.DeployParams) (*api.UpdateResult, error) {
 	cfg, stack, pv, err := p.prepareForChildStack(ctx, &params.StackParams)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	return pv.DeployStack(ctx, cfg, *stack, params)
 }
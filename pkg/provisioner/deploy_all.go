@@ -0,0 +1,263 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+// DeployAll deploys every client stack configured for params.Environment in the order
+// defined by their dependencies, running independent stacks concurrently
+func (p *provisioner) DeployAll(ctx context.Context, params api.DeployAllParams) ([]api.StackDeployResult, error) {
+	p.logWelcome(ctx, &params.DeployParams)
+
+	if params.Environment == "" {
+		return nil, errors.Errorf("environment must be specified")
+	}
+
+	cfg, err := api.ReadConfigFile(p.rootDir, p.profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file for profile %q", p.profile)
+	}
+	if err := p.ReadStacks(ctx, cfg, api.ProvisionParams{
+		StacksDir: params.StacksDir,
+		Profile:   params.Profile,
+	}, api.ReadIgnoreNoAnyCfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to read stacks")
+	}
+
+	graph, err := buildDeployGraph(p.stacks, params.Environment)
+	if err != nil {
+		return nil, err
+	}
+	if len(graph.nodes) == 0 {
+		return nil, errors.Errorf("no client stacks are configured for environment %q", params.Environment)
+	}
+	p.log.Info(ctx, "%s", color.GreenFmt("deploying %d stacks to %q: [%s]", len(graph.nodes), params.Environment, strings.Join(graph.nodes, ", ")))
+
+	results := graph.run(ctx, params.Concurrency, func(ctx context.Context, stackName string) (*api.UpdateResult, error) {
+		stackParams := params.DeployParams
+		stackParams.StackName = stackName
+		p.log.Info(ctx, "%s", color.GreenFmt("deploying stack %q to %q...", stackName, params.Environment))
+		return p.forStack().deploy(ctx, stackParams)
+	})
+
+	failed := lo.Filter(results, func(r api.StackDeployResult, _ int) bool {
+		return r.Status != api.StackDeployStatusSucceeded
+	})
+	if len(failed) > 0 {
+		return results, errors.Errorf("%d of %d stacks were not deployed: [%s]", len(failed), len(results),
+			strings.Join(lo.Map(failed, func(r api.StackDeployResult, _ int) string { return r.StackName }), ", "))
+	}
+	return results, nil
+}
+
+type deployGraph struct {
+	nodes []string            // stacks to deploy, sorted by name
+	deps  map[string][]string // stack -> stacks it must be deployed after
+}
+
+// buildDeployGraph collects client stacks configured for env and links them to the stacks they
+// depend on: owners of `dependencies`, the parent stack and the stacks declaring resources from `uses`,
+// as long as those are deployed within the same environment too
+func buildDeployGraph(stacks api.StacksMap, env string) (*deployGraph, error) {
+	g := &deployGraph{
+		deps: make(map[string][]string),
+	}
+	for stackName, stack := range stacks {
+		if _, ok := stack.Client.Stacks[env]; ok {
+			g.nodes = append(g.nodes, stackName)
+		}
+	}
+	sort.Strings(g.nodes)
+
+	for _, stackName := range g.nodes {
+		clientDesc := stacks[stackName].Client.Stacks[env]
+		parentEnv := lo.If(clientDesc.ParentEnv != "", clientDesc.ParentEnv).Else(env)
+		parentName := collapseStackName(clientDesc.ParentStack)
+
+		var deps []string
+		addDep := func(dep string) {
+			if dep != "" && dep != stackName && lo.Contains(g.nodes, dep) && !lo.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+		addDep(parentName)
+
+		uses, dependencies := clientStackReferences(clientDesc)
+		for _, dep := range dependencies {
+			addDep(collapseStackName(dep.Owner))
+		}
+		for _, resName := range uses {
+			// resources are looked up in the parent first, so the parent (if deployed) wins
+			if _, declaredInParent := stacks[parentName].Server.Resources.Resources[parentEnv].Resources[resName]; declaredInParent {
+				continue
+			}
+			for _, candidate := range g.nodes {
+				if _, declared := stacks[candidate].Server.Resources.Resources[parentEnv].Resources[resName]; declared {
+					addDep(candidate)
+				}
+			}
+		}
+		sort.Strings(deps)
+		g.deps[stackName] = deps
+	}
+
+	if cycle := g.findCycle(); len(cycle) > 0 {
+		return nil, errors.Errorf("stacks in %q have circular dependencies: %s", env, strings.Join(cycle, " -> "))
+	}
+	return g, nil
+}
+
+func (g *deployGraph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(node string) []string
+	visit = func(node string) []string {
+		state[node] = visiting
+		path = append(path, node)
+		for _, dep := range g.deps[node] {
+			switch state[dep] {
+			case visiting:
+				return append(path[lo.IndexOf(path, dep):], dep)
+			case unvisited:
+				if cycle := visit(dep); len(cycle) > 0 {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+		return nil
+	}
+	for _, node := range g.nodes {
+		if state[node] == unvisited {
+			if cycle := visit(node); len(cycle) > 0 {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+type deployFunc func(ctx context.Context, stackName string) (*api.UpdateResult, error)
+
+// run deploys stacks of the graph with at most `concurrency` deploys at a time.
+// A stack is started only once all its dependencies succeeded, otherwise it is skipped.
+// Results are returned in the order stacks have completed.
+func (g *deployGraph) run(ctx context.Context, concurrency int, deploy deployFunc) []api.StackDeployResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	pending := make(map[string]int, len(g.nodes))
+	dependants := make(map[string][]string)
+	var ready []string
+	for _, node := range g.nodes {
+		pending[node] = len(g.deps[node])
+		for _, dep := range g.deps[node] {
+			dependants[dep] = append(dependants[dep], node)
+		}
+		if pending[node] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	statuses := make(map[string]api.StackDeployStatus, len(g.nodes))
+	results := make([]api.StackDeployResult, 0, len(g.nodes))
+	done := make(chan api.StackDeployResult)
+	running := 0
+
+	complete := func(res api.StackDeployResult) {
+		statuses[res.StackName] = res.Status
+		results = append(results, res)
+		for _, next := range dependants[res.StackName] {
+			pending[next]--
+			if pending[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	for len(ready) > 0 || running > 0 {
+		for len(ready) > 0 && running < concurrency {
+			stackName := ready[0]
+			ready = ready[1:]
+
+			blockedBy := lo.Filter(g.deps[stackName], func(dep string, _ int) bool {
+				return statuses[dep] != api.StackDeployStatusSucceeded
+			})
+			if len(blockedBy) > 0 {
+				complete(api.StackDeployResult{
+					StackName: stackName,
+					Status:    api.StackDeployStatusSkipped,
+					Error:     "upstream stacks were not deployed",
+					BlockedBy: blockedBy,
+				})
+				continue
+			}
+			if ctx.Err() != nil {
+				complete(api.StackDeployResult{
+					StackName: stackName,
+					Status:    api.StackDeployStatusSkipped,
+					Error:     ctx.Err().Error(),
+				})
+				continue
+			}
+
+			running++
+			go func(stackName string) {
+				startedAt := time.Now()
+				updateRes, err := deploy(ctx, stackName)
+				res := api.StackDeployResult{
+					StackName: stackName,
+					Status:    api.StackDeployStatusSucceeded,
+					Duration:  time.Since(startedAt),
+					Update:    updateRes,
+				}
+				if err != nil {
+					res.Status = api.StackDeployStatusFailed
+					res.Error = err.Error()
+				}
+				done <- res
+			}(stackName)
+		}
+		if running > 0 {
+			res := <-done
+			running--
+			complete(res)
+		}
+	}
+	return results
+}
+
+func clientStackReferences(clientDesc api.StackClientDescriptor) ([]string, []api.StackConfigDependencyResource) {
+	switch cfg := clientDesc.Config.Config.(type) {
+	case *api.StackConfigCompose:
+		return cfg.Uses, cfg.Dependencies
+	case *api.StackConfigSingleImage:
+		return cfg.Uses, cfg.Dependencies
+	default:
+		return nil, nil
+	}
+}
+
+// collapseStackName returns stack name from the stack reference in form of [[<org>/]<project>/]<stack>
+func collapseStackName(stackRef string) string {
+	parts := strings.SplitN(stackRef, "/", 3)
+	return parts[len(parts)-1]
+}
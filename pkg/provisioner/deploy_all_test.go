@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func clientStack(env string, parent string, cfg any) api.Stack {
+	return api.Stack{
+		Client: api.ClientDescriptor{
+			Stacks: map[string]api.StackClientDescriptor{
+				env: {
+					Type:        api.ClientTypeCloudCompose,
+					ParentStack: parent,
+					Config:      api.Config{Config: cfg},
+				},
+			},
+		},
+	}
+}
+
+func Test_buildDeployGraph(t *testing.T) {
+	RegisterTestingT(t)
+
+	stacks := api.StacksMap{
+		"infra": {
+			Server: api.ServerDescriptor{
+				Resources: api.PerStackResourcesDescriptor{
+					Resources: map[string]api.PerEnvResourcesDescriptor{
+						"prod": {Resources: map[string]api.ResourceDescriptor{"db": {Type: "aws-rds-postgres"}}},
+					},
+				},
+			},
+		},
+		"queue": func() api.Stack {
+			s := clientStack("prod", "myproject/infra", &api.StackConfigCompose{})
+			s.Server.Resources.Resources = map[string]api.PerEnvResourcesDescriptor{
+				"prod": {Resources: map[string]api.ResourceDescriptor{"events": {Type: "aws-sqs-sns"}}},
+			}
+			return s
+		}(),
+		"billing": clientStack("prod", "infra", &api.StackConfigCompose{
+			Uses: []string{"db"},
+		}),
+		"api": clientStack("prod", "infra", &api.StackConfigCompose{
+			Uses: []string{"db", "events"},
+			Dependencies: []api.StackConfigDependencyResource{
+				{Name: "billing-db", Owner: "myproject/billing", Resource: "db"},
+			},
+		}),
+		"staging-only": clientStack("staging", "infra", &api.StackConfigCompose{}),
+	}
+
+	g, err := buildDeployGraph(stacks, "prod")
+	Expect(err).To(BeNil())
+	Expect(g.nodes).To(Equal([]string{"api", "billing", "queue"}))
+	Expect(g.deps["api"]).To(Equal([]string{"billing", "queue"}))
+	Expect(g.deps["billing"]).To(BeEmpty())
+	Expect(g.deps["queue"]).To(BeEmpty())
+
+	stacks["billing"] = clientStack("prod", "infra", &api.StackConfigSingleImage{
+		Dependencies: []api.StackConfigDependencyResource{{Owner: "api", Resource: "db"}},
+	})
+	_, err = buildDeployGraph(stacks, "prod")
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("circular dependencies: api -> billing -> api"))
+}
+
+func Test_deployGraphRun(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := &deployGraph{
+		nodes: []string{"a", "b", "c", "d", "e"},
+		deps: map[string][]string{
+			"b": {"a"},
+			"c": {"b"},
+			"d": {"a"},
+		},
+	}
+
+	var lock sync.Mutex
+	var deployed []string
+	results := g.run(context.Background(), 2, func(ctx context.Context, stackName string) (*api.UpdateResult, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		deployed = append(deployed, stackName)
+		if stackName == "b" {
+			return nil, errors.New("boom")
+		}
+		return &api.UpdateResult{StackName: stackName}, nil
+	})
+
+	byName := lo.KeyBy(results, func(r api.StackDeployResult) string { return r.StackName })
+	Expect(byName).To(HaveLen(5))
+	Expect(byName["a"].Status).To(Equal(api.StackDeployStatusSucceeded))
+	Expect(byName["a"].Update.StackName).To(Equal("a"))
+	Expect(byName["b"].Status).To(Equal(api.StackDeployStatusFailed))
+	Expect(byName["b"].Error).To(Equal("boom"))
+	Expect(byName["c"].Status).To(Equal(api.StackDeployStatusSkipped))
+	Expect(byName["c"].BlockedBy).To(Equal([]string{"b"}))
+	Expect(byName["d"].Status).To(Equal(api.StackDeployStatusSucceeded))
+	Expect(byName["e"].Status).To(Equal(api.StackDeployStatusSucceeded))
+	Expect(deployed).NotTo(ContainElement("c"))
+	Expect(lo.IndexOf(deployed, "a")).To(BeNumerically("<", lo.IndexOf(deployed, "b")))
+}
+
+func Test_deployGraphRunCanceled(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := &deployGraph{
+		nodes: []string{"a", "b"},
+		deps:  map[string][]string{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := g.run(ctx, 1, func(ctx context.Context, stackName string) (*api.UpdateResult, error) {
+		return &api.UpdateResult{}, nil
+	})
+	Expect(results).To(HaveLen(2))
+	for _, r := range results {
+		Expect(r.Status).To(Equal(api.StackDeployStatusSkipped))
+	}
+}
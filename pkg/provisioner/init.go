@@ -9,4 +9,5 @@ import (
 	_ "github.com/simple-container-com/api/pkg/clouds/github"
 	_ "github.com/simple-container-com/api/pkg/clouds/mongodb"
 	_ "github.com/simple-container-com/api/pkg/clouds/pulumi"
+	_ "github.com/simple-container-com/api/pkg/clouds/vault"
 )
@@ -4,11 +4,15 @@
 package placeholders
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	os "os"
 	"os/user"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -29,7 +33,7 @@ type Placeholders interface {
 	Apply(obj any, opts ...Option) error
 
 	// Resolve resolves all placeholders (like ${auth:<something}) in a stack map
-	Resolve(stacks api.StacksMap) error
+	Resolve(stacks api.StacksMap, opts ...Option) error
 }
 
 type (
@@ -41,6 +45,10 @@ type InitOption func(p *placeholders)
 
 type placeholders struct {
 	git git.Repo
+
+	secretsLock sync.Mutex
+	// secretsCache keeps secrets read from external sources, so every secret is read once per run
+	secretsCache map[string]string
 }
 
 func WithExtensions(extensions map[string]template.Extension) Option {
@@ -55,6 +63,41 @@ func WithData(data MapData) Option {
 	}
 }
 
+// WithStacksDir restricts ${file:...} placeholders to stacks dir,
+// relative paths are resolved against the dir of the stack being resolved
+func WithStacksDir(stacksDir string) Option {
+	return func(tpl *template.Template) {
+		tpl.WithRootDir(stacksDir)
+	}
+}
+
+// WithCommands allows ${cmd:...} placeholders
+func WithCommands(allowed bool) Option {
+	return func(tpl *template.Template) {
+		tpl.WithCommands(allowed)
+	}
+}
+
+// WithDryRun makes Apply and Resolve to report placeholders which cannot be resolved instead of modifying objects,
+// location of reference is YAML path of the string within the object (prefixed with stack name for Resolve)
+func WithDryRun(report func(ref template.Reference)) Option {
+	return func(tpl *template.Template) {
+		tpl.WithReporter(report)
+	}
+}
+
+func withLocation(location string) Option {
+	return func(tpl *template.Template) {
+		tpl.WithLocation(location)
+	}
+}
+
+func withWorkDir(stackName string) Option {
+	return func(tpl *template.Template) {
+		tpl.WithWorkDir(stackName)
+	}
+}
+
 func WithGitRepo(gitRepo git.Repo) InitOption {
 	return func(p *placeholders) {
 		p.git = gitRepo
@@ -65,28 +108,44 @@ func (p *placeholders) Apply(obj any, opts ...Option) error {
 	return p.applyTemplatesOnObject(obj, opts)
 }
 
-func (p *placeholders) Resolve(stacks api.StacksMap) error {
+func (p *placeholders) Resolve(stacks api.StacksMap, resolveOpts ...Option) error {
 	stacks = *stacks.ResolveInheritance()
 	iterStacks := lo.Assign(stacks)
+	dryRun := p.initTemplate(resolveOpts).HasReporter()
+	var strictErrs []string
 	for stackName, stack := range iterStacks {
+		extensions := map[string]template.Extension{
+			"env":     lo.Ternary(dryRun, p.extEnvDryRun, p.extEnv),
+			"git":     p.tplGit(stackName),
+			"project": p.tplProject(stackName), // Added project extension
+			"date":    p.extDate,               // Added date extension
+			"auth":    p.tplAuth(stackName, stack, stacks),
+			"secret":  p.tplSecrets(stackName, stack, stacks),
+			"var":     p.tplVars(stackName, stack, stacks),
+			"stack":   p.tplStack(stackName, stack, stacks),
+			"user":    p.tplUser,
+		}
+		// configs of secret sources may only refer to secrets of the repository
+		sourceOpts := append([]Option{withWorkDir(stackName), WithExtensions(lo.Assign(extensions))}, resolveOpts...)
+		sourceOpts = append(sourceOpts, WithDryRun(nil))
+		extensions["secret"] = p.tplExternalSecrets(stackName, stack, extensions["secret"], dryRun, sourceOpts, func(err error) {
+			strictErrs = append(strictErrs, err.Error())
+		})
+
 		opts := []Option{
-			WithExtensions(map[string]template.Extension{
-				"env":     p.extEnv,
-				"git":     p.tplGit(stackName),
-				"project": p.tplProject(stackName), // Added project extension
-				"date":    p.extDate,               // Added date extension
-				"auth":    p.tplAuth(stackName, stack, stacks),
-				"secret":  p.tplSecrets(stackName, stack, stacks),
-				"var":     p.tplVars(stackName, stack, stacks),
-				"stack":   p.tplStack(stackName, stack, stacks),
-				"user":    p.tplUser,
-			}),
-		}
-		if err := p.Apply(&stack, opts...); err != nil {
+			withWorkDir(stackName),
+			withLocation(stackName),
+			WithExtensions(extensions),
+		}
+		if err := p.Apply(&stack, append(opts, resolveOpts...)...); err != nil {
 			return err
 		}
 		stacks[stackName] = stack
 	}
+	if len(strictErrs) > 0 && !dryRun {
+		sort.Strings(strictErrs)
+		return errors.Errorf("failed to read secrets from strict secret sources:\n%s", strings.Join(lo.Uniq(strictErrs), "\n"))
+	}
 	return nil
 }
 
@@ -137,7 +196,7 @@ func (p *placeholders) extDate(noSubstitution, path string, defaultValue *string
 		res = fmt.Sprintf("%02d", t.Second())
 	default:
 		if defaultValue != nil {
-			return *defaultValue, nil
+			return template.DefaultValue(*defaultValue)
 		}
 		return noSubstitution, errors.Errorf("unknown date format %q (available: time, dateOnly, timestamp, iso8601, rfc3339, year, month, day, hour, minute, second)", path)
 	}
@@ -186,14 +245,84 @@ func (p *placeholders) tplSecrets(stackName string, stack api.Stack, stacks api.
 	}
 }
 
+// tplExternalSecrets resolves ${secret:<source>://<path>#<key>} placeholders with secret sources declared in server.yaml
+// or the store of `secrets` descriptor of the <source> type, names of repository secrets are resolved with repoSecrets.
+// Sources are not read in dry run, only their declaration is checked
+func (p *placeholders) tplExternalSecrets(stackName string, stack api.Stack, repoSecrets template.Extension, dryRun bool, sourceOpts []Option, strictErr func(err error)) func(source string, path string, value *string) (string, error) {
+	return func(noSubs, path string, value *string) (string, error) {
+		ref, external := api.ParseSecretRef(path)
+		if !external {
+			return repoSecrets(noSubs, path, value)
+		}
+		source, found := stack.Server.SecretSources[ref.Source]
+		if !found && stack.Server.Secrets.Type != "" && stack.Server.Secrets.Type == ref.Source {
+			source, found = api.SecretSourceDescriptor{Type: stack.Server.Secrets.Type, Config: stack.Server.Secrets.Config}, true
+		}
+		if !found {
+			return noSubs, errors.Errorf("secret source %q is not declared in stack %q", ref.Source, stackName)
+		}
+		if dryRun {
+			return noSubs, &template.DeferredError{Reason: fmt.Sprintf("secret source %q is not read in dry run", ref.Source)}
+		}
+		res, err := p.readExternalSecret(ref, source, sourceOpts)
+		if err != nil && value != nil {
+			return template.DefaultValue(*value)
+		} else if err != nil {
+			if source.Strict {
+				strictErr(errors.Wrapf(err, "stack %q", stackName))
+			}
+			return noSubs, err
+		}
+		return res, nil
+	}
+}
+
+// readExternalSecret reads secret from the source once per run and selects the field of the reference
+func (p *placeholders) readExternalSecret(ref api.SecretRef, source api.SecretSourceDescriptor, sourceOpts []Option) (string, error) {
+	if err := p.Apply(&source.Config, sourceOpts...); err != nil {
+		return "", errors.Wrapf(err, "failed to resolve config of secret source %q", ref.Source)
+	}
+	config, err := json.Marshal(source.Config.Config)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal config of secret source %q", ref.Source)
+	}
+	cacheKey := strings.Join([]string{source.Type, string(config), ref.Path}, "\n")
+
+	p.secretsLock.Lock()
+	defer p.secretsLock.Unlock()
+	secret, cached := p.secretsCache[cacheKey]
+	if !cached {
+		reader, err := api.NewSecretSource(source.Type, source.Config)
+		if err != nil {
+			return "", err
+		}
+		if secret, err = reader.ReadSecret(context.Background(), ref.Path); err != nil {
+			return "", errors.Wrapf(err, "failed to read secret %q", ref.String())
+		}
+		if p.secretsCache == nil {
+			p.secretsCache = make(map[string]string)
+		}
+		p.secretsCache[cacheKey] = secret
+	}
+	return ref.Value(secret)
+}
+
 func (p *placeholders) extEnv(noSubstitution, path string, defaultValue *string) (string, error) {
 	res := os.Getenv(path)
 	if res == "" && defaultValue != nil {
-		return *defaultValue, nil
+		return template.DefaultValue(*defaultValue)
 	}
 	return res, nil
 }
 
+// extEnvDryRun reports variables which are not set, since they'd silently resolve to empty values
+func (p *placeholders) extEnvDryRun(noSubstitution, path string, defaultValue *string) (string, error) {
+	if _, found := os.LookupEnv(path); !found && defaultValue == nil {
+		return noSubstitution, errors.Errorf("environment variable %q is not set", path)
+	}
+	return p.extEnv(noSubstitution, path, defaultValue)
+}
+
 func (p *placeholders) tplGit(stackName string) func(source string, path string, value *string) (string, error) {
 	return func(noSubs, path string, value *string) (string, error) {
 		if p.git == nil {
@@ -305,8 +434,41 @@ func (p *placeholders) initTemplate(opts []Option) *template.Template {
 }
 
 // value must be a string
-func (p *placeholders) applyTemplateOnString(value string, opts []Option) string {
-	return p.initTemplate(opts).Exec(value)
+func (p *placeholders) applyTemplateOnString(value string, opts []Option, path []string) string {
+	tpl := p.initTemplate(opts)
+	if tpl.HasReporter() {
+		tpl.WithLocation(joinLocation(append([]string{tpl.Location()}, path...)))
+	}
+	return tpl.Exec(value)
+}
+
+// joinLocation joins path segments into YAML path, e.g. stacks.prod.config.env.FOO or uses[0]
+func joinLocation(path []string) string {
+	var res strings.Builder
+	for _, segment := range path {
+		if segment == "" {
+			continue
+		}
+		if res.Len() > 0 && !strings.HasPrefix(segment, "[") {
+			res.WriteString(".")
+		}
+		res.WriteString(segment)
+	}
+	return res.String()
+}
+
+// fieldLocation returns name of the struct field as in YAML, empty for inlined fields
+func fieldLocation(field reflect.StructField) string {
+	tag := field.Tag.Get("yaml")
+	if tag == "" {
+		tag = field.Tag.Get("json")
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+		return name
+	} else if strings.Contains(tag, "inline") {
+		return ""
+	}
+	return field.Name
 }
 
 // out must be a pointer
@@ -314,6 +476,10 @@ func (p *placeholders) applyTemplatesOnObject(out any, opts []Option) error {
 	rv := reflect.ValueOf(out)
 	reflectedVal := rv.Elem()
 	appliedResult := p.applyTemplates(out, opts)
+	if p.initTemplate(opts).HasReporter() {
+		// dry run: only report unresolved placeholders
+		return nil
+	}
 	val := reflect.ValueOf(appliedResult).Elem()
 	reflectedVal.Set(val)
 	return nil
@@ -323,12 +489,12 @@ func (p *placeholders) applyTemplates(obj any, opts []Option) any {
 	// Wrap the original in a reflect.Value
 	original := reflect.ValueOf(obj)
 	res := reflect.New(original.Type()).Elem()
-	p.applyTemplatesRecursive(res, original, opts)
+	p.applyTemplatesRecursive(res, original, opts, nil)
 	// Remove the reflection wrapper
 	return res.Interface()
 }
 
-func (p *placeholders) applyTemplatesRecursive(copy, original reflect.Value, opts []Option) {
+func (p *placeholders) applyTemplatesRecursive(copy, original reflect.Value, opts []Option, path []string) {
 	switch original.Kind() {
 	// The first cases handle nested structures and translate them recursively
 
@@ -345,7 +511,7 @@ func (p *placeholders) applyTemplatesRecursive(copy, original reflect.Value, opt
 		// Allocate a new object and set the pointer to it
 		copy.Set(reflect.New(originalValue.Type()))
 		// Unwrap the newly created pointer
-		p.applyTemplatesRecursive(copy.Elem(), originalValue, opts)
+		p.applyTemplatesRecursive(copy.Elem(), originalValue, opts, path)
 
 	// If it is an interface (which is very similar to a pointer), do basically the
 	// same as for the pointer. Though a pointer is not the same as an interface so
@@ -359,21 +525,21 @@ func (p *placeholders) applyTemplatesRecursive(copy, original reflect.Value, opt
 		// points to, so we have to call Elem() to unwrap it
 		if originalValue.IsValid() && copy.CanSet() {
 			copyValue := reflect.New(originalValue.Type()).Elem()
-			p.applyTemplatesRecursive(copyValue, originalValue, opts)
+			p.applyTemplatesRecursive(copyValue, originalValue, opts, path)
 			copy.Set(copyValue)
 		}
 
 	// If it is a struct we translate each field
 	case reflect.Struct:
 		for i := 0; i < original.NumField(); i += 1 {
-			p.applyTemplatesRecursive(copy.Field(i), original.Field(i), opts)
+			p.applyTemplatesRecursive(copy.Field(i), original.Field(i), opts, append(path, fieldLocation(original.Type().Field(i))))
 		}
 
 	// If it is a slice we create a new slice and translate each element
 	case reflect.Slice:
 		copy.Set(reflect.MakeSlice(original.Type(), original.Len(), original.Cap()))
 		for i := 0; i < original.Len(); i += 1 {
-			p.applyTemplatesRecursive(copy.Index(i), original.Index(i), opts)
+			p.applyTemplatesRecursive(copy.Index(i), original.Index(i), opts, append(path, fmt.Sprintf("[%d]", i)))
 		}
 
 	// If it is a map we create a new map and translate each value
@@ -383,7 +549,7 @@ func (p *placeholders) applyTemplatesRecursive(copy, original reflect.Value, opt
 			originalValue := original.MapIndex(key)
 			// New gives us a pointer, but again we want the value
 			copyValue := reflect.New(originalValue.Type()).Elem()
-			p.applyTemplatesRecursive(copyValue, originalValue, opts)
+			p.applyTemplatesRecursive(copyValue, originalValue, opts, append(path, fmt.Sprint(key.Interface())))
 			copy.SetMapIndex(key, copyValue)
 		}
 
@@ -394,11 +560,11 @@ func (p *placeholders) applyTemplatesRecursive(copy, original reflect.Value, opt
 		var processed string
 		originalVal := original.Interface()
 		if _, ok := originalVal.(string); ok {
-			processed = p.applyTemplateOnString(originalVal.(string), opts)
+			processed = p.applyTemplateOnString(originalVal.(string), opts, path)
 		} else if _, ok := originalVal.(StringValue); ok {
-			processed = p.applyTemplateOnString(string(originalVal.(StringValue)), opts)
+			processed = p.applyTemplateOnString(string(originalVal.(StringValue)), opts, path)
 		} else {
-			processed = p.applyTemplateOnString(reflect.ValueOf(originalVal).String(), opts)
+			processed = p.applyTemplateOnString(reflect.ValueOf(originalVal).String(), opts, path)
 		}
 		copy.SetString(processed)
 
@@ -4,10 +4,13 @@
 package tests
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	"github.com/simple-container-com/api/pkg/api"
 	git_mocks "github.com/simple-container-com/api/pkg/api/git/mocks"
@@ -18,6 +21,7 @@ import (
 	"github.com/simple-container-com/api/pkg/clouds/mongodb"
 	"github.com/simple-container-com/api/pkg/clouds/pulumi"
 	"github.com/simple-container-com/api/pkg/provisioner/placeholders"
+	"github.com/simple-container-com/api/pkg/template"
 )
 
 func Test_placeholders_ProcessStacks(t *testing.T) {
@@ -153,3 +157,173 @@ func Test_placeholders_ProcessStacks(t *testing.T) {
 		})
 	}
 }
+
+func Test_placeholders_DryRun(t *testing.T) {
+	RegisterTestingT(t)
+	t.Setenv("SC_PLACEHOLDERS_DRY_RUN_SET", "set")
+	stacksDir := t.TempDir()
+
+	stacks := api.StacksMap{
+		"billing": {
+			Name:    "billing",
+			Secrets: api.SecretsDescriptor{Values: map[string]string{"KNOWN": "value"}},
+			Client: api.ClientDescriptor{Stacks: map[string]api.StackClientDescriptor{
+				"prod": {Type: api.ClientTypeCloudCompose, Config: api.Config{Config: &api.StackConfigCompose{
+					Uses: []string{"${nme}"},
+					Env: map[string]string{
+						"A": "${secret:KNOWN}",
+						"B": "${secret:MISSING}",
+						"C": "${env:SC_PLACEHOLDERS_DRY_RUN_UNSET}",
+						"D": "${env:SC_PLACEHOLDERS_DRY_RUN_UNSET:default}",
+						"E": "${env:SC_PLACEHOLDERS_DRY_RUN_SET:${secret:UNUSED_DEFAULT}}",
+						"F": "${cmd:touch ran}",
+					},
+				}}},
+			}},
+		},
+	}
+
+	var refs []template.Reference
+	err := placeholders.New().Resolve(stacks, placeholders.WithStacksDir(stacksDir), placeholders.WithCommands(true), placeholders.WithDryRun(func(ref template.Reference) {
+		refs = append(refs, ref)
+	}))
+	Expect(err).To(BeNil())
+	Expect(refs).To(ConsistOf(
+		template.Reference{
+			Placeholder: "${nme}", Location: "billing.client.stacks.prod.config.uses[0]",
+			Reason: "no extension specified and value is not found", Ambiguous: true,
+		},
+		template.Reference{
+			Placeholder: "${secret:MISSING}", Extension: "secret", Location: "billing.client.stacks.prod.config.env.B",
+			Reason: `secret "MISSING" not found in stack "billing"`,
+		},
+		template.Reference{
+			Placeholder: "${env:SC_PLACEHOLDERS_DRY_RUN_UNSET}", Extension: "env", Location: "billing.client.stacks.prod.config.env.C",
+			Reason: `environment variable "SC_PLACEHOLDERS_DRY_RUN_UNSET" is not set`,
+		},
+		template.Reference{
+			Placeholder: "${cmd:touch ran}", Extension: "cmd", Location: "billing.client.stacks.prod.config.env.F",
+			Reason: `command "touch ran" is not run in dry run`, Deferred: true,
+		},
+	))
+	// commands are not run in dry-run mode
+	Expect(filepath.Join(stacksDir, "billing", "ran")).ToNot(BeAnExistingFile())
+
+	// stacks are not modified in dry-run mode
+	clientCfg := stacks["billing"].Client.Stacks["prod"].Config.Config.(*api.StackConfigCompose)
+	Expect(clientCfg.Env["A"]).To(Equal("${secret:KNOWN}"))
+}
+
+type testSecretSource struct {
+	token   string
+	secrets map[string]string
+	reads   *int
+}
+
+func (s *testSecretSource) ReadSecret(_ context.Context, path string) (string, error) {
+	*s.reads++
+	if s.token != "root" {
+		return "", errors.New("permission denied")
+	}
+	secret, found := s.secrets[path]
+	if !found {
+		return "", errors.Errorf("secret %q not found", path)
+	}
+	return secret, nil
+}
+
+func Test_placeholders_SecretSources(t *testing.T) {
+	RegisterTestingT(t)
+
+	reads := 0
+	api.RegisterSecretSources(api.SecretSourcesRegisterMap{
+		"test-store": func(config api.Config) (api.SecretSource, error) {
+			cfg := config.Config.(map[string]any)
+			return &testSecretSource{token: cfg["token"].(string), reads: &reads, secrets: map[string]string{
+				"kv/data/payments": `{"stripe_key":"sk_test","port":8080}`,
+				"plain":            "plain-value",
+			}}, nil
+		},
+	})
+	newStacks := func(strict bool, env map[string]string) api.StacksMap {
+		return api.StacksMap{
+			"payments": {
+				Name:    "payments",
+				Secrets: api.SecretsDescriptor{Values: map[string]string{"STORE_TOKEN": "root", "KNOWN": "value"}},
+				Server: api.ServerDescriptor{SecretSources: map[string]api.SecretSourceDescriptor{
+					"store": {Type: "test-store", Strict: strict, Config: api.Config{Config: map[string]any{"token": "${secret:STORE_TOKEN}"}}},
+				}},
+				Client: api.ClientDescriptor{Stacks: map[string]api.StackClientDescriptor{
+					"prod": {Type: api.ClientTypeCloudCompose, Config: api.Config{Config: &api.StackConfigCompose{Env: env}}},
+				}},
+			},
+		}
+	}
+	clientEnv := func(stacks api.StacksMap) map[string]string {
+		return stacks["payments"].Client.Stacks["prod"].Config.Config.(*api.StackConfigCompose).Env
+	}
+
+	t.Run("resolves secrets of declared sources", func(t *testing.T) {
+		RegisterTestingT(t)
+		reads = 0
+		stacks := newStacks(false, map[string]string{
+			"STRIPE_KEY": "${secret:store://kv/data/payments#stripe_key}",
+			"PORT":       "${secret:store://kv/data/payments#port}",
+			"PLAIN":      "${secret:store://plain}",
+			"FALLBACK":   "${secret:store://missing:fallback}",
+			"KNOWN":      "${secret:KNOWN}",
+		})
+		Expect(placeholders.New().Resolve(stacks)).To(Succeed())
+		Expect(clientEnv(stacks)).To(Equal(map[string]string{
+			"STRIPE_KEY": "sk_test",
+			"PORT":       "8080",
+			"PLAIN":      "plain-value",
+			"FALLBACK":   "fallback",
+			"KNOWN":      "value",
+		}))
+		// secrets are cached, so kv/data/payments is read once
+		Expect(reads).To(Equal(3))
+	})
+
+	t.Run("leaves placeholders unresolved for non-strict sources", func(t *testing.T) {
+		RegisterTestingT(t)
+		stacks := newStacks(false, map[string]string{"MISSING": "${secret:store://missing}"})
+		Expect(placeholders.New().Resolve(stacks)).To(Succeed())
+		Expect(clientEnv(stacks)["MISSING"]).To(Equal("${secret:store://missing}"))
+	})
+
+	t.Run("fails for strict sources", func(t *testing.T) {
+		RegisterTestingT(t)
+		stacks := newStacks(true, map[string]string{"MISSING": "${secret:store://missing}"})
+		err := placeholders.New().Resolve(stacks)
+		Expect(err).To(MatchError(ContainSubstring(`failed to read secret "store://missing"`)))
+	})
+
+	t.Run("does not read sources in dry run", func(t *testing.T) {
+		RegisterTestingT(t)
+		reads = 0
+		stacks := newStacks(true, map[string]string{"STRIPE_KEY": "${secret:store://kv/data/payments#stripe_key}"})
+		var refs []template.Reference
+		Expect(placeholders.New().Resolve(stacks, placeholders.WithDryRun(func(ref template.Reference) {
+			refs = append(refs, ref)
+		}))).To(Succeed())
+		Expect(refs).To(ConsistOf(template.Reference{
+			Placeholder: "${secret:store://kv/data/payments#stripe_key}", Extension: "secret", Location: "payments.client.stacks.prod.config.env.STRIPE_KEY",
+			Reason: `secret source "store" is not read in dry run`, Deferred: true,
+		}))
+		Expect(reads).To(Equal(0))
+	})
+
+	t.Run("reports undeclared sources in dry run", func(t *testing.T) {
+		RegisterTestingT(t)
+		stacks := newStacks(true, map[string]string{"OTHER": "${secret:other://path}"})
+		var refs []template.Reference
+		Expect(placeholders.New().Resolve(stacks, placeholders.WithDryRun(func(ref template.Reference) {
+			refs = append(refs, ref)
+		}))).To(Succeed())
+		Expect(refs).To(ConsistOf(template.Reference{
+			Placeholder: "${secret:other://path}", Extension: "secret", Location: "payments.client.stacks.prod.config.env.OTHER",
+			Reason: `secret source "other" is not declared in stack "payments"`,
+		}))
+	})
+}
@@ -5,8 +5,10 @@ package provisioner
 
 import (
 	"context"
+	"sort"
 
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 
 	"github.com/simple-container-com/api/pkg/api"
 )
@@ -42,6 +44,43 @@ func (p *provisioner) Outputs(ctx context.Context, params api.StackParams) (*api
 	}
 }
 
+// OutputsAll returns outputs of every client stack configured for params.Environment (sorted by stack name)
+func (p *provisioner) OutputsAll(ctx context.Context, params api.StackParams) ([]*api.OutputsResult, error) {
+	if params.Environment == "" {
+		return nil, errors.Errorf("environment must be specified")
+	}
+	cfg, err := api.ReadConfigFile(p.rootDir, p.profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file for profile %q", p.profile)
+	}
+	if err := p.ReadStacks(ctx, cfg, api.ProvisionParams{
+		StacksDir: params.StacksDir,
+		Profile:   params.Profile,
+	}, api.ReadIgnoreNoAnyCfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to read stacks")
+	}
+	stackNames := lo.Filter(lo.Keys(p.stacks), func(stackName string, _ int) bool {
+		_, ok := p.stacks[stackName].Client.Stacks[params.Environment]
+		return ok
+	})
+	if len(stackNames) == 0 {
+		return nil, errors.Errorf("no client stacks are configured for environment %q", params.Environment)
+	}
+	sort.Strings(stackNames)
+
+	res := make([]*api.OutputsResult, 0, len(stackNames))
+	for _, stackName := range stackNames {
+		stackParams := params
+		stackParams.StackName = stackName
+		outputs, err := p.forStack().Outputs(ctx, stackParams)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get outputs of stack %q in %q", stackName, params.Environment)
+		}
+		res = append(res, outputs)
+	}
+	return res, nil
+}
+
 func (p *provisioner) PreviewProvision(ctx context.Context, params api.ProvisionParams) ([]*api.PreviewResult, error) {
 	p.logWelcome(ctx, nil)
 
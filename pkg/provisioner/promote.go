@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+)
+
+// Promote deploys stack to params.Environment with exactly the same images and version
+// that are currently deployed to params.FromEnvironment, without rebuilding them
+func (p *provisioner) Promote(ctx context.Context, params api.PromoteParams) (*api.UpdateResult, error) {
+	p.logWelcome(ctx, nil)
+
+	if params.FromEnvironment == "" || params.Environment == "" {
+		return nil, errors.Errorf("both source and target environments must be specified")
+	}
+	if params.FromEnvironment == params.Environment {
+		return nil, errors.Errorf("cannot promote stack %q to the same environment %q", params.StackName, params.Environment)
+	}
+
+	fromParams := params.StackParams
+	fromParams.Environment = params.FromEnvironment
+	source := p.forStack()
+	outputs, err := source.Outputs(ctx, fromParams)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read outputs of stack %q in %q", params.StackName, params.FromEnvironment)
+	}
+	images, err := api.PromotedImagesFromOutputs(outputs.Outputs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read deployed images of stack %q in %q", params.StackName, params.FromEnvironment)
+	}
+	if len(images) == 0 {
+		return nil, errors.Errorf("no deployed images found in outputs of stack %q in %q, consider re-deploying it first", params.StackName, params.FromEnvironment)
+	}
+	version, _ := outputs.Outputs[api.DeployVersionOutput].(string)
+	if params.Version != "" && params.Version != version {
+		return nil, errors.Errorf("version %q differs from version %q deployed to %q", params.Version, version, params.FromEnvironment)
+	}
+
+	params.Version = version
+	params.PromotedFrom = params.FromEnvironment
+	params.PinnedImages = images
+	cfg, stack, pv, err := p.prepareForChildStack(ctx, &params.StackParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.CheckPromotable(source.stacks[params.StackName].Client.Stacks[params.FromEnvironment],
+		stack.Client.Stacks[params.Environment]); err != nil {
+		return nil, errors.Wrapf(err, "stack %q in %q requires rebuild and cannot be promoted from %q", params.StackName, params.Environment, params.FromEnvironment)
+	}
+
+	p.log.Info(ctx, "%s", color.GreenFmt("promoting stack %q version %q from %q to %q...", params.StackName, version, params.FromEnvironment, params.Environment))
+	for imageName, ref := range images {
+		p.log.Info(ctx, "image %q: %s", imageName, ref)
+	}
+	return pv.DeployStack(ctx, cfg, *stack, params.DeployParams)
+}
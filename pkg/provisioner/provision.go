@@ -14,6 +14,7 @@ import (
 	"github.com/samber/lo"
 
 	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/provisioner/placeholders"
 )
 
 func (p *provisioner) Provision(ctx context.Context, params api.ProvisionParams) error {
@@ -75,6 +76,10 @@ func (p *provisioner) getProvisionerForStack(ctx context.Context, stack api.Stac
 
 func (p *provisioner) ReadStacks(ctx context.Context, cfg *api.ConfigFile, params api.ProvisionParams, readOpts api.ReadOpts) error {
 	stacksDir := p.getStacksDir(cfg, params.StacksDir)
+	p.phOpts = []placeholders.Option{
+		placeholders.WithStacksDir(stacksDir),
+		placeholders.WithCommands(cfg != nil && cfg.AllowCmdPlaceholders),
+	}
 
 	stacks := params.Stacks
 	if len(stacks) == 0 {
@@ -156,7 +161,7 @@ func (p *provisioner) resolvePlaceholders() error {
 	}
 
 	p.log.Debug(ctx, "🔧 Calling phResolver.Resolve()...")
-	err := p.phResolver.Resolve(p.stacks)
+	err := p.phResolver.Resolve(p.stacks, p.phOpts...)
 	if err != nil {
 		p.log.Debug(ctx, "❌ Placeholder resolution failed: %v", err)
 		return err
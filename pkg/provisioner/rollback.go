@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+// History returns updates of the stack in the environment along with revisions they can be rolled back to
+func (p *provisioner) History(ctx context.Context, params api.StackParams) ([]api.StackHistoryEntry, error) {
+	cfg, stack, pv, err := p.prepareForChildStack(ctx, &params)
+	if err != nil {
+		return nil, err
+	}
+	return pv.StackHistory(ctx, cfg, *stack, params)
+}
+
+// Rollback re-deploys stack with the descriptor and images captured at one of its previous updates
+func (p *provisioner) Rollback(ctx context.Context, params api.RollbackParams) (*api.UpdateResult, error) {
+	p.logWelcome(ctx, nil)
+
+	cfg, stack, pv, err := p.prepareForChildStack(ctx, &params.StackParams)
+	if err != nil {
+		return nil, err
+	}
+	history, err := pv.StackHistory(ctx, cfg, *stack, params.StackParams)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get history of stack %q in %q", params.StackName, params.Environment)
+	}
+	revision, err := api.SelectRollbackRevision(history, params.ToUpdate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to roll back stack %q in %q", params.StackName, params.Environment)
+	}
+	return pv.RollbackStack(ctx, cfg, *stack, params.DeployParams, *revision)
+}
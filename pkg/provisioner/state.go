@@ -0,0 +1,394 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/logger/color"
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+// MigrateState copies states of parent stacks and their child stacks from the state storage configured for
+// params.FromProfile to the one configured for params.ToProfile, re-encrypting secrets with the secrets provider
+// of the target and verifying the number of resources of every migrated stack
+func (p *provisioner) MigrateState(ctx context.Context, params api.MigrateStateParams) (*api.StateMigrationReport, error) {
+	if params.FromProfile == "" || params.ToProfile == "" {
+		return nil, errors.Errorf("both source and target profiles must be specified")
+	} else if params.FromProfile == params.ToProfile {
+		return nil, errors.Errorf("source and target profiles must differ")
+	}
+
+	from := p.forProfile(params.FromProfile)
+	cfg, err := api.ReadConfigFile(from.rootDir, from.profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file for profile %q", from.profile)
+	}
+	if err := from.ReadStacks(ctx, cfg, api.ProvisionParams{
+		StacksDir: params.StacksDir,
+	}, api.ReadIgnoreNoAnyCfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to read stacks")
+	}
+	targets, err := stateTargets(from.stacks, params.Stacks)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &api.StateMigrationReport{
+		FromProfile: params.FromProfile,
+		ToProfile:   params.ToProfile,
+		DryRun:      params.DryRun,
+	}
+	for _, target := range targets {
+		target.StacksDir = params.StacksDir
+		migration, err := p.migrateStackState(ctx, target, params)
+		if err != nil {
+			return report, errors.Wrapf(err, "failed to migrate state of %s", stateTargetName(target))
+		}
+		report.Stacks = append(report.Stacks, *migration)
+	}
+	return report, nil
+}
+
+func (p *provisioner) migrateStackState(ctx context.Context, target api.StackParams, params api.MigrateStateParams) (*api.StackMigration, error) {
+	res := &api.StackMigration{Stack: target.StackName, Environment: target.Environment}
+
+	srcCfg, srcStack, srcPv, err := p.forProfile(params.FromProfile).prepareForStateStack(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	state, err := srcPv.ExportStack(ctx, srcCfg, *srcStack, target)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		res.Skipped = "stack does not exist in the source state storage"
+		p.log.Info(ctx, "%s", color.YellowFmt("skipping %s: %s", stateTargetName(target), res.Skipped))
+		return res, nil
+	}
+	if res.SourceResources, err = state.ResourceCount(); err != nil {
+		return nil, err
+	}
+
+	dstCfg, dstStack, dstPv, err := p.forProfile(params.ToProfile).prepareForStateStack(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := dstPv.ExportStack(ctx, dstCfg, *dstStack, target)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if res.TargetResources, err = existing.ResourceCount(); err != nil {
+			return nil, err
+		}
+		if res.TargetResources > 0 && !params.Force {
+			return nil, errors.Errorf("stack already has %d resources in the target state storage, use --force to overwrite it", res.TargetResources)
+		}
+	}
+	if params.DryRun {
+		p.log.Info(ctx, "%s", color.GreenFmt("%s would be migrated with %d resources", stateTargetName(target), res.SourceResources))
+		return res, nil
+	}
+
+	p.log.Info(ctx, "%s", color.GreenFmt("migrating %s with %d resources...", stateTargetName(target), res.SourceResources))
+	if err := dstPv.ImportStack(ctx, dstCfg, *dstStack, target, *state); err != nil {
+		return nil, err
+	}
+	imported, err := dstPv.ExportStack(ctx, dstCfg, *dstStack, target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to verify imported state")
+	} else if imported == nil {
+		return nil, errors.Errorf("stack does not exist in the target state storage after import")
+	}
+	if res.TargetResources, err = imported.ResourceCount(); err != nil {
+		return nil, err
+	}
+	if res.TargetResources != res.SourceResources {
+		return nil, errors.Errorf("target state storage has %d resources while source has %d", res.TargetResources, res.SourceResources)
+	}
+	return res, nil
+}
+
+// forProfile returns provisioner reading configuration of another profile
+func (p *provisioner) forProfile(profile string) *provisioner {
+	res := p.forStack()
+	res.profile = profile
+	return res
+}
+
+// prepareForStateStack returns provisioner of the parent stack, or of the child stack if environment is specified
+func (p *provisioner) prepareForStateStack(ctx context.Context, params api.StackParams) (*api.ConfigFile, *api.Stack, api.Provisioner, error) {
+	if params.Environment != "" {
+		return p.prepareForChildStack(ctx, &params)
+	}
+	return p.initProvisioner(ctx, params)
+}
+
+// stateTargets returns parent stacks (with provisioner configured) along with child stacks deployed into them,
+// each parent stack is followed by its child stacks, all sorted by name and environment
+func stateTargets(stacks api.StacksMap, parents []string) ([]api.StackParams, error) {
+	for _, name := range parents {
+		if stack, found := stacks[name]; !found {
+			return nil, errors.Errorf("stack %q is not found in configurations", name)
+		} else if stack.Server.Provisioner.Type == "" {
+			return nil, errors.Errorf("stack %q is not a parent stack: provisioner is not configured", name)
+		}
+	}
+	if len(parents) == 0 {
+		parents = lo.Filter(lo.Keys(stacks), func(name string, _ int) bool {
+			return stacks[name].Server.Provisioner.Type != ""
+		})
+	}
+	parents = lo.Uniq(parents)
+	sort.Strings(parents)
+
+	children := lo.Keys(stacks)
+	sort.Strings(children)
+
+	var res []api.StackParams
+	for _, parent := range parents {
+		res = append(res, api.StackParams{StackName: parent, Parent: true})
+		for _, child := range children {
+			envs := lo.Keys(stacks[child].Client.Stacks)
+			sort.Strings(envs)
+			for _, env := range envs {
+				if collapseStackName(stacks[child].Client.Stacks[env].ParentStack) == parent {
+					res = append(res, api.StackParams{StackName: child, Environment: env})
+				}
+			}
+		}
+	}
+	if len(res) == 0 {
+		return nil, errors.Errorf("no parent stacks are configured")
+	}
+	return res, nil
+}
+
+func stateTargetName(params api.StackParams) string {
+	if params.Environment == "" {
+		return fmt.Sprintf("parent stack %q", params.StackName)
+	}
+	return fmt.Sprintf("stack %q in %q", params.StackName, params.Environment)
+}
+
+// BackupState writes snapshot of states of the parent stack and its child stacks encrypted for public keys
+// allowed to decrypt secrets of all these stacks and deletes snapshots of the stack beyond params.Keep latest ones
+func (p *provisioner) BackupState(ctx context.Context, params api.BackupStateParams) (*api.StateSnapshot, error) {
+	if params.StackName == "" {
+		return nil, errors.Errorf("stack must be specified")
+	}
+	if p.cryptor == nil {
+		return nil, errors.Errorf("secrets are not configured, snapshots cannot be encrypted")
+	}
+	cfg, err := api.ReadConfigFile(p.rootDir, p.profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file for profile %q", p.profile)
+	}
+	if err := p.ReadStacks(ctx, cfg, api.ProvisionParams{
+		StacksDir: params.StacksDir,
+	}, api.ReadIgnoreNoAnyCfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to read stacks")
+	}
+	targets, err := stateTargets(p.stacks, []string{params.StackName})
+	if err != nil {
+		return nil, err
+	}
+	secretFiles, err := stateSecretFiles(p.rootDir, p.getStacksDir(cfg, params.StacksDir), targets)
+	if err != nil {
+		return nil, err
+	}
+	backups, err := openStateBackups(ctx, params.Location)
+	if err != nil {
+		return nil, err
+	}
+	defer backups.close()
+
+	now := time.Now().UTC()
+	snapshot := api.StateSnapshot{
+		ID:        api.NewStateSnapshotID(params.StackName, now),
+		Stack:     params.StackName,
+		CreatedAt: now,
+	}
+	for _, target := range targets {
+		target.StacksDir = params.StacksDir
+		cfg, stack, pv, err := p.forStack().prepareForStateStack(ctx, target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare %s", stateTargetName(target))
+		}
+		state, err := pv.ExportStack(ctx, cfg, *stack, target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to export %s", stateTargetName(target))
+		}
+		if state == nil {
+			p.log.Info(ctx, "%s", color.YellowFmt("skipping %s: stack does not exist", stateTargetName(target)))
+			continue
+		}
+		count, err := state.ResourceCount()
+		if err != nil {
+			return nil, err
+		}
+		content, err := json.Marshal(state)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal state of %s", stateTargetName(target))
+		}
+		sealed, err := p.cryptor.Seal(content, secretFiles...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encrypt state of %s", stateTargetName(target))
+		}
+		file := stateSnapshotFile(target)
+		if err := backups.writeState(ctx, snapshot.ID, file, sealed); err != nil {
+			return nil, err
+		}
+		snapshot.Stacks = append(snapshot.Stacks, api.StateSnapshotStack{
+			Stack:       target.StackName,
+			Environment: target.Environment,
+			Resources:   count,
+			File:        file,
+		})
+		p.log.Info(ctx, "%s", color.GreenFmt("backed up %s with %d resources", stateTargetName(target), count))
+	}
+	if len(snapshot.Stacks) == 0 {
+		return nil, errors.Errorf("none of stacks of %q exist, nothing to back up", params.StackName)
+	}
+	if err := backups.writeManifest(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := backups.list(ctx)
+	if err != nil {
+		return &snapshot, err
+	}
+	for _, expired := range expiredStateSnapshots(snapshots, params.StackName, params.Keep) {
+		p.log.Info(ctx, "deleting expired snapshot %q", expired.ID)
+		if err := backups.delete(ctx, expired.ID); err != nil {
+			return &snapshot, err
+		}
+	}
+	return &snapshot, nil
+}
+
+// stateSecretFiles returns paths of secrets.yaml of the stacks relative to the root directory, states contain
+// their decrypted secrets and must only be readable by public keys allowed to decrypt all of them
+func stateSecretFiles(rootDir, stacksDir string, targets []api.StackParams) ([]string, error) {
+	relStacksDir, err := filepath.Rel(rootDir, stacksDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve stacks dir %q relative to %q", stacksDir, rootDir)
+	}
+	res := lo.Uniq(lo.Map(targets, func(target api.StackParams, _ int) string {
+		return secrets.StackSecretsFile(filepath.ToSlash(relStacksDir), target.StackName)
+	}))
+	sort.Strings(res)
+	return res, nil
+}
+
+// StateSnapshots returns snapshots stored in the location sorted from the newest to the oldest
+func (p *provisioner) StateSnapshots(ctx context.Context, location string) ([]api.StateSnapshot, error) {
+	backups, err := openStateBackups(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	defer backups.close()
+	return backups.list(ctx)
+}
+
+// RestoreState compares states of stacks in the snapshot with their current states and, unless params.Preview
+// is set, backs up current states to a new snapshot in the same location and replaces current states of the
+// changed stacks with the ones from the snapshot
+func (p *provisioner) RestoreState(ctx context.Context, params api.RestoreStateParams) (*api.StateRestoreReport, error) {
+	if params.Snapshot == "" {
+		return nil, errors.Errorf("snapshot must be specified")
+	}
+	if p.cryptor == nil {
+		return nil, errors.Errorf("secrets are not configured, snapshots cannot be decrypted")
+	}
+	backups, err := openStateBackups(ctx, params.Location)
+	if err != nil {
+		return nil, err
+	}
+	defer backups.close()
+	snapshot, err := backups.manifest(ctx, params.Snapshot)
+	if err != nil {
+		return nil, err
+	} else if snapshot == nil {
+		return nil, errors.Errorf("snapshot %q is not found in %q", params.Snapshot, params.Location)
+	}
+
+	type stackToRestore struct {
+		target api.StackParams
+		cfg    *api.ConfigFile
+		stack  *api.Stack
+		pv     api.Provisioner
+		state  api.StackState
+	}
+	var toRestore []stackToRestore
+	report := &api.StateRestoreReport{Snapshot: *snapshot, Preview: params.Preview}
+	for _, snapshotStack := range snapshot.Stacks {
+		target := api.StackParams{
+			StackName:   snapshotStack.Stack,
+			Environment: snapshotStack.Environment,
+			Parent:      snapshotStack.Environment == "",
+			StacksDir:   params.StacksDir,
+		}
+		sealed, err := backups.readState(ctx, snapshot.ID, snapshotStack.File)
+		if err != nil {
+			return nil, err
+		}
+		content, err := p.cryptor.Open(*sealed)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt state of %s", stateTargetName(target))
+		}
+		var state api.StackState
+		if err := json.Unmarshal(content, &state); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal state of %s", stateTargetName(target))
+		}
+		cfg, stack, pv, err := p.forStack().prepareForStateStack(ctx, target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare %s", stateTargetName(target))
+		}
+		current, err := pv.ExportStack(ctx, cfg, *stack, target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to export %s", stateTargetName(target))
+		}
+		restore, err := api.NewStackRestore(snapshotStack, current, state)
+		if err != nil {
+			return nil, err
+		}
+		report.Stacks = append(report.Stacks, *restore)
+		if restore.HasChanges() {
+			toRestore = append(toRestore, stackToRestore{target: target, cfg: cfg, stack: stack, pv: pv, state: state})
+		}
+	}
+	if params.Preview {
+		return report, nil
+	}
+	if len(toRestore) > 0 && lo.SomeBy(report.Stacks, func(s api.StackRestore) bool { return s.CurrentResources >= 0 }) {
+		backup, err := p.BackupState(ctx, api.BackupStateParams{
+			StacksDir: params.StacksDir,
+			StackName: snapshot.Stack,
+			Location:  params.Location,
+		})
+		if err != nil {
+			return report, errors.Wrapf(err, "failed to back up current states before restoring snapshot %q", snapshot.ID)
+		}
+		report.Backup = backup
+		p.log.Info(ctx, "%s", color.GreenFmt("current states are backed up to snapshot %q", backup.ID))
+	}
+	for _, s := range toRestore {
+		p.log.Info(ctx, "%s", color.GreenFmt("restoring %s from snapshot %q...", stateTargetName(s.target), snapshot.ID))
+		if err := s.pv.ImportStack(ctx, s.cfg, *s.stack, s.target, s.state); err != nil {
+			return report, errors.Wrapf(err, "failed to restore %s", stateTargetName(s.target))
+		}
+	}
+	return report, nil
+}
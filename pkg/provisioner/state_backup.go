@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+	"gocloud.dev/gcerrors"
+
+	// drivers of bucket URLs snapshots can be written to
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+const stateSnapshotManifestFile = "manifest.json"
+
+// stateBackups stores snapshots of stack states in a local directory or a bucket,
+// every snapshot is a directory named by its ID with the manifest and encrypted states of stacks
+type stateBackups struct {
+	bucket *blob.Bucket
+}
+
+// openStateBackups opens location which is either a local directory or a bucket URL (s3://, gs://, file://)
+func openStateBackups(ctx context.Context, location string) (*stateBackups, error) {
+	if location == "" {
+		return nil, errors.Errorf("location of snapshots must be specified")
+	}
+	var bucket *blob.Bucket
+	var err error
+	if strings.Contains(location, "://") {
+		bucket, err = blob.OpenBucket(ctx, location)
+	} else {
+		dir, absErr := filepath.Abs(location)
+		if absErr != nil {
+			return nil, errors.Wrapf(absErr, "failed to resolve path %q", location)
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, errors.Wrapf(err, "failed to create directory %q", dir)
+		}
+		bucket, err = fileblob.OpenBucket(dir, nil)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open location of snapshots %q", location)
+	}
+	return &stateBackups{bucket: bucket}, nil
+}
+
+func (b *stateBackups) close() {
+	_ = b.bucket.Close()
+}
+
+// writeManifest completes the snapshot, snapshots without manifest are ignored
+func (b *stateBackups) writeManifest(ctx context.Context, snapshot api.StateSnapshot) error {
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal manifest of snapshot %q", snapshot.ID)
+	}
+	return b.write(ctx, path.Join(snapshot.ID, stateSnapshotManifestFile), content)
+}
+
+func (b *stateBackups) manifest(ctx context.Context, id string) (*api.StateSnapshot, error) {
+	content, err := b.bucket.ReadAll(ctx, path.Join(id, stateSnapshotManifestFile))
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest of snapshot %q", id)
+	}
+	var res api.StateSnapshot
+	if err := json.Unmarshal(content, &res); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal manifest of snapshot %q", id)
+	}
+	return &res, nil
+}
+
+func (b *stateBackups) writeState(ctx context.Context, id, file string, sealed *secrets.SealedData) error {
+	content, err := json.Marshal(sealed)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %q of snapshot %q", file, id)
+	}
+	return b.write(ctx, path.Join(id, file), content)
+}
+
+func (b *stateBackups) readState(ctx context.Context, id, file string) (*secrets.SealedData, error) {
+	content, err := b.bucket.ReadAll(ctx, path.Join(id, file))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q of snapshot %q", file, id)
+	}
+	var res secrets.SealedData
+	if err := json.Unmarshal(content, &res); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %q of snapshot %q", file, id)
+	}
+	return &res, nil
+}
+
+func (b *stateBackups) write(ctx context.Context, key string, content []byte) error {
+	if err := b.bucket.WriteAll(ctx, key, content, &blob.WriterOptions{ContentType: "application/json"}); err != nil {
+		return errors.Wrapf(err, "failed to write %q", key)
+	}
+	return nil
+}
+
+// list returns complete snapshots sorted from the newest to the oldest
+func (b *stateBackups) list(ctx context.Context) ([]api.StateSnapshot, error) {
+	var res []api.StateSnapshot
+	iter := b.bucket.List(&blob.ListOptions{Delimiter: "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to list snapshots")
+		}
+		if !obj.IsDir {
+			continue
+		}
+		snapshot, err := b.manifest(ctx, strings.TrimSuffix(obj.Key, "/"))
+		if err != nil {
+			return nil, err
+		} else if snapshot != nil {
+			res = append(res, *snapshot)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+// delete removes the snapshot along with its states
+func (b *stateBackups) delete(ctx context.Context, id string) error {
+	// manifest goes first, so that partially deleted snapshot is never listed
+	manifestKey := path.Join(id, stateSnapshotManifestFile)
+	if err := b.bucket.Delete(ctx, manifestKey); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return errors.Wrapf(err, "failed to delete %q", manifestKey)
+	}
+	iter := b.bucket.List(&blob.ListOptions{Prefix: id + "/"})
+	var keys []string
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to list files of snapshot %q", id)
+		}
+		keys = append(keys, obj.Key)
+	}
+	for _, key := range keys {
+		if err := b.bucket.Delete(ctx, key); err != nil {
+			return errors.Wrapf(err, "failed to delete %q", key)
+		}
+	}
+	return nil
+}
+
+// expiredStateSnapshots returns snapshots of the stack beyond the keep latest ones
+func expiredStateSnapshots(snapshots []api.StateSnapshot, stackName string, keep int) []api.StateSnapshot {
+	if keep <= 0 {
+		return nil
+	}
+	ofStack := lo.Filter(snapshots, func(s api.StateSnapshot, _ int) bool {
+		return s.Stack == stackName
+	})
+	sort.SliceStable(ofStack, func(i, j int) bool {
+		return ofStack[i].CreatedAt.After(ofStack[j].CreatedAt)
+	})
+	if len(ofStack) <= keep {
+		return nil
+	}
+	return ofStack[keep:]
+}
+
+// stateSnapshotFile returns name of the file with the state of the stack within the snapshot
+func stateSnapshotFile(params api.StackParams) string {
+	if params.Environment == "" {
+		return params.StackName + ".json"
+	}
+	return params.StackName + "--" + params.Environment + ".json"
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/api/secrets"
+)
+
+func TestStateBackups(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	backups, err := openStateBackups(ctx, filepath.Join(t.TempDir(), "backups"))
+	Expect(err).To(BeNil())
+	defer backups.close()
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	snapshot := func(stack string, hoursAgo int) api.StateSnapshot {
+		createdAt := start.Add(-time.Duration(hoursAgo) * time.Hour)
+		res := api.StateSnapshot{
+			ID:        api.NewStateSnapshotID(stack, createdAt),
+			Stack:     stack,
+			CreatedAt: createdAt,
+			Stacks:    []api.StateSnapshotStack{{Stack: stack, Resources: 2, File: stateSnapshotFile(api.StackParams{StackName: stack})}},
+		}
+		Expect(backups.writeState(ctx, res.ID, res.Stacks[0].File, &secrets.SealedData{Data: []byte(stack)})).To(Succeed())
+		Expect(backups.writeManifest(ctx, res)).To(Succeed())
+		return res
+	}
+	oldest, older, latest := snapshot("devops", 3), snapshot("devops", 2), snapshot("devops", 1)
+	other := snapshot("infra", 4)
+	// snapshot without manifest is incomplete
+	Expect(backups.writeState(ctx, "devops-20261016T120000Z", "devops.json", &secrets.SealedData{})).To(Succeed())
+
+	snapshots, err := backups.list(ctx)
+	Expect(err).To(BeNil())
+	Expect(lo.Map(snapshots, func(s api.StateSnapshot, _ int) string { return s.ID })).To(Equal([]string{latest.ID, older.ID, oldest.ID, other.ID}))
+	Expect(snapshots[0].Stacks).To(Equal(latest.Stacks))
+
+	sealed, err := backups.readState(ctx, older.ID, "devops.json")
+	Expect(err).To(BeNil())
+	Expect(sealed.Data).To(Equal([]byte("devops")))
+
+	Expect(expiredStateSnapshots(snapshots, "devops", 0)).To(BeEmpty())
+	Expect(expiredStateSnapshots(snapshots, "devops", 3)).To(BeEmpty())
+	expired := expiredStateSnapshots(snapshots, "devops", 1)
+	Expect(expired).To(Equal([]api.StateSnapshot{older, oldest}))
+
+	for _, s := range expired {
+		Expect(backups.delete(ctx, s.ID)).To(Succeed())
+	}
+	snapshots, err = backups.list(ctx)
+	Expect(err).To(BeNil())
+	Expect(lo.Map(snapshots, func(s api.StateSnapshot, _ int) string { return s.ID })).To(Equal([]string{latest.ID, other.ID}))
+	_, err = backups.readState(ctx, older.ID, "devops.json")
+	Expect(err).To(HaveOccurred())
+
+	missing, err := backups.manifest(ctx, "devops-20200101T000000Z")
+	Expect(err).To(BeNil())
+	Expect(missing).To(BeNil())
+
+	Expect(stateSnapshotFile(api.StackParams{StackName: "billing", Environment: "production"})).To(Equal("billing--production.json"))
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func Test_stateTargets(t *testing.T) {
+	RegisterTestingT(t)
+
+	parentStack := func(name string) api.Stack {
+		return api.Stack{Name: name, Server: api.ServerDescriptor{Provisioner: api.ProvisionerDescriptor{Type: "pulumi"}}}
+	}
+	billing := clientStack("prod", "myproject/infra", &api.StackConfigCompose{})
+	billing.Client.Stacks["staging"] = api.StackClientDescriptor{ParentStack: "infra"}
+	stacks := api.StacksMap{
+		"infra":   parentStack("infra"),
+		"devops":  parentStack("devops"),
+		"billing": billing,
+		"api":     clientStack("prod", "infra", &api.StackConfigCompose{}),
+		"tools":   clientStack("prod", "devops", &api.StackConfigCompose{}),
+		"common":  {Name: "common"},
+	}
+
+	targets, err := stateTargets(stacks, nil)
+	Expect(err).To(BeNil())
+	Expect(targets).To(Equal([]api.StackParams{
+		{StackName: "devops", Parent: true},
+		{StackName: "tools", Environment: "prod"},
+		{StackName: "infra", Parent: true},
+		{StackName: "api", Environment: "prod"},
+		{StackName: "billing", Environment: "prod"},
+		{StackName: "billing", Environment: "staging"},
+	}))
+
+	targets, err = stateTargets(stacks, []string{"devops"})
+	Expect(err).To(BeNil())
+	Expect(targets).To(Equal([]api.StackParams{
+		{StackName: "devops", Parent: true},
+		{StackName: "tools", Environment: "prod"},
+	}))
+
+	_, err = stateTargets(stacks, []string{"common"})
+	Expect(err).To(MatchError(ContainSubstring(`stack "common" is not a parent stack`)))
+	_, err = stateTargets(stacks, []string{"missing"})
+	Expect(err).To(MatchError(ContainSubstring(`stack "missing" is not found`)))
+	_, err = stateTargets(api.StacksMap{"api": stacks["api"]}, nil)
+	Expect(err).To(MatchError(ContainSubstring("no parent stacks are configured")))
+}
+
+func Test_stateSecretFiles(t *testing.T) {
+	RegisterTestingT(t)
+
+	targets := []api.StackParams{
+		{StackName: "infra", Parent: true},
+		{StackName: "billing", Environment: "prod"},
+		{StackName: "billing", Environment: "staging"},
+	}
+	files, err := stateSecretFiles("/repo", "/repo/.sc/stacks", targets)
+	Expect(err).To(BeNil())
+	Expect(files).To(Equal([]string{".sc/stacks/billing/secrets.yaml", ".sc/stacks/infra/secrets.yaml"}))
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/api/pkg/api"
+)
+
+func (p *provisioner) Unlock(ctx context.Context, params api.StackParams) (*api.StackLock, error) {
+	if params.Environment != "" {
+		cfg, stack, pv, err := p.prepareForChildStack(ctx, &params)
+		if err != nil {
+			return nil, err
+		}
+		return pv.UnlockStack(ctx, cfg, *stack, params)
+	}
+	cfg, err := p.prepareForParentStack(ctx, params.ToProvisionParams())
+	if err != nil {
+		return nil, err
+	}
+	if stack, found := p.stacks[params.StackName]; !found {
+		return nil, errors.Errorf("stack %q is not found in configurations", params.StackName)
+	} else if pv, err := p.getProvisionerForStack(ctx, stack); err != nil {
+		return nil, errors.Wrapf(err, "failed to get provisioner for stack %q", stack.Name)
+	} else {
+		return pv.UnlockStack(ctx, cfg, stack, params)
+	}
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/clouds/compose"
+	"github.com/simple-container-com/api/pkg/clouds/local"
+)
+
+// Up runs cloud-compose stack locally with stand-ins of the resources it uses
+func (p *provisioner) Up(ctx context.Context, params api.UpParams) error {
+	p.logWelcome(ctx, nil)
+
+	project, err := p.localProject(ctx, &params.StackParams)
+	if err != nil {
+		return err
+	}
+	runner, err := local.NewRunner(p.log)
+	if err != nil {
+		return err
+	}
+	return runner.Up(ctx, project, params)
+}
+
+// Down removes stack running locally
+func (p *provisioner) Down(ctx context.Context, params api.StackParams) error {
+	if params.Environment == "" {
+		return errors.Errorf("environment must be specified")
+	}
+	runner, err := local.NewRunner(p.log)
+	if err != nil {
+		return err
+	}
+	return runner.Down(ctx, local.ProjectName(params.StackName, params.Environment))
+}
+
+func (p *provisioner) localProject(ctx context.Context, params *api.StackParams) (*local.Project, error) {
+	_, stack, _, err := p.prepareForChildStack(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	clientDesc := stack.Client.Stacks[params.Environment]
+	if clientDesc.Type != api.ClientTypeCloudCompose {
+		return nil, errors.Errorf("stack %q in %q is of type %q, only %q stacks can run locally",
+			params.StackName, params.Environment, clientDesc.Type, api.ClientTypeCloudCompose)
+	}
+	clientCfg, ok := clientDesc.Config.Config.(*api.StackConfigCompose)
+	if !ok {
+		return nil, errors.Errorf("client config is not of type *StackConfigCompose")
+	}
+
+	stackDir := params.StackDir
+	if stackDir == "" {
+		stackDir = filepath.Join(params.StacksDir, params.StackName)
+	}
+	composeCfg, err := compose.ReadDockerCompose(ctx, stackDir, clientCfg.DockerComposeFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read docker-compose config from %q/%q", stackDir, clientCfg.DockerComposeFile)
+	}
+
+	// resources are declared for the parent environment, which is usually the same as the environment of the stack
+	resEnv := lo.Ternary(clientDesc.ParentEnv != "", clientDesc.ParentEnv, params.Environment)
+	return local.NewProject(local.ProjectParams{
+		StackName:   params.StackName,
+		Environment: params.Environment,
+		Config:      clientCfg,
+		Compose:     composeCfg,
+		Resources:   stack.Server.Resources.Resources[resEnv].Resources,
+	})
+}
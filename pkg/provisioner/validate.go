@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package provisioner
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/api/pkg/api"
+	"github.com/simple-container-com/api/pkg/provisioner/placeholders"
+	"github.com/simple-container-com/api/pkg/template"
+)
+
+// deferredExtensions are resolved by cloud provisioners during deploy and cannot be validated in advance
+var deferredExtensions = []string{"resource", "dependency"}
+
+// descriptorFiles maps top-level fields of a stack to the files they are read from
+var descriptorFiles = map[string]string{
+	"server":  api.ServerDescriptorFileName,
+	"client":  api.ClientDescriptorFileName,
+	"secrets": api.SecretsDescriptorFileName,
+}
+
+// Validate reads stacks and reports placeholders which cannot be resolved the same way as during deploy:
+// configurations of client stacks are checked per environment after reconciliation with their parent stacks
+func (p *provisioner) Validate(ctx context.Context, params api.ValidateParams) (*api.ValidationReport, error) {
+	cfg, err := api.ReadConfigFile(p.rootDir, p.profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file for profile %q", p.profile)
+	}
+	if err := p.ReadStacks(ctx, cfg, api.ProvisionParams{StacksDir: params.StacksDir}, api.ReadOpts{
+		IgnoreServerMissing:  true,
+		IgnoreClientMissing:  true,
+		IgnoreSecretsMissing: true,
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to read stacks")
+	}
+	stacksDir := p.getStacksDir(cfg, params.StacksDir)
+	if _, ok := p.stacks[params.StackName]; params.StackName != "" && !ok {
+		return nil, errors.Errorf("stack %q is not found in %q", params.StackName, stacksDir)
+	}
+	if rel, err := filepath.Rel(p.rootDir, stacksDir); err == nil && !strings.HasPrefix(rel, "..") {
+		stacksDir = rel
+	}
+
+	report := &api.ValidationReport{}
+	stackNames := lo.Filter(lo.Keys(p.stacks), func(name string, _ int) bool {
+		return params.StackName == "" || name == params.StackName
+	})
+	sort.Strings(stackNames)
+	report.Stacks = stackNames
+
+	// server and secrets descriptors are resolved within their own stacks
+	if err := p.validatePlaceholders(p.stacks, stacksDir, report, func(stackName, location string) bool {
+		return lo.Contains(stackNames, stackName) && !strings.HasPrefix(location, "client.")
+	}); err != nil {
+		return nil, err
+	}
+
+	// client descriptors are resolved after parent's server and secrets descriptors are copied to the stack
+	for _, stackName := range stackNames {
+		envs := lo.Keys(p.stacks[stackName].Client.Stacks)
+		sort.Strings(envs)
+		for _, env := range envs {
+			if params.Environment != "" && env != params.Environment {
+				continue
+			}
+			stacks := api.StacksMap(lo.Assign(p.stacks))
+			reconciled, err := stacks.ReconcileForDeploy(api.StackParams{StackName: stackName, Environment: env})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to reconcile stack %q in %q", stackName, env)
+			}
+			envPrefix := "client.stacks." + env + "."
+			if err := p.validatePlaceholders(*reconciled, stacksDir, report, func(refStack, location string) bool {
+				return refStack == stackName && strings.HasPrefix(location, envPrefix)
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	report.Sort()
+	return report, nil
+}
+
+// validatePlaceholders resolves stacks in dry-run mode and adds references accepted by filter to the report
+func (p *provisioner) validatePlaceholders(stacks api.StacksMap, stacksDir string, report *api.ValidationReport, filter func(stackName, location string) bool) error {
+	stacks = lo.Assign(stacks)
+	stackNames := lo.Keys(stacks)
+	opts := append([]placeholders.Option{}, p.phOpts...)
+	opts = append(opts, placeholders.WithDryRun(func(ref template.Reference) {
+		if lo.Contains(deferredExtensions, ref.Extension) {
+			return
+		}
+		stackName, location := splitStackLocation(stackNames, ref.Location)
+		if !filter(stackName, location) {
+			return
+		}
+		section, path, _ := strings.Cut(location, ".")
+		report.Add(api.UnresolvedReference{
+			Stack:       stackName,
+			File:        filepath.Join(stacksDir, stackName, descriptorFiles[section]),
+			Path:        path,
+			Placeholder: ref.Placeholder,
+			Extension:   ref.Extension,
+			Reason:      ref.Reason,
+			Ambiguous:   ref.Ambiguous,
+			Deferred:    ref.Deferred,
+		})
+	}))
+	return p.phResolver.Resolve(stacks, opts...)
+}
+
+// splitStackLocation splits location reported by Resolve into the name of the stack and the location within it
+func splitStackLocation(stackNames []string, location string) (string, string) {
+	stackName := ""
+	for _, name := range stackNames {
+		if strings.HasPrefix(location, name+".") && len(name) > len(stackName) {
+			stackName = name
+		}
+	}
+	return stackName, strings.TrimPrefix(location, stackName+".")
+}
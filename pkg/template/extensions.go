@@ -4,9 +4,13 @@
 package template
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
+	"os/exec"
 	"os/user"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -58,7 +62,7 @@ func (tpl *Template) extGit(noSubstitution, path string, defaultValue *string) (
 func (tpl *Template) extEnv(noSubstitution, path string, defaultValue *string) (string, error) {
 	res := os.Getenv(path)
 	if res == "" && defaultValue != nil {
-		return *defaultValue, nil
+		return DefaultValue(*defaultValue)
 	}
 	return res, nil
 }
@@ -94,9 +98,105 @@ func (tpl *Template) extDate(noSubstitution, path string, defaultValue *string)
 		res = fmt.Sprintf("%d-%02d-%02d", t.Year(), t.Month(), t.Day())
 	default:
 		if defaultValue != nil {
-			return *defaultValue, nil
+			return DefaultValue(*defaultValue)
 		}
 		return res, errors.Errorf("%s", "unknown date format: "+path)
 	}
 	return res, nil
 }
+
+const cmdTimeout = 30 * time.Second
+
+// extFile inlines contents of a file within root dir, e.g. ${file:certs/ca.pem}
+func (tpl *Template) extFile(noSubstitution, path string, defaultValue *string) (string, error) {
+	filePath, err := tpl.sandboxedPath(path)
+	if err != nil {
+		return noSubstitution, err
+	}
+	content, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) && defaultValue != nil {
+		return DefaultValue(*defaultValue)
+	} else if err != nil {
+		return noSubstitution, errors.Wrapf(err, "failed to read file %q", path)
+	}
+	return string(content), nil
+}
+
+// extBase64 encodes value, e.g. ${base64:${file:certs/ca.pem}}
+func (tpl *Template) extBase64(noSubstitution, path string, defaultValue *string) (string, error) {
+	value := path
+	if defaultValue != nil {
+		// value itself may contain colons
+		value += ":" + tpl.Exec(*defaultValue)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(value)), nil
+}
+
+// extCmd inlines trimmed output of a shell command, e.g. ${cmd:git describe --tags}
+// Commands must be explicitly allowed
+func (tpl *Template) extCmd(noSubstitution, path string, defaultValue *string) (string, error) {
+	if !tpl.allowCommands {
+		return noSubstitution, errors.Errorf("command placeholders are not allowed (see allowCmdPlaceholders in profile config)")
+	}
+	command := path
+	if defaultValue != nil {
+		// command itself may contain colons
+		command += ":" + tpl.Exec(*defaultValue)
+	}
+	if tpl.HasReporter() {
+		return noSubstitution, &DeferredError{Reason: fmt.Sprintf("command %q is not run in dry run", command)}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = tpl.baseDir()
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return noSubstitution, errors.Wrapf(err, "failed to run command %q: %s", command, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// baseDir returns directory relative paths are resolved against
+func (tpl *Template) baseDir() string {
+	if tpl.workDir == "" || tpl.rootDir == "" {
+		return tpl.rootDir
+	}
+	if filepath.IsAbs(tpl.workDir) {
+		return tpl.workDir
+	}
+	return filepath.Join(tpl.rootDir, tpl.workDir)
+}
+
+// sandboxedPath resolves path against base dir and makes sure it does not escape root dir
+func (tpl *Template) sandboxedPath(path string) (string, error) {
+	if tpl.rootDir == "" {
+		return "", errors.Errorf("root dir is not configured for file placeholders")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(tpl.baseDir(), path)
+	}
+	root, err := filepath.Abs(tpl.rootDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve root dir %q", tpl.rootDir)
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve path %q", path)
+	}
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		target = resolved
+	} else if !os.IsNotExist(err) {
+		return "", errors.Wrapf(err, "failed to resolve path %q", path)
+	}
+	if rel, err := filepath.Rel(root, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("path %q is outside of %q", path, tpl.rootDir)
+	}
+	return target, nil
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) Simple Container
+
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/simple-container-com/api/pkg/util"
+)
+
+func TestNestedPlaceholders(t *testing.T) {
+	RegisterTestingT(t)
+	t.Setenv("SC_TPL_TEST_SET", "from-env")
+	t.Setenv("SC_TPL_TEST_UNSET", "")
+
+	tpl := NewTemplate().WithData(util.Data{"fallback": "from-data"})
+
+	Expect(tpl.Exec("${env:SC_TPL_TEST_UNSET:${fallback}}")).To(Equal("from-data"))
+	Expect(tpl.Exec("${env:SC_TPL_TEST_SET:${fallback}}")).To(Equal("from-env"))
+	Expect(tpl.Exec("${env:SC_TPL_TEST_UNSET:${env:SC_TPL_TEST_UNSET:${fallback}}}")).To(Equal("from-data"))
+	Expect(tpl.Exec("${env:SC_TPL_TEST_UNSET:http://host:8080}")).To(Equal("http://host:8080"))
+	Expect(tpl.Exec("${env:${env:SC_TPL_TEST_UNSET:SC_TPL_TEST_SET}}")).To(Equal("from-env"))
+
+	// unresolved and unclosed placeholders are kept as is
+	Expect(tpl.Exec("${A:-${B}}")).To(Equal("${A:-${B}}"))
+	Expect(tpl.Exec("prefix ${unclosed ${fallback}")).To(Equal("prefix ${unclosed from-data"))
+}
+
+func TestExtFile(t *testing.T) {
+	RegisterTestingT(t)
+	root := t.TempDir()
+	Expect(os.MkdirAll(filepath.Join(root, "billing", "certs"), 0o755)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(root, "billing", "certs", "ca.pem"), []byte("-----CA-----\n"), 0o644)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(root, "common.txt"), []byte("common"), 0o644)).To(Succeed())
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	Expect(os.WriteFile(outside, []byte("secret"), 0o644)).To(Succeed())
+	Expect(os.Symlink(outside, filepath.Join(root, "billing", "link.txt"))).To(Succeed())
+
+	tpl := NewTemplate().WithRootDir(root).WithWorkDir("billing").WithStrict(true)
+
+	Expect(tpl.Exec("${file:certs/ca.pem}")).To(Equal("-----CA-----\n"))
+	Expect(tpl.Exec("${file:../common.txt}")).To(Equal("common"))
+	Expect(tpl.Exec("${file:" + filepath.Join(root, "common.txt") + "}")).To(Equal("common"))
+	Expect(tpl.Exec("${file:missing.txt:none}")).To(Equal("none"))
+	Expect(tpl.Exec("${base64:${file:certs/ca.pem}}")).To(Equal("LS0tLS1DQS0tLS0tCg=="))
+
+	Expect(tpl.Exec("${file:../../secret.txt}")).To(ContainSubstring("is outside of"))
+	Expect(tpl.Exec("${file:" + outside + "}")).To(ContainSubstring("is outside of"))
+	Expect(tpl.Exec("${file:link.txt}")).To(ContainSubstring("is outside of"))
+
+	Expect(NewTemplate().Exec("${file:certs/ca.pem}")).To(Equal("${file:certs/ca.pem}"))
+}
+
+func TestExtBase64(t *testing.T) {
+	RegisterTestingT(t)
+	tpl := NewTemplate()
+
+	Expect(tpl.Exec("${base64:hello}")).To(Equal("aGVsbG8="))
+	Expect(tpl.Exec("${base64:user:pass}")).To(Equal("dXNlcjpwYXNz"))
+	Expect(tpl.Exec("${base64:a:b:c}")).To(Equal("YTpiOmM="))
+}
+
+func TestExtCmd(t *testing.T) {
+	RegisterTestingT(t)
+	root := t.TempDir()
+	Expect(os.MkdirAll(filepath.Join(root, "billing"), 0o755)).To(Succeed())
+
+	t.Run("disabled by default", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(NewTemplate().Exec("${cmd:echo hi}")).To(Equal("${cmd:echo hi}"))
+		Expect(NewTemplate().WithStrict(true).Exec("${cmd:echo hi}")).To(ContainSubstring("command placeholders are not allowed"))
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		RegisterTestingT(t)
+		tpl := NewTemplate().WithRootDir(root).WithWorkDir("billing").WithCommands(true).WithStrict(true)
+		Expect(tpl.Exec("${cmd:echo hi}")).To(Equal("hi"))
+		Expect(tpl.Exec("${cmd:echo a:b}")).To(Equal("a:b"))
+		Expect(tpl.Exec("${cmd:basename $(pwd)}")).To(Equal("billing"))
+		Expect(tpl.Exec("${cmd:echo oops >&2; exit 1}")).To(ContainSubstring("oops"))
+	})
+
+	t.Run("default is only run when used", func(t *testing.T) {
+		RegisterTestingT(t)
+		t.Setenv("SC_TPL_TEST_SET", "from-env")
+		tpl := NewTemplate().WithRootDir(root).WithWorkDir("billing").WithCommands(true).WithStrict(true)
+		marker := filepath.Join(root, "billing", "ran")
+
+		Expect(tpl.Exec("${env:SC_TPL_TEST_SET:${cmd:touch ran && echo from-cmd}}")).To(Equal("from-env"))
+		Expect(marker).ToNot(BeAnExistingFile())
+		// value equal to the default is not mistaken for falling back to it
+		t.Setenv("SC_TPL_TEST_LITERAL", "${cmd:touch ran && echo from-cmd}")
+		Expect(tpl.Exec("${env:SC_TPL_TEST_LITERAL:${cmd:touch ran && echo from-cmd}}")).To(Equal("${cmd:touch ran && echo from-cmd}"))
+		Expect(marker).ToNot(BeAnExistingFile())
+		Expect(tpl.Exec("${file:missing.txt:${cmd:touch ran && echo from-cmd}}")).To(Equal("from-cmd"))
+		Expect(marker).To(BeAnExistingFile())
+	})
+
+	t.Run("not run in dry run", func(t *testing.T) {
+		RegisterTestingT(t)
+		var reported []Reference
+		tpl := NewTemplate().WithRootDir(root).WithWorkDir("billing").WithCommands(true).WithReporter(func(ref Reference) {
+			reported = append(reported, ref)
+		})
+
+		Expect(tpl.Exec("${cmd:touch dry-run}")).To(Equal("${cmd:touch dry-run}"))
+		Expect(filepath.Join(root, "billing", "dry-run")).ToNot(BeAnExistingFile())
+		Expect(reported).To(Equal([]Reference{{
+			Placeholder: "${cmd:touch dry-run}", Extension: "cmd", Reason: `command "touch dry-run" is not run in dry run`, Deferred: true,
+		}}))
+	})
+}
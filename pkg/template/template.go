@@ -5,23 +5,59 @@ package template
 
 import (
 	"fmt"
-	"io"
 	"strings"
 
-	"github.com/valyala/fasttemplate"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
 
 	"github.com/simple-container-com/api/pkg/api/git"
 	"github.com/simple-container-com/api/pkg/util"
 )
 
 // Extension allows to extend template engine
+// defaultValue is passed as is, its nested placeholders are only resolved if the extension falls back to it
+// with DefaultValue, so that unused defaults have no side effects (e.g. ${env:A:${cmd:...}} does not run
+// the command when A is set)
 type Extension func(source string, path string, defaultValue *string) (string, error)
 
+// ErrDefaultValue is returned by extensions along with the default value of the placeholder they fall back to
+var ErrDefaultValue = errors.New("extension falls back to default value")
+
+// DefaultValue returns the default value of the placeholder for an extension to fall back to, see ErrDefaultValue
+func DefaultValue(defaultValue string) (string, error) {
+	return defaultValue, ErrDefaultValue
+}
+
+// Reference describes placeholder which could not be resolved
+type Reference struct {
+	Placeholder string // e.g. ${secret:FOO}
+	Extension   string // empty if placeholder does not specify extension
+	Location    string // location of the template string, see WithLocation
+	Reason      string
+	Ambiguous   bool // placeholder neither refers to a known extension nor to data
+	Deferred    bool // placeholder is not evaluated in dry run (see DeferredError), it is only resolved on deploy
+}
+
+// DeferredError is returned by extensions which must not be evaluated in dry run because of their side effects
+// (e.g. running commands or reading external secret sources)
+type DeferredError struct {
+	Reason string
+}
+
+func (e *DeferredError) Error() string {
+	return e.Reason
+}
+
 // Template defines structure for template engine
 type Template struct {
 	git               git.Repo
 	data              util.Data
 	strict            bool
+	rootDir           string
+	workDir           string
+	allowCommands     bool
+	location          string
+	reporter          func(ref Reference)
 	extensions        map[string]Extension
 	defaultExtensions map[string]Extension
 }
@@ -30,10 +66,13 @@ type Template struct {
 func NewTemplate() *Template {
 	res := &Template{}
 	res.defaultExtensions = map[string]Extension{
-		"git":  res.extGit,
-		"env":  res.extEnv,
-		"date": res.extDate,
-		"user": res.extUser,
+		"git":    res.extGit,
+		"env":    res.extEnv,
+		"date":   res.extDate,
+		"user":   res.extUser,
+		"file":   res.extFile,
+		"base64": res.extBase64,
+		"cmd":    res.extCmd,
 	}
 	return res
 }
@@ -62,16 +101,120 @@ func (tpl *Template) WithData(data util.Data) *Template {
 	return tpl
 }
 
+// WithRootDir sets directory ${file:...} placeholders are restricted to
+func (tpl *Template) WithRootDir(rootDir string) *Template {
+	tpl.rootDir = rootDir
+	return tpl
+}
+
+// WithWorkDir sets directory relative paths of ${file:...} and ${cmd:...} are resolved against
+// (relative work dir is resolved against root dir)
+func (tpl *Template) WithWorkDir(workDir string) *Template {
+	tpl.workDir = workDir
+	return tpl
+}
+
+// WithCommands enables ${cmd:...} placeholders
+func (tpl *Template) WithCommands(allowed bool) *Template {
+	tpl.allowCommands = allowed
+	return tpl
+}
+
+// WithLocation sets location of the template string reported with unresolved references
+func (tpl *Template) WithLocation(location string) *Template {
+	tpl.location = location
+	return tpl
+}
+
+// Location returns location of the template string
+func (tpl *Template) Location() string {
+	return tpl.location
+}
+
+// WithReporter sets function called for every placeholder which could not be resolved
+func (tpl *Template) WithReporter(reporter func(ref Reference)) *Template {
+	tpl.reporter = reporter
+	return tpl
+}
+
+// HasReporter returns true if unresolved placeholders are reported
+func (tpl *Template) HasReporter() bool {
+	return tpl.reporter != nil
+}
+
+func (tpl *Template) report(ref Reference) {
+	if tpl.reporter == nil {
+		return
+	}
+	ref.Location = tpl.location
+	tpl.reporter(ref)
+}
+
 // Exec applies template engine to a string with placeholders
+// Placeholders can be nested, e.g. ${env:A:${var:B}}
 func (tpl *Template) Exec(tplString string) string {
-	return fasttemplate.ExecuteFuncString(tplString, "${", "}", func(w io.Writer, tag string) (int, error) {
-		return w.Write([]byte(tpl.calcValue(tag)))
-	})
+	var res strings.Builder
+	for {
+		start := strings.Index(tplString, "${")
+		if start < 0 {
+			break
+		}
+		res.WriteString(tplString[:start])
+		tplString = tplString[start+2:]
+		end := closingBrace(tplString)
+		if end < 0 {
+			// cannot find end tag - keep start tag as is and continue with the rest
+			res.WriteString("${")
+			continue
+		}
+		res.WriteString(tpl.calcValue(tplString[:end]))
+		tplString = tplString[end+1:]
+	}
+	res.WriteString(tplString)
+	return res.String()
+}
+
+// closingBrace returns index of "}" closing placeholder, taking nested placeholders into account
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// splitTag splits tag into at most 3 parts by colons which are not within nested placeholders,
+// colons of URL schemes (e.g. ${secret:vault://kv/data/app#key}) are kept within the path
+func splitTag(tag string) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i := 0; i < len(tag) && len(parts) < 2; i++ {
+		switch {
+		case strings.HasPrefix(tag[i:], "${"):
+			depth++
+			i++
+		case tag[i] == '}' && depth > 0:
+			depth--
+		case tag[i] == ':' && depth == 0 && (len(parts) == 0 || !strings.HasPrefix(tag[i:], "://")):
+			parts = append(parts, tag[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, tag[last:])
 }
 
 func (tpl *Template) calcValue(tag string) string {
 	noSubstitution := fmt.Sprintf("${%s}", tag)
-	parts := strings.SplitN(tag, ":", 3)
+	parts := splitTag(tag)
 	context := parts[0]
 
 	// if there is no context specified
@@ -79,36 +222,26 @@ func (tpl *Template) calcValue(tag string) string {
 		res, err := util.GetValue(tag, tpl.data)
 		if err != nil {
 			// ignore errors here to ignore placeholders like ${USER}
+			tpl.report(Reference{Placeholder: noSubstitution, Ambiguous: true, Reason: "no extension specified and value is not found"})
 			return noSubstitution
 		}
 		return res.(string)
 	}
 
-	// if there was context specified
-	path := parts[1]
+	// if there was context specified, resolve nested placeholders of the path first,
+	// default is only resolved when it is used
+	path := tpl.Exec(parts[1])
 	var defaultValue *string
 	if len(parts) > 2 {
-		defaultValue = &parts[2]
+		defaultValue = lo.ToPtr(parts[2])
 	}
 	// check extra extensions first (if registered)
 	if extension, extraExtensionExists := tpl.extensions[context]; extraExtensionExists {
-		if res, err := extension(noSubstitution, path, defaultValue); err == nil {
-			return res
-		} else if tpl.strict {
-			return noSubstitution + "; error: " + err.Error()
-		} else {
-			return noSubstitution
-		}
+		return tpl.callExtension(extension, context, noSubstitution, path, defaultValue)
 	}
 	// check default extensions
 	if extension, defaultExtensionExists := tpl.defaultExtensions[context]; defaultExtensionExists {
-		if res, err := extension(noSubstitution, path, defaultValue); err == nil {
-			return res
-		} else if tpl.strict {
-			return noSubstitution + "; error: " + err.Error()
-		} else {
-			return noSubstitution
-		}
+		return tpl.callExtension(extension, context, noSubstitution, path, defaultValue)
 	}
 
 	// try to traverse path in different ways
@@ -123,10 +256,34 @@ func (tpl *Template) calcValue(tag string) string {
 		}
 		// if nothing is found, return default if present
 		if defaultValue != nil {
-			return *defaultValue
+			return tpl.Exec(*defaultValue)
 		}
 		// if no default - return without substitution
+		tpl.report(Reference{Placeholder: noSubstitution, Extension: context, Ambiguous: true, Reason: fmt.Sprintf("unknown extension %q", context)})
 		return noSubstitution
 	}
 	return res.(string)
 }
+
+func (tpl *Template) callExtension(extension Extension, context, noSubstitution, path string, defaultValue *string) string {
+	res, err := extension(noSubstitution, path, defaultValue)
+	if errors.Is(err, ErrDefaultValue) {
+		return tpl.Exec(res)
+	}
+	var deferred *DeferredError
+	if errors.As(err, &deferred) {
+		tpl.report(Reference{Placeholder: noSubstitution, Extension: context, Deferred: true, Reason: deferred.Reason})
+		return noSubstitution
+	}
+	if err != nil {
+		tpl.report(Reference{Placeholder: noSubstitution, Extension: context, Reason: err.Error()})
+		if tpl.strict {
+			return noSubstitution + "; error: " + err.Error()
+		}
+		return noSubstitution
+	}
+	if res == noSubstitution {
+		tpl.report(Reference{Placeholder: noSubstitution, Extension: context, Reason: "value is not found"})
+	}
+	return res
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 
 	"github.com/simple-container-com/api/pkg/util"
 )
@@ -169,3 +170,16 @@ func TestCustomExtension(t *testing.T) {
 	})
 	Expect(tpl.Exec("${custom:abc}")).To(Equal("X-abc"))
 }
+
+func TestCustomExtension_URLPath(t *testing.T) {
+	RegisterTestingT(t)
+
+	tpl := NewTemplate().WithExtensions(map[string]Extension{
+		"custom": func(source, path string, def *string) (string, error) {
+			return path + "|" + lo.FromPtr(def), nil
+		},
+	})
+	Expect(tpl.Exec("${custom:vault://kv/data/app#key}")).To(Equal("vault://kv/data/app#key|"))
+	Expect(tpl.Exec("${custom:vault://kv/data/app#key:fallback}")).To(Equal("vault://kv/data/app#key|fallback"))
+	Expect(tpl.Exec("${custom:name:http://host}")).To(Equal("name|http://host"))
+}
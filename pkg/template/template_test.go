@@ -122,3 +122,29 @@ func TestTemplating(t *testing.T) {
 	Expect(result).To(ContainSubstring("With deep field value: value."))
 	Expect(result).To(ContainSubstring("Another deep field with default: default."))
 }
+
+func TestReporter(t *testing.T) {
+	RegisterTestingT(t)
+	t.Setenv("SC_TPL_TEST_SET", "value")
+
+	var refs []Reference
+	tpl := NewTemplate().
+		WithData(util.Data{"known": "value"}).
+		WithExtensions(map[string]Extension{
+			"secret": func(noSubs, path string, defaultVal *string) (string, error) {
+				return noSubs, errors.Errorf("secret %q not found", path)
+			},
+		}).
+		WithLocation("stacks.prod.config.env.DB_URL").
+		WithReporter(func(ref Reference) {
+			refs = append(refs, ref)
+		})
+
+	result := tpl.Exec("${known} ${env:SC_TPL_TEST_SET} ${secret:FOO} ${unknown:path} ${UNKNOWN} ${unknown:path:default}")
+	Expect(result).To(Equal("value value ${secret:FOO} ${unknown:path} ${UNKNOWN} default"))
+	Expect(refs).To(Equal([]Reference{
+		{Placeholder: "${secret:FOO}", Extension: "secret", Location: "stacks.prod.config.env.DB_URL", Reason: `secret "FOO" not found`},
+		{Placeholder: "${unknown:path}", Extension: "unknown", Location: "stacks.prod.config.env.DB_URL", Reason: `unknown extension "unknown"`, Ambiguous: true},
+		{Placeholder: "${UNKNOWN}", Location: "stacks.prod.config.env.DB_URL", Reason: "no extension specified and value is not found", Ambiguous: true},
+	}))
+}
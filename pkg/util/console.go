@@ -33,6 +33,9 @@ type StdinConsoleReader struct{}
 
 type StdoutConsoleWriter struct{}
 
+// StderrConsoleWriter is used when stdout is reserved for machine-readable output
+type StderrConsoleWriter struct{}
+
 type ConsoleImpl struct {
 	reader        ConsoleReader
 	writer        ConsoleWriter
@@ -118,6 +121,14 @@ func (w StdoutConsoleWriter) Println(args ...interface{}) {
 	fmt.Println(args...)
 }
 
+func (w StderrConsoleWriter) Print(args ...interface{}) {
+	fmt.Fprint(os.Stderr, args...)
+}
+
+func (w StderrConsoleWriter) Println(args ...interface{}) {
+	fmt.Fprintln(os.Stderr, args...)
+}
+
 func (reader StdinConsoleReader) ReadPassword() (string, error) {
 	bytePass, err := gopass.GetPasswd()
 	return string(bytePass), err